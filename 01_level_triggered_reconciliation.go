@@ -24,6 +24,7 @@ package guide
 
 import (
 	"context"
+	"errors"
 	"fmt"
 )
 
@@ -166,29 +167,54 @@ func (r *LevelTriggeredReconciler) Reconcile(ctx context.Context, name string, n
 // --- Helper types for illustration ---
 
 type MyResource struct {
-	Name             string
-	Namespace        string
+	ObjectMeta
 	TargetSecretName string
 	RefreshInterval  int
 }
 
-type FakeClient struct{}
+// FakeClient reads and writes through the in-memory API server (Pattern 24),
+// so Reconcile() sees real NotFound errors and resourceVersion conflicts.
+type FakeClient struct {
+	server *FakeAPIServer
+}
+
+func NewFakeClient(server *FakeAPIServer) FakeClient {
+	return FakeClient{server: server}
+}
 
 func (c FakeClient) GetExternalSecret(ctx context.Context, name, namespace string) (*MyResource, error) {
-	return nil, nil
+	es := &MyResource{}
+	if err := c.server.Get(ctx, namespace, name, es); err != nil {
+		return nil, err
+	}
+	return es, nil
 }
 func (c FakeClient) GetSecret(ctx context.Context, name, namespace string) (map[string][]byte, error) {
-	return nil, nil
+	secret := &Secret{}
+	if err := c.server.Get(ctx, namespace, name, secret); err != nil {
+		return nil, err
+	}
+	return secret.Data, nil
 }
 func (c FakeClient) CreateSecret(ctx context.Context, name, namespace string, data map[string][]byte) error {
-	return nil
+	return c.server.Create(ctx, &Secret{
+		ObjectMeta: ObjectMeta{Name: name, Namespace: namespace},
+		Data:       data,
+	})
 }
 func (c FakeClient) UpdateSecret(ctx context.Context, name, namespace string, data map[string][]byte) error {
-	return nil
+	// Read-modify-write: the resourceVersion from Get makes Update fail with
+	// ErrConflict if someone else wrote in between.
+	secret := &Secret{}
+	if err := c.server.Get(ctx, namespace, name, secret); err != nil {
+		return err
+	}
+	secret.Data = data
+	return c.server.Update(ctx, secret)
 }
 
-func isNotFound(err error) bool            { return false }
-func needsRefresh(r *MyResource) bool      { return true }
+func isNotFound(err error) bool       { return errors.Is(err, ErrNotFound) }
+func needsRefresh(r *MyResource) bool { return true }
 func isSecretValid(actual map[string][]byte, desired *MyResource) bool {
 	return false
 }
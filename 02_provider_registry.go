@@ -19,6 +19,7 @@
 package guide
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"sync"
@@ -27,21 +28,16 @@ import (
 // =============================================================================
 // STEP 1: Define the Provider interface
 // =============================================================================
-// All providers must implement this interface.
+// All providers must implement ONE interface: SecretsProvider from Pattern 05.
+// It is context-aware, takes a typed StoreConfig, and hands out clients that
+// hold connections or tokens and so must be closed.
+//
 // The reconciler only talks to this interface, never to concrete types.
+// Providers written against the older context-free shape (map config, no
+// Close) are registered through AdaptLegacyProvider (Pattern 38).
 //
 // Real code: the Provider interface in apis/externalsecrets/v1/
 
-type SecretProvider interface {
-	// NewClient creates an authenticated client for this provider
-	NewClient(config map[string]string) (SecretClient, error)
-}
-
-type SecretClient interface {
-	GetSecret(key string) ([]byte, error)
-	GetSecretMap(key string) (map[string][]byte, error)
-}
-
 // =============================================================================
 // STEP 2: The Global Registry
 // =============================================================================
@@ -56,7 +52,7 @@ type SecretClient interface {
 // Real code: apis/externalsecrets/v1/provider_schema.go:26-50
 
 var (
-	providerRegistry     = make(map[string]SecretProvider)
+	providerRegistry     = make(map[string]SecretsProvider)
 	providerRegistryLock sync.RWMutex
 )
 
@@ -67,7 +63,7 @@ var (
 // silently overwriting a provider at runtime, which would be extremely hard to debug.
 //
 // Real code: apis/externalsecrets/v1/provider_schema.go:35-50
-func RegisterProvider(name string, provider SecretProvider) {
+func RegisterProvider(name string, provider SecretsProvider) {
 	providerRegistryLock.Lock()
 	defer providerRegistryLock.Unlock()
 
@@ -80,7 +76,7 @@ func RegisterProvider(name string, provider SecretProvider) {
 // GetProviderByName looks up a provider from the registry.
 //
 // Real code: apis/externalsecrets/v1/provider_schema.go:67-72
-func GetProviderByName(name string) (SecretProvider, bool) {
+func GetProviderByName(name string) (SecretsProvider, bool) {
 	providerRegistryLock.RLock()
 	defer providerRegistryLock.RUnlock()
 
@@ -88,7 +84,7 @@ func GetProviderByName(name string) (SecretProvider, bool) {
 	return p, ok
 }
 
-// GetProviderFromSpec determines which provider to use based on the store spec.
+// providerFromSpec determines which provider to use based on the store spec.
 // It marshals the spec to JSON and finds the one non-nil field.
 //
 // This is an elegant approach to polymorphic dispatch: the SecretStore spec is a
@@ -99,68 +95,47 @@ func GetProviderByName(name string) (SecretProvider, bool) {
 // For example: {"aws": {"region": "us-east-1"}} → provider name is "aws"
 //
 // Real code: apis/externalsecrets/v1/provider_schema.go:75-100
-func GetProviderFromSpec(storeSpec map[string]interface{}) (SecretProvider, error) {
+func providerFromSpec(storeSpec map[string]interface{}) (string, SecretsProvider, error) {
 	// Marshal and inspect to find which provider is configured
 	specBytes, _ := json.Marshal(storeSpec)
 	specMap := make(map[string]interface{})
 	json.Unmarshal(specBytes, &specMap)
 
 	if len(specMap) != 1 {
-		return nil, fmt.Errorf("exactly one provider must be specified, found %d", len(specMap))
+		return "", nil, fmt.Errorf("exactly one provider must be specified, found %d", len(specMap))
 	}
 
 	for name := range specMap {
 		p, ok := GetProviderByName(name)
 		if !ok {
-			return nil, fmt.Errorf("unknown provider: %s", name)
+			return "", nil, fmt.Errorf("unknown provider: %s", name)
 		}
-		return p, nil
+		return name, p, nil
 	}
-	return nil, fmt.Errorf("no provider found")
+	return "", nil, fmt.Errorf("no provider found")
+}
+
+// GetProviderFromSpec resolves the provider, validates the store config
+// (Pattern 37) and returns an authenticated client. The client holds
+// connections or tokens: the caller MUST Close it, normally with defer.
+//
+// Real code: pkg/controllers/secretstore/client_manager.go (Manager.Get, Manager.Close)
+func GetProviderFromSpec(ctx context.Context, storeSpec map[string]interface{}) (SecretsClient, error) {
+	provider, store, err := validateStoreSpec(SecretStoreSpec{Provider: storeSpec})
+	if err != nil {
+		return nil, err
+	}
+	return provider.NewClient(ctx, store)
 }
 
 // =============================================================================
 // STEP 3: Concrete Provider Implementations
 // =============================================================================
-// Each provider is in its own package and file. It knows nothing about other providers.
-
-// --- AWS Provider ---
-// Real file: providers/v1/aws/
-type AWSProvider struct{}
-
-func (p *AWSProvider) NewClient(config map[string]string) (SecretClient, error) {
-	// Create AWS Secrets Manager client using config["region"], config["role"], etc.
-	return &AWSClient{region: config["region"]}, nil
-}
-
-type AWSClient struct{ region string }
-
-func (c *AWSClient) GetSecret(key string) ([]byte, error) {
-	// Call AWS Secrets Manager API: secretsmanager.GetSecretValue(key)
-	return []byte("aws-secret-value"), nil
-}
-func (c *AWSClient) GetSecretMap(key string) (map[string][]byte, error) {
-	return map[string][]byte{"key": []byte("value")}, nil
-}
-
-// --- Vault Provider ---
-// Real file: providers/v1/vault/
-type VaultProvider struct{}
-
-func (p *VaultProvider) NewClient(config map[string]string) (SecretClient, error) {
-	// Create HashiCorp Vault client using config["server"], config["path"], etc.
-	return &VaultClient{server: config["server"]}, nil
-}
-
-type VaultClient struct{ server string }
-
-func (c *VaultClient) GetSecret(key string) ([]byte, error) {
-	// Call Vault API: vault.Logical().Read(key)
-	return []byte("vault-secret-value"), nil
-}
-func (c *VaultClient) GetSecretMap(key string) (map[string][]byte, error) {
-	return map[string][]byte{"key": []byte("value")}, nil
-}
+// Each provider is in its own package and file. It knows nothing about other
+// providers. This guide's AWS, Vault and GCP implementations live with the
+// interface they implement, in Pattern 05; this file only registers them.
+//
+// Real files: providers/v1/aws/, providers/v1/vault/, providers/v1/gcp/
 
 // =============================================================================
 // STEP 4: Registration via init()
@@ -190,10 +165,10 @@ func (c *VaultClient) GetSecretMap(key string) (map[string][]byte, error) {
 func init() {
 	// In the real project, each provider registers in its own init() in a separate file.
 	// Here we show them together for illustration.
-	RegisterProvider("aws", &AWSProvider{})
-	RegisterProvider("vault", &VaultProvider{})
-	// RegisterProvider("gcp", &GCPProvider{})
-	// RegisterProvider("azure", &AzureProvider{})
+	RegisterProvider("aws", &awsSecretsProvider{})
+	RegisterProvider("vault", &vaultProvider{})
+	RegisterProvider("gcp", &gcpProvider{})
+	// RegisterProvider("azure", &azureProvider{})
 	// ... 30+ more providers
 }
 
@@ -220,6 +195,8 @@ func init() {
 //   dataMap, err := r.GetProviderSecretData(ctx, externalSecret)
 
 func ExampleReconcilerUsage() {
+	ctx := context.Background()
+
 	// The SecretStore spec tells us which provider to use:
 	//   SecretStore:
 	//     spec:
@@ -230,26 +207,22 @@ func ExampleReconcilerUsage() {
 		"aws": map[string]string{"region": "us-east-1"},
 	}
 
-	// Look up the provider — the reconciler doesn't know it's AWS
-	provider, err := GetProviderFromSpec(storeSpec)
-	if err != nil {
-		panic(err)
-	}
-
-	// Create a client — could be AWS, Vault, GCP... the code is the same
-	client, err := provider.NewClient(map[string]string{"region": "us-east-1"})
+	// Look up the provider and create a client — the reconciler doesn't know
+	// it's AWS. Could be AWS, Vault, GCP... the code is the same.
+	client, err := GetProviderFromSpec(ctx, storeSpec) // AWS: creating client for region us-east-1
 	if err != nil {
 		panic(err)
 	}
+	defer client.Close(ctx) // clients hold connections/tokens
 
 	// Fetch the secret — generic interface, works for any provider
-	secret, _ := client.GetSecret("my-secret-key")
-	fmt.Println(string(secret)) // "aws-secret-value"
+	secret, _ := client.GetSecret(ctx, "my-secret-key") // AWS: fetching secret my-secret-key from region us-east-1
+	fmt.Println(string(secret))                         // aws-secret-value
 }
 
 // KEY INSIGHT:
 // Adding a new provider (e.g. "digitalocean") requires:
-//   1. Create providers/v1/digitalocean/ with DigitalOceanProvider implementing SecretProvider
+//   1. Create providers/v1/digitalocean/ with DigitalOceanProvider implementing SecretsProvider
 //   2. Create pkg/register/digitalocean.go with init() { Register(...) }
 //   3. Add build tag: //go:build digitalocean || all_providers
 //
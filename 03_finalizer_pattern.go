@@ -22,7 +22,11 @@ package guide
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"sync"
+	"time"
 )
 
 // Finalizer names must be globally unique to avoid collisions with other controllers.
@@ -36,20 +40,46 @@ const MyFinalizer = "mycontroller.example.com/cleanup"
 //   ExternalSecretFinalizer = "externalsecrets.external-secrets.io/externalsecret-cleanup"
 
 type Resource struct {
-	Name              string
-	Namespace         string
-	DeletionTimestamp *string            // nil = not being deleted, non-nil = marked for deletion
-	Finalizers        []string
-	Spec              ResourceSpec
+	ObjectMeta // Name, Namespace, Finalizers, DeletionTimestamp (nil = not being deleted)
+	Spec       ResourceSpec
+	Status     ResourceStatus
 }
 
 type ResourceSpec struct {
 	TargetSecretName string
-	DeletionPolicy   string // "Delete" = remove managed secret on cleanup; "Retain" = leave it
+	DeletionPolicy   string // "Delete", "Retain" (default) or "Merge" — see Pattern 35
+}
+
+type ResourceStatus struct {
+	// Per-finalizer cleanup progress while the object is terminating, so
+	// "why is this stuck?" is answered by kubectl get -o yaml.
+	Finalizers []FinalizerProgress
+
+	// CleanupStuck / CleanupAbandoned while terminating (see DeletionDeadlines).
+	Conditions []Condition
 }
 
 type FinalizerReconciler struct {
-	client FinalizerFakeClient
+	client     FinalizerFakeClient
+	secrets    KubeSecretClient  // target Secrets, for the default DeletionPolicy cleanup
+	recorder   EventRecorder     // abandonment and cleanup outcomes, not only on the dying object
+	finalizers *FinalizerManager // nil = MyFinalizer with the DeletionPolicy cleanup
+
+	Deadlines DeletionDeadlines
+	Clock     Clock // nil = RealClock; ages are measured from DeletionTimestamp
+}
+
+func NewFinalizerReconciler(client FinalizerFakeClient, secrets KubeSecretClient, recorder EventRecorder, finalizers *FinalizerManager) *FinalizerReconciler {
+	return &FinalizerReconciler{client: client, secrets: secrets, recorder: recorder, finalizers: finalizers}
+}
+
+func (r *FinalizerReconciler) manager() *FinalizerManager {
+	if r.finalizers != nil {
+		return r.finalizers
+	}
+	m := &FinalizerManager{}
+	m.Register(MyFinalizer, 0, r.cleanupManagedSecret)
+	return m
 }
 
 func (r *FinalizerReconciler) Reconcile(ctx context.Context, name, namespace string) error {
@@ -85,28 +115,65 @@ func (r *FinalizerReconciler) Reconcile(ctx context.Context, name, namespace str
 		// This is our window to perform cleanup. If cleanup fails, we return an error
 		// and the finalizer stays — the object remains in a "terminating" state until
 		// we successfully clean up and remove the finalizer.
-		if hasFinalizer(resource, MyFinalizer) {
-			// Clean up managed resources based on deletion policy.
-			// The DeletionPolicy gives users control: "Delete" cleans up the managed
-			// K8s Secret (default), while "Retain" leaves it in place for manual handling.
-			if resource.Spec.DeletionPolicy == "Delete" {
-				fmt.Println("deleting managed secret:", resource.Spec.TargetSecretName)
-				if err := r.client.DeleteSecret(ctx, resource.Spec.TargetSecretName, namespace); err != nil {
-					return err // Will retry — finalizer stays, object can't be deleted
-				}
-			} else {
-				fmt.Println("retaining managed secret:", resource.Spec.TargetSecretName)
+		//
+		// With several finalizers, each one is removed as soon as ITS cleanup
+		// succeeds; a failure keeps only that finalizer (see FinalizerManager).
+		// Deadlines bound how long a failing cleanup may hold the object.
+		m, clock := r.manager(), orRealClock(r.Clock)
+		age := clock.Since(*resource.DeletionTimestamp)
+		forced := resource.Annotations[AnnotationForceRemoveFinalizers] == "true"
+
+		var progress []FinalizerProgress
+		var cleanupErr error
+		if !forced {
+			progress, cleanupErr = m.Finalize(ctx, resource)
+		}
+		resource.Status.Conditions = nil
+		switch {
+		case forced:
+			progress = m.Abandon(resource, progress)
+			resource.Status.Conditions = cleanupCondition(clock, ConditionCleanupAbandoned, "ForceRemoved",
+				fmt.Sprintf("%s is set; remaining cleanup skipped", AnnotationForceRemoveFinalizers))
+		case cleanupErr != nil && r.Deadlines.AbandonAfter > 0 && age >= r.Deadlines.AbandonAfter:
+			progress = m.Abandon(resource, progress)
+			resource.Status.Conditions = cleanupCondition(clock, ConditionCleanupAbandoned, "DeadlineExceeded",
+				fmt.Sprintf("cleanup still failing after %s (limit %s), abandoned: %v", age, r.Deadlines.AbandonAfter, cleanupErr))
+			cleanupErr = nil // nothing left to retry
+		case cleanupErr != nil && r.Deadlines.WarnAfter > 0 && age >= r.Deadlines.WarnAfter:
+			resource.Status.Conditions = cleanupCondition(clock, ConditionCleanupStuck, "GracePeriodExceeded",
+				fmt.Sprintf("cleanup failing for %s: %v", age, cleanupErr))
+		}
+		for _, c := range resource.Status.Conditions {
+			if c.Type != ConditionCleanupAbandoned {
+				continue
+			}
+			// The condition dies with the object a moment from now; the Event
+			// is a separate object and outlives it. Recorded BEFORE the
+			// finalizers go: if it can't be written, nothing is removed and
+			// the next pass abandons — and records — again.
+			if err := r.recorder.Event(ctx, resource, EventTypeWarning, ConditionCleanupAbandoned,
+				c.Reason+": "+c.Message); err != nil {
+				return err
 			}
+		}
 
-			// Remove our finalizer — this is the critical step that unblocks deletion.
-			// Once we remove it, Kubernetes checks if any other finalizers remain.
+		// Status first: if the Update below removes the last finalizer, the
+		// object — and any status written after it — is gone.
+		remaining := resource.Finalizers
+		resource.Status.Finalizers = progress
+		if err := r.client.UpdateStatus(ctx, resource); err != nil && !isNotFoundErr(err) {
+			return err
+		}
+		if stored := resource.Finalizers; len(remaining) != len(stored) {
+			// Remove our finalizers — this is the critical step that unblocks deletion.
+			// Once they're gone, Kubernetes checks if any other finalizers remain.
 			// If not, the object is permanently deleted from etcd.
-			removeFinalizer(resource, MyFinalizer)
+			resource.Finalizers = remaining
 			if err := r.client.Update(ctx, resource); err != nil {
 				return err
 			}
 		}
-		return nil // Done, object will be deleted by Kubernetes
+		return cleanupErr // non-nil → requeue with backoff; finished finalizers won't run again
 	}
 
 	// =========================================================================
@@ -124,8 +191,7 @@ func (r *FinalizerReconciler) Reconcile(ctx context.Context, name, namespace str
 	//           return ctrl.Result{}, err
 	//       }
 	//   }
-	if !hasFinalizer(resource, MyFinalizer) {
-		addFinalizer(resource, MyFinalizer)
+	if r.manager().Ensure(resource) {
 		if err := r.client.Update(ctx, resource); err != nil {
 			return err
 		}
@@ -136,6 +202,415 @@ func (r *FinalizerReconciler) Reconcile(ctx context.Context, name, namespace str
 	return nil
 }
 
+// cleanupManagedSecret is MyFinalizer's cleanup: the DeletionPolicy gives
+// users control — "Delete" removes the managed K8s Secret, "Retain" (default)
+// leaves it in place for manual handling, "Merge" removes only our keys.
+// The same executor handles the provider's NoSecretErr (Pattern 35). What
+// it did is recorded as an Event: the object's status goes with the object.
+func (r *FinalizerReconciler) cleanupManagedSecret(ctx context.Context, resource *Resource) error {
+	es := &ExternalSecret{Name: resource.Name, Namespace: resource.Namespace, UID: resource.UID}
+	out, err := NewDeletionPolicyExecutor(r.secrets).
+		Apply(ctx, TriggerFinalize, es, resource.Spec.TargetSecretName, resource.Spec.DeletionPolicy)
+	if err != nil {
+		return err // Will retry — finalizer stays, object can't be deleted
+	}
+	return r.recorder.Event(ctx, resource, EventTypeNormal, ReasonDeletionPolicyApplied,
+		fmt.Sprintf("secret %s: %s", resource.Spec.TargetSecretName, out))
+}
+
+// ReasonDeletionPolicyApplied is the Event reason for a finished cleanupManagedSecret.
+const ReasonDeletionPolicyApplied = "DeletionPolicyApplied"
+
+// =============================================================================
+// Multiple Finalizers: FinalizerManager
+// =============================================================================
+//
+// One object often needs several independent cleanups — a PushSecret must
+// delete what it pushed to the provider AND clean up locally. One finalizer
+// per concern (instead of one finalizer running everything) means:
+//   - each subsystem registers its own name and cleanup, no shared switch
+//   - a finalizer is removed the moment ITS cleanup succeeds, so a retry
+//     never repeats work that already finished
+//   - "kubectl get" shows exactly which cleanup is holding the object
+//
+// Cleanups run in STAGES, lowest first. Finalizers in one stage are
+// independent and all run; a later stage waits until every finalizer of the
+// earlier stages is gone (e.g. "delete remote copies" before "drop the local
+// credentials used to reach the provider").
+//
+// Real code: pkg/controllers/pushsecret/pushsecret_controller.go (pushSecretFinalizer)
+
+type FinalizerFunc func(ctx context.Context, r *Resource) error
+
+const (
+	FinalizerDone    = "Done"    // cleanup succeeded; finalizer removed
+	FinalizerFailed  = "Failed"  // cleanup returned an error; finalizer kept
+	FinalizerWaiting = "Waiting" // an earlier stage hasn't finished
+)
+
+type FinalizerProgress struct {
+	Name    string
+	Stage   int
+	State   string // FinalizerDone, FinalizerFailed, FinalizerWaiting or FinalizerAbandoned
+	Message string
+}
+
+var ErrDuplicateFinalizer = errors.New("finalizer already registered")
+
+type FinalizerManager struct {
+	entries []registeredFinalizer // sorted by stage, then registration order
+}
+
+type registeredFinalizer struct {
+	name    string
+	stage   int
+	cleanup FinalizerFunc
+}
+
+// Register adds a finalizer. Names must be unique: two subsystems sharing a
+// name would each think the other's removal meant "my cleanup is done".
+func (m *FinalizerManager) Register(name string, stage int, cleanup FinalizerFunc) error {
+	for _, e := range m.entries {
+		if e.name == name {
+			return fmt.Errorf("%w: %s", ErrDuplicateFinalizer, name)
+		}
+	}
+	m.entries = append(m.entries, registeredFinalizer{name: name, stage: stage, cleanup: cleanup})
+	sort.SliceStable(m.entries, func(i, j int) bool { return m.entries[i].stage < m.entries[j].stage })
+	return nil
+}
+
+// Ensure adds every registered finalizer the object is missing (Step 1) and
+// reports whether it changed anything. Other controllers' finalizers are
+// never touched.
+func (m *FinalizerManager) Ensure(r *Resource) bool {
+	changed := false
+	for _, e := range m.entries {
+		if !hasFinalizer(r, e.name) {
+			addFinalizer(r, e.name)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// Finalize runs the cleanups still pending on r (Step 3), stage by stage,
+// and removes the finalizer of each one that succeeds. The returned error
+// joins every failure; progress covers every registered finalizer.
+func (m *FinalizerManager) Finalize(ctx context.Context, r *Resource) ([]FinalizerProgress, error) {
+	var (
+		progress []FinalizerProgress
+		errs     error
+		blocked  bool // an earlier stage still has a finalizer on the object
+	)
+	for i := 0; i < len(m.entries); {
+		// One stage: entries[i:j] share a stage number.
+		j := i
+		for j < len(m.entries) && m.entries[j].stage == m.entries[i].stage {
+			j++
+		}
+		stageBlocked := false
+		for _, e := range m.entries[i:j] {
+			p := FinalizerProgress{Name: e.name, Stage: e.stage, State: FinalizerDone}
+			switch {
+			case !hasFinalizer(r, e.name):
+				// Finished on an earlier pass (or never added).
+			case blocked:
+				p.State = FinalizerWaiting
+				p.Message = "waiting for earlier stages"
+				stageBlocked = true
+			default:
+				if err := e.cleanup(ctx, r); err != nil {
+					p.State = FinalizerFailed
+					p.Message = err.Error()
+					errs = errors.Join(errs, fmt.Errorf("finalizer %s: %w", e.name, err))
+					stageBlocked = true
+				} else {
+					removeFinalizer(r, e.name)
+				}
+			}
+			progress = append(progress, p)
+		}
+		blocked = blocked || stageBlocked
+		i = j
+	}
+	return progress, errs
+}
+
+// Abandon removes every registered finalizer still on r without running its
+// cleanup, marking it FinalizerAbandoned in progress (which is built from
+// scratch when no cleanup ran this pass).
+func (m *FinalizerManager) Abandon(r *Resource, progress []FinalizerProgress) []FinalizerProgress {
+	if progress == nil {
+		for _, e := range m.entries {
+			state := FinalizerDone
+			if hasFinalizer(r, e.name) {
+				state = FinalizerWaiting
+			}
+			progress = append(progress, FinalizerProgress{Name: e.name, Stage: e.stage, State: state})
+		}
+	}
+	for i, p := range progress {
+		if hasFinalizer(r, p.Name) {
+			removeFinalizer(r, p.Name)
+			progress[i].State = FinalizerAbandoned
+		}
+	}
+	return progress
+}
+
+// =============================================================================
+// Deletion Deadlines: When Cleanup Never Succeeds
+// =============================================================================
+//
+// A cleanup that can't succeed (provider account closed, credentials
+// revoked) leaves the object Terminating forever, and blocks namespace
+// deletion with it. Two escape hatches, both recorded rather than silent:
+//
+//   WarnAfter     CleanupStuck=True condition; cleanup keeps retrying
+//   AbandonAfter  CleanupAbandoned=True; remaining finalizers removed;
+//                 a Warning Event that outlives the object (see Events)
+//   annotation    AnnotationForceRemoveFinalizers="true": abandon now,
+//                 without even trying (the operator has decided)
+//
+// Both durations are measured from DeletionTimestamp, which is why it's a
+// time.Time and not a string. Zero disables that deadline.
+
+const AnnotationForceRemoveFinalizers = "finalizers.example.com/force-remove"
+
+const (
+	ConditionCleanupStuck     = "CleanupStuck"
+	ConditionCleanupAbandoned = "CleanupAbandoned"
+
+	FinalizerAbandoned = "Abandoned" // removed without its cleanup succeeding
+)
+
+type DeletionDeadlines struct {
+	WarnAfter    time.Duration
+	AbandonAfter time.Duration
+}
+
+func cleanupCondition(clock Clock, condType, reason, msg string) []Condition {
+	return []Condition{{
+		Type:               condType,
+		Status:             "True",
+		Reason:             reason,
+		Message:            msg,
+		LastTransitionTime: clock.Now(),
+	}}
+}
+
+// =============================================================================
+// Recording Abandonment: Events
+// =============================================================================
+//
+// A CleanupAbandoned condition is written to the object being deleted —
+// which is gone as soon as its last finalizer is. Whoever later asks "why is
+// there still a Secret in the provider?" needs a record that survives it.
+// That's what Events are for: separate objects in the same namespace, naming
+// the object they're about (kubectl get events, kubectl describe).
+//
+// Real code: mgr.GetEventRecorderFor("external-secrets") and
+// r.recorder.Event(externalSecret, v1.EventTypeWarning, reason, msg)
+
+const (
+	EventTypeNormal  = "Normal"
+	EventTypeWarning = "Warning"
+)
+
+// Event is a simplified corev1.Event.
+type Event struct {
+	ObjectMeta
+	InvolvedObject EventObjectReference
+	Type           string // EventTypeNormal or EventTypeWarning
+	Reason         string
+	Message        string
+	Timestamp      time.Time
+}
+
+// EventObjectReference identifies the object an Event is about. The UID
+// tells apart two objects that had the same name at different times.
+type EventObjectReference struct {
+	Kind      string
+	Namespace string
+	Name      string
+	UID       string
+}
+
+// EventRecorder records an Event about obj. Unlike client-go's recorder it
+// returns the write error, so a caller can refuse to proceed unrecorded.
+type EventRecorder interface {
+	Event(ctx context.Context, obj Object, eventType, reason, message string) error
+}
+
+// APIServerEventRecorder creates Events through the API server (Pattern 24).
+type APIServerEventRecorder struct {
+	server *FakeAPIServer
+
+	mu  sync.Mutex
+	seq int
+}
+
+func NewAPIServerEventRecorder(server *FakeAPIServer) *APIServerEventRecorder {
+	return &APIServerEventRecorder{server: server}
+}
+
+func (r *APIServerEventRecorder) Event(ctx context.Context, obj Object, eventType, reason, message string) error {
+	meta := obj.GetObjectMeta()
+	r.mu.Lock()
+	r.seq++
+	name := fmt.Sprintf("%s.%d", meta.Name, r.seq) // real names: <object>.<hex timestamp>
+	r.mu.Unlock()
+	return r.server.Create(ctx, &Event{
+		ObjectMeta:     ObjectMeta{Name: name, Namespace: meta.Namespace},
+		InvolvedObject: EventObjectReference{Kind: KindOf(obj), Namespace: meta.Namespace, Name: meta.Name, UID: meta.UID},
+		Type:           eventType,
+		Reason:         reason,
+		Message:        message,
+		Timestamp:      r.server.clock.Now(),
+	})
+}
+
+// EventsFor lists the Events about the named object, oldest first.
+func EventsFor(ctx context.Context, server *FakeAPIServer, kind, namespace, name string) ([]*Event, error) {
+	objs, err := server.List(ctx, ListOptions{Kind: "Event", Namespace: namespace})
+	if err != nil {
+		return nil, err
+	}
+	var events []*Event
+	for _, obj := range objs {
+		if ev := obj.(*Event); ev.InvolvedObject.Kind == kind && ev.InvolvedObject.Name == name {
+			events = append(events, ev)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { // resourceVersions are decimal counters
+		a, b := events[i].ResourceVersion, events[j].ResourceVersion
+		return len(a) < len(b) || len(a) == len(b) && a < b
+	})
+	return events, nil
+}
+
+// =============================================================================
+// Example: Remote and Local Cleanup, One of Which Fails Once
+// =============================================================================
+
+func ExampleFinalizerManager() {
+	ctx := context.Background()
+	server := NewFakeAPIServer()
+	client := NewFinalizerFakeClient(server)
+
+	providerDown := true
+	m := &FinalizerManager{}
+	m.Register("pushsecrets.example.com/remote-cleanup", 0, func(ctx context.Context, r *Resource) error {
+		if providerDown {
+			return errors.New("provider unavailable")
+		}
+		fmt.Println("  deleted remote copy")
+		return nil
+	})
+	m.Register("pushsecrets.example.com/audit", 0, func(ctx context.Context, r *Resource) error {
+		fmt.Println("  wrote audit record") // independent of the provider: runs once, on the first pass
+		return nil
+	})
+	m.Register("pushsecrets.example.com/local-cleanup", 1, func(ctx context.Context, r *Resource) error {
+		fmt.Println("  deleted local secret")
+		return nil
+	})
+	reconciler := NewFinalizerReconciler(client, NewAPIServerSecretClient(server), NewAPIServerEventRecorder(server), m)
+
+	server.Create(ctx, &Resource{ObjectMeta: ObjectMeta{Name: "my-push", Namespace: "default"}})
+	reconciler.Reconcile(ctx, "my-push", "default") // Step 1: adds all three
+	res, _ := client.Get(ctx, "my-push", "default")
+	fmt.Println("finalizers:", len(res.Finalizers)) // 3
+
+	server.Delete(ctx, res)
+	fmt.Println("pass 1:")
+	fmt.Println(" ", reconciler.Reconcile(ctx, "my-push", "default"))
+	res, _ = client.Get(ctx, "my-push", "default")
+	for _, p := range res.Status.Finalizers {
+		fmt.Printf("  %-40s stage=%d %s\n", p.Name, p.Stage, p.State)
+	}
+	// pass 1:
+	//   wrote audit record
+	//   finalizer pushsecrets.example.com/remote-cleanup: provider unavailable
+	//   pushsecrets.example.com/remote-cleanup   stage=0 Failed
+	//   pushsecrets.example.com/audit            stage=0 Done
+	//   pushsecrets.example.com/local-cleanup    stage=1 Waiting
+
+	providerDown = false
+	fmt.Println("pass 2:")
+	fmt.Println(" ", reconciler.Reconcile(ctx, "my-push", "default"))
+	_, err := client.Get(ctx, "my-push", "default")
+	fmt.Println("gone:", isNotFoundErr(err))
+	// pass 2:
+	//   deleted remote copy       ← audit does NOT run again
+	//   deleted local secret
+	//   <nil>
+	// gone: true
+}
+
+// =============================================================================
+// Example: A Cleanup That Never Succeeds
+// =============================================================================
+
+func ExampleDeletionDeadlines() {
+	ctx := context.Background()
+	clock := NewFakeClock(time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC))
+	server := NewFakeAPIServerWithClock(clock)
+	client := NewFinalizerFakeClient(server)
+
+	m := &FinalizerManager{}
+	m.Register(MyFinalizer, 0, func(ctx context.Context, r *Resource) error {
+		return errors.New("provider account closed")
+	})
+	reconciler := NewFinalizerReconciler(client, NewAPIServerSecretClient(server), NewAPIServerEventRecorder(server), m)
+	reconciler.Clock = clock
+	reconciler.Deadlines = DeletionDeadlines{WarnAfter: 10 * time.Minute, AbandonAfter: time.Hour}
+
+	for _, name := range []string{"stuck", "forced"} {
+		server.Create(ctx, &Resource{ObjectMeta: ObjectMeta{Name: name, Namespace: "default", Finalizers: []string{MyFinalizer}}})
+		res, _ := client.Get(ctx, name, "default")
+		server.Delete(ctx, res)
+	}
+	condition := func(name string) string {
+		res, err := client.Get(ctx, name, "default")
+		if err != nil {
+			return "gone"
+		}
+		if len(res.Status.Conditions) == 0 {
+			return "terminating, no condition"
+		}
+		return res.Status.Conditions[0].Type
+	}
+
+	reconciler.Reconcile(ctx, "stuck", "default")
+	fmt.Println("t=0:  ", condition("stuck")) // terminating, no condition — just retry
+
+	clock.Step(15 * time.Minute)
+	reconciler.Reconcile(ctx, "stuck", "default")
+	fmt.Println("t=15m:", condition("stuck")) // CleanupStuck — someone should look
+
+	clock.Step(time.Hour)
+	fmt.Println("t=75m:", reconciler.Reconcile(ctx, "stuck", "default"), condition("stuck"))
+	// t=75m: <nil> gone
+
+	// The object is gone; the record of what was skipped is not.
+	events, _ := EventsFor(ctx, server, "Resource", "default", "stuck")
+	for _, ev := range events {
+		fmt.Println(ev.Type, ev.Reason, ev.Message)
+	}
+	// Warning CleanupAbandoned DeadlineExceeded: cleanup still failing after 1h15m0s (limit 1h0m0s), abandoned: finalizer ...: provider account closed
+
+	// The operator doesn't want to wait: the annotation skips cleanup at once.
+	res, _ := client.Get(ctx, "forced", "default")
+	res.Annotations = map[string]string{AnnotationForceRemoveFinalizers: "true"}
+	client.Update(ctx, res)
+	reconciler.Reconcile(ctx, "forced", "default")
+	events, _ = EventsFor(ctx, server, "Resource", "default", "forced")
+	fmt.Println("forced:", condition("forced"), "-", events[0].Message)
+	// forced: gone - ForceRemoved: finalizers.example.com/force-remove is set; remaining cleanup skipped
+}
+
 // THE TIMELINE:
 //
 // Time 0: ExternalSecret "my-es" created
@@ -190,14 +665,29 @@ func removeFinalizer(r *Resource, finalizer string) {
 	r.Finalizers = result
 }
 
-func isNotFoundErr(err error) bool { return false }
+func isNotFoundErr(err error) bool { return errors.Is(err, ErrNotFound) }
+
+// FinalizerFakeClient is backed by the in-memory API server (Pattern 24), which
+// honors finalizers: deleting a Resource only sets DeletionTimestamp, and the
+// Update that removes the last finalizer is what actually deletes it.
+type FinalizerFakeClient struct {
+	server *FakeAPIServer
+}
 
-type FinalizerFakeClient struct{}
+func NewFinalizerFakeClient(server *FakeAPIServer) FinalizerFakeClient {
+	return FinalizerFakeClient{server: server}
+}
 
 func (c FinalizerFakeClient) Get(ctx context.Context, name, namespace string) (*Resource, error) {
-	return nil, nil
+	r := &Resource{}
+	if err := c.server.Get(ctx, namespace, name, r); err != nil {
+		return nil, err
+	}
+	return r, nil
 }
-func (c FinalizerFakeClient) Update(ctx context.Context, r *Resource) error    { return nil }
-func (c FinalizerFakeClient) DeleteSecret(ctx context.Context, name, ns string) error {
-	return nil
+func (c FinalizerFakeClient) Update(ctx context.Context, r *Resource) error {
+	return c.server.Update(ctx, r)
+}
+func (c FinalizerFakeClient) UpdateStatus(ctx context.Context, r *Resource) error {
+	return c.server.UpdateStatus(ctx, r)
 }
@@ -0,0 +1,388 @@
+package guide
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type finalizerFixture struct {
+	ctx        context.Context
+	clock      *FakeClock
+	server     *FakeAPIServer
+	client     FinalizerFakeClient
+	reconciler *FinalizerReconciler
+}
+
+// newFinalizerFixture registers one MyFinalizer whose cleanup returns
+// cleanupErr, and creates a terminating Resource per name.
+func newFinalizerFixture(t *testing.T, recorder EventRecorder, cleanupErr error, names ...string) *finalizerFixture {
+	t.Helper()
+	f := &finalizerFixture{ctx: context.Background(), clock: NewFakeClock(testEpoch)}
+	f.server = NewFakeAPIServerWithClock(f.clock)
+	f.client = NewFinalizerFakeClient(f.server)
+	if recorder == nil {
+		recorder = NewAPIServerEventRecorder(f.server)
+	}
+	m := &FinalizerManager{}
+	m.Register(MyFinalizer, 0, func(ctx context.Context, r *Resource) error { return cleanupErr })
+	f.reconciler = NewFinalizerReconciler(f.client, NewAPIServerSecretClient(f.server), recorder, m)
+	f.reconciler.Clock = f.clock
+	f.reconciler.Deadlines = DeletionDeadlines{WarnAfter: 10 * time.Minute, AbandonAfter: time.Hour}
+
+	for _, name := range names {
+		res := &Resource{ObjectMeta: ObjectMeta{Name: name, Namespace: "default", Finalizers: []string{MyFinalizer}}}
+		if err := f.server.Create(f.ctx, res); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.server.Delete(f.ctx, res); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return f
+}
+
+func (f *finalizerFixture) events(t *testing.T, name string) []*Event {
+	t.Helper()
+	events, err := EventsFor(f.ctx, f.server, "Resource", "default", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
+
+func TestAbandonedCleanupLeavesWarningEventThatOutlivesTheObject(t *testing.T) {
+	f := newFinalizerFixture(t, nil, errors.New("provider account closed"), "stuck")
+	res, _ := f.client.Get(f.ctx, "stuck", "default")
+	uid := res.UID
+
+	// Failing but within the deadlines: retried, nothing recorded.
+	f.reconciler.Reconcile(f.ctx, "stuck", "default")
+	f.clock.Step(15 * time.Minute)
+	f.reconciler.Reconcile(f.ctx, "stuck", "default")
+	if got := f.events(t, "stuck"); len(got) != 0 {
+		t.Fatalf("events before abandonment = %+v, want none", got)
+	}
+
+	f.clock.Step(time.Hour)
+	if err := f.reconciler.Reconcile(f.ctx, "stuck", "default"); err != nil {
+		t.Fatalf("abandoning Reconcile() = %v", err)
+	}
+	if _, err := f.client.Get(f.ctx, "stuck", "default"); !isNotFoundErr(err) {
+		t.Fatalf("object still present after abandonment: %v", err)
+	}
+
+	events := f.events(t, "stuck")
+	if len(events) != 1 {
+		t.Fatalf("events = %d, want 1", len(events))
+	}
+	ev := events[0]
+	if ev.Type != EventTypeWarning || ev.Reason != ConditionCleanupAbandoned {
+		t.Fatalf("event = %s %s, want Warning %s", ev.Type, ev.Reason, ConditionCleanupAbandoned)
+	}
+	if ev.InvolvedObject.UID != uid {
+		t.Fatalf("event UID = %q, want the deleted object's %q", ev.InvolvedObject.UID, uid)
+	}
+	if !strings.HasPrefix(ev.Message, "DeadlineExceeded: ") || !strings.Contains(ev.Message, "provider account closed") {
+		t.Fatalf("event message = %q", ev.Message)
+	}
+	if !ev.Timestamp.Equal(f.clock.Now()) {
+		t.Fatalf("event timestamp = %v, want %v", ev.Timestamp, f.clock.Now())
+	}
+}
+
+func TestForceRemoveRecordsWarningEvent(t *testing.T) {
+	f := newFinalizerFixture(t, nil, nil, "forced")
+	res, _ := f.client.Get(f.ctx, "forced", "default")
+	res.Annotations = map[string]string{AnnotationForceRemoveFinalizers: "true"}
+	if err := f.client.Update(f.ctx, res); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.reconciler.Reconcile(f.ctx, "forced", "default"); err != nil {
+		t.Fatal(err)
+	}
+	events := f.events(t, "forced")
+	if len(events) != 1 || !strings.HasPrefix(events[0].Message, "ForceRemoved: ") {
+		t.Fatalf("events = %+v, want one ForceRemoved warning", events)
+	}
+}
+
+type failingRecorder struct{}
+
+func (failingRecorder) Event(context.Context, Object, string, string, string) error {
+	return errors.New("events are unavailable")
+}
+
+// Abandoning without a record is not allowed: the finalizer stays and the
+// next pass tries again.
+func TestAbandonmentWaitsForTheEventToBeRecorded(t *testing.T) {
+	f := newFinalizerFixture(t, failingRecorder{}, errors.New("provider account closed"), "stuck")
+	f.clock.Step(2 * time.Hour)
+
+	if err := f.reconciler.Reconcile(f.ctx, "stuck", "default"); err == nil {
+		t.Fatal("Reconcile() = nil, want the recorder's error")
+	}
+	res, err := f.client.Get(f.ctx, "stuck", "default")
+	if err != nil {
+		t.Fatalf("object deleted without a recorded event: %v", err)
+	}
+	if !hasFinalizer(res, MyFinalizer) {
+		t.Fatal("finalizer removed without a recorded event")
+	}
+}
+
+func TestEventsForOrdersByCreation(t *testing.T) {
+	ctx := context.Background()
+	server := NewFakeAPIServer()
+	recorder := NewAPIServerEventRecorder(server)
+	obj := &Resource{ObjectMeta: ObjectMeta{Name: "r", Namespace: "default"}}
+	for _, reason := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l"} {
+		if err := recorder.Event(ctx, obj, EventTypeNormal, reason, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	events, _ := EventsFor(ctx, server, "Resource", "default", "r")
+	var got string
+	for _, ev := range events {
+		got += ev.Reason
+	}
+	if got != "abcdefghijkl" {
+		t.Fatalf("event order = %q", got)
+	}
+}
+
+type unreachableSecrets struct{ KubeSecretClient }
+
+func (unreachableSecrets) GetSecret(context.Context, string, string) (*Secret, error) {
+	return nil, errors.New("connection refused")
+}
+
+// defaultCleanupFixture runs the default MyFinalizer cleanup for a Resource
+// whose target Secret it controls.
+func defaultCleanupFixture(t *testing.T, policy string, secrets func(*FakeAPIServer) KubeSecretClient) (context.Context, *FakeAPIServer, *FinalizerReconciler) {
+	t.Helper()
+	ctx := context.Background()
+	server := NewFakeAPIServer()
+	reconciler := NewFinalizerReconciler(NewFinalizerFakeClient(server), secrets(server), NewAPIServerEventRecorder(server), nil)
+
+	res := &Resource{
+		ObjectMeta: ObjectMeta{Name: "my-es", Namespace: "default"},
+		Spec:       ResourceSpec{TargetSecretName: "db-creds", DeletionPolicy: policy},
+	}
+	if err := server.Create(ctx, res); err != nil {
+		t.Fatal(err)
+	}
+	es := &ExternalSecret{Name: res.Name, Namespace: res.Namespace, UID: res.UID}
+	secret := &Secret{ObjectMeta: ObjectMeta{Name: "db-creds", Namespace: "default"}, Data: map[string][]byte{"password": []byte("s3cr3t")}}
+	SetControllerReference(es, &secret.ObjectMeta)
+	StampOwnerLabels(es, &secret.ObjectMeta)
+	if err := server.Create(ctx, secret); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reconciler.Reconcile(ctx, "my-es", "default"); err != nil { // adds MyFinalizer
+		t.Fatal(err)
+	}
+	stored := &Resource{}
+	server.Get(ctx, "default", "my-es", stored)
+	if err := server.Delete(ctx, stored); err != nil {
+		t.Fatal(err)
+	}
+	return ctx, server, reconciler
+}
+
+func TestDefaultCleanupUsesInjectedSecretClientAndRecordsOutcome(t *testing.T) {
+	for _, tc := range []struct {
+		policy      string
+		secretGone  bool
+		wantMessage string
+	}{
+		{DeletionPolicyDelete, true, "secret db-creds: Finalize/Delete: secret deleted"},
+		{"", false, "secret db-creds: Finalize/Retain: secret kept, ownership removed=true"},
+	} {
+		ctx, server, reconciler := defaultCleanupFixture(t, tc.policy, NewAPIServerSecretClient)
+
+		if err := reconciler.Reconcile(ctx, "my-es", "default"); err != nil {
+			t.Fatalf("%q: Reconcile() = %v", tc.policy, err)
+		}
+		if err := server.Get(ctx, "default", "my-es", &Resource{}); !isNotFoundErr(err) {
+			t.Fatalf("%q: resource still present: %v", tc.policy, err)
+		}
+		err := server.Get(ctx, "default", "db-creds", &Secret{})
+		if gone := isNotFoundErr(err); gone != tc.secretGone {
+			t.Fatalf("%q: secret gone = %v, want %v", tc.policy, gone, tc.secretGone)
+		}
+
+		events, _ := EventsFor(ctx, server, "Resource", "default", "my-es")
+		if len(events) != 1 {
+			t.Fatalf("%q: events = %+v, want one", tc.policy, events)
+		}
+		if ev := events[0]; ev.Type != EventTypeNormal || ev.Reason != ReasonDeletionPolicyApplied || ev.Message != tc.wantMessage {
+			t.Fatalf("%q: event = %s %s %q, want Normal %s %q", tc.policy, ev.Type, ev.Reason, ev.Message, ReasonDeletionPolicyApplied, tc.wantMessage)
+		}
+	}
+}
+
+func TestDefaultCleanupFailureKeepsFinalizerAndRecordsNothing(t *testing.T) {
+	ctx, server, reconciler := defaultCleanupFixture(t, DeletionPolicyDelete, func(s *FakeAPIServer) KubeSecretClient {
+		return unreachableSecrets{NewAPIServerSecretClient(s)}
+	})
+
+	if err := reconciler.Reconcile(ctx, "my-es", "default"); err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Fatalf("Reconcile() = %v, want the secret client's error", err)
+	}
+	res := &Resource{}
+	if err := server.Get(ctx, "default", "my-es", res); err != nil || !hasFinalizer(res, MyFinalizer) {
+		t.Fatalf("finalizer must stay after a failed cleanup: err=%v finalizers=%v", err, res.Finalizers)
+	}
+	if res.Status.Finalizers[0].State != FinalizerFailed {
+		t.Fatalf("progress = %+v, want Failed", res.Status.Finalizers)
+	}
+	if events, _ := EventsFor(ctx, server, "Resource", "default", "my-es"); len(events) != 0 {
+		t.Fatalf("events = %+v, want none before the cleanup succeeds", events)
+	}
+	if err := server.Get(ctx, "default", "db-creds", &Secret{}); err != nil {
+		t.Fatalf("secret touched by a failed cleanup: %v", err)
+	}
+}
+
+const foreignResourceFinalizer = "other.example.com/keep"
+
+// stagedManager registers two stage-1 finalizers, "a" and "b", and one
+// stage-2 finalizer "c". b fails until failB is cleared; calls counts every
+// cleanup run.
+func stagedManager(t *testing.T, calls map[string]int, failB *bool) *FinalizerManager {
+	t.Helper()
+	m := &FinalizerManager{}
+	run := func(name string) FinalizerFunc {
+		return func(ctx context.Context, r *Resource) error {
+			calls[name]++
+			if name == "b" && *failB {
+				return errors.New("provider unreachable")
+			}
+			return nil
+		}
+	}
+	// Registered out of stage order: stages sort, registration order holds within one.
+	for _, reg := range []struct {
+		name  string
+		stage int
+	}{{"c", 2}, {"a", 1}, {"b", 1}} {
+		if err := m.Register(reg.name, reg.stage, run(reg.name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return m
+}
+
+func progressStates(progress []FinalizerProgress) string {
+	var parts []string
+	for _, p := range progress {
+		parts = append(parts, fmt.Sprintf("%s@%d=%s", p.Name, p.Stage, p.State))
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestFinalizerManagerRunsStagesAcrossPasses(t *testing.T) {
+	calls := map[string]int{}
+	failB := true
+	m := stagedManager(t, calls, &failB)
+	r := &Resource{ObjectMeta: ObjectMeta{Name: "app", Finalizers: []string{foreignResourceFinalizer}}}
+
+	if !m.Ensure(r) || m.Ensure(r) {
+		t.Fatal("Ensure should change the object once, then be a no-op")
+	}
+	if want := []string{foreignResourceFinalizer, "a", "b", "c"}; !slices.Equal(r.Finalizers, want) {
+		t.Fatalf("finalizers = %v, want %v", r.Finalizers, want)
+	}
+
+	// Pass 1: a succeeds, b fails, so stage 2 waits.
+	progress, err := m.Finalize(context.Background(), r)
+	if err == nil || !strings.Contains(err.Error(), "finalizer b: provider unreachable") {
+		t.Fatalf("err = %v, want b's failure", err)
+	}
+	if got, want := progressStates(progress), "a@1=Done b@1=Failed c@2=Waiting"; got != want {
+		t.Fatalf("pass 1 progress = %s, want %s", got, want)
+	}
+	if progress[1].Message != "provider unreachable" || progress[2].Message != "waiting for earlier stages" {
+		t.Fatalf("messages = %q, %q", progress[1].Message, progress[2].Message)
+	}
+	if want := []string{foreignResourceFinalizer, "b", "c"}; !slices.Equal(r.Finalizers, want) {
+		t.Fatalf("finalizers after pass 1 = %v, want %v", r.Finalizers, want)
+	}
+	if calls["c"] != 0 {
+		t.Fatal("stage 2 ran while stage 1 was failing")
+	}
+
+	// Pass 2: b recovers; a, already finished, isn't run again.
+	failB = false
+	progress, err = m.Finalize(context.Background(), r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := progressStates(progress), "a@1=Done b@1=Done c@2=Done"; got != want {
+		t.Fatalf("pass 2 progress = %s, want %s", got, want)
+	}
+	if want := (map[string]int{"a": 1, "b": 2, "c": 1}); !maps.Equal(calls, want) {
+		t.Fatalf("cleanup calls = %v, want %v", calls, want)
+	}
+	if want := []string{foreignResourceFinalizer}; !slices.Equal(r.Finalizers, want) {
+		t.Fatalf("finalizers after pass 2 = %v, want only the foreign one", r.Finalizers)
+	}
+
+	// A further pass is a no-op.
+	if _, err := m.Finalize(context.Background(), r); err != nil || calls["a"]+calls["b"]+calls["c"] != 4 {
+		t.Fatalf("pass 3 ran cleanups again: err=%v calls=%v", err, calls)
+	}
+}
+
+func TestFinalizerManagerRejectsDuplicateNames(t *testing.T) {
+	m := &FinalizerManager{}
+	noop := func(context.Context, *Resource) error { return nil }
+	if err := m.Register("a", 1, noop); err != nil {
+		t.Fatal(err)
+	}
+	err := m.Register("a", 2, noop) // a different stage doesn't make it a different finalizer
+	if !errors.Is(err, ErrDuplicateFinalizer) || !strings.Contains(err.Error(), "a") {
+		t.Fatalf("err = %v, want ErrDuplicateFinalizer naming a", err)
+	}
+	r := &Resource{}
+	m.Ensure(r)
+	if !slices.Equal(r.Finalizers, []string{"a"}) {
+		t.Fatalf("finalizers = %v, want [a] once", r.Finalizers)
+	}
+}
+
+func TestFinalizerManagerAbandonSkipsCleanupAndKeepsForeignFinalizers(t *testing.T) {
+	calls := map[string]int{}
+	failB := true
+	m := stagedManager(t, calls, &failB)
+	r := &Resource{ObjectMeta: ObjectMeta{Name: "app", Finalizers: []string{foreignResourceFinalizer}}}
+	m.Ensure(r)
+	progress, _ := m.Finalize(context.Background(), r)
+
+	// After a pass: its progress is updated in place.
+	abandoned := m.Abandon(&Resource{ObjectMeta: ObjectMeta{Finalizers: slices.Clone(r.Finalizers)}}, progress)
+	if got, want := progressStates(abandoned), "a@1=Done b@1=Abandoned c@2=Abandoned"; got != want {
+		t.Fatalf("abandon after a pass = %s, want %s", got, want)
+	}
+
+	// Without a pass (force-remove): progress is built from the object.
+	before := maps.Clone(calls)
+	abandoned = m.Abandon(r, nil)
+	if got, want := progressStates(abandoned), "a@1=Done b@1=Abandoned c@2=Abandoned"; got != want {
+		t.Fatalf("abandon without a pass = %s, want %s", got, want)
+	}
+	if !maps.Equal(calls, before) {
+		t.Fatalf("Abandon ran cleanups: %v → %v", before, calls)
+	}
+	if want := []string{foreignResourceFinalizer}; !slices.Equal(r.Finalizers, want) {
+		t.Fatalf("finalizers = %v, want only the foreign one", r.Finalizers)
+	}
+}
@@ -19,11 +19,15 @@
 //   controller-runtime/pkg/internal/controller/controller.go:259-313  (Start, worker loop)
 //   controller-runtime/pkg/internal/controller/controller.go:403-423  (processNextWorkItem)
 //   controller-runtime/pkg/internal/controller/controller.go:444-495  (reconcileHandler)
+//
+// A runnable version of the queue described here lives in 22_workqueue.go.
 
 package guide
 
 import (
+	"context"
 	"fmt"
+	"sync"
 	"time"
 )
 
@@ -166,12 +170,33 @@ func ExampleWorkerLoop() {
 	//
 	// This prevents race conditions where two goroutines try to
 	// update the same Secret at the same time.
+	//
+	// The Controller in 23_controller.go runs this loop for real.
+	var reconciled sync.WaitGroup
+	reconciled.Add(2)
+
+	ctrl := NewController("externalsecret", ReconcilerFunc(func(ctx context.Context, req Request) (ReconcileResult, error) {
+		defer reconciled.Done()
+		fmt.Println("processing", req)
+		return ReconcileResult{RequeueAfter: 1 * time.Hour}, nil
+	}), ControllerOptions{MaxConcurrentReconciles: 5})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ctrl.Start(ctx)
+	}()
+
+	// Two keys, five workers: two workers reconcile, three stay blocked in Get().
+	ctrl.Enqueue(Request{Namespace: "default", Name: "my-es-1"})
+	ctrl.Enqueue(Request{Namespace: "default", Name: "my-es-2"})
+	reconciled.Wait()
 
-	fmt.Println("Worker 1: processing default/my-es-1")
-	fmt.Println("Worker 2: processing default/my-es-2")
-	fmt.Println("Worker 3: waiting... (queue empty, blocked on Get())")
-	fmt.Println("Worker 4: waiting... (queue empty, blocked on Get())")
-	fmt.Println("Worker 5: waiting... (queue empty, blocked on Get())")
+	// Graceful shutdown: blocked workers wake up and exit, in-flight
+	// reconciles finish first.
+	cancel()
+	<-stopped
 }
 
 // KEY INSIGHT:
@@ -40,8 +40,13 @@ import (
 // This separation exists because authentication (NewClient) is expensive and
 // can be reused across multiple GetSecret calls. The reconciler creates ONE
 // client per reconciliation, then makes multiple calls to fetch different keys.
+//
+// This is the one provider interface: the registry (Pattern 02) stores it,
+// and older context-free providers are adapted to it (Pattern 38).
 type SecretsProvider interface {
 	NewClient(ctx context.Context, store StoreConfig) (SecretsClient, error)
+	Capabilities() Capabilities            // what the provider supports (Pattern 36)
+	ValidateStore(store StoreConfig) error // static config checks, no network (Pattern 37)
 }
 
 // SecretsClient fetches secrets from the external provider.
@@ -69,7 +74,9 @@ type StoreConfig struct {
 // Provider Implementations
 // =============================================================================
 // Each provider is a completely independent package.
-// They know nothing about each other.
+// They know nothing about each other. These are the implementations the
+// registry holds: Pattern 02's init() registers them as "aws", "vault"
+// and "gcp".
 
 // --- AWS Secrets Manager ---
 type awsSecretsProvider struct{}
@@ -80,6 +87,10 @@ func (p *awsSecretsProvider) NewClient(ctx context.Context, store StoreConfig) (
 	return &awsSecretsClient{region: store.Region}, nil
 }
 
+func (p *awsSecretsProvider) Capabilities() Capabilities { return CapAll }
+
+func (p *awsSecretsProvider) ValidateStore(store StoreConfig) error { return validateAWSStore(store) }
+
 type awsSecretsClient struct{ region string }
 
 func (c *awsSecretsClient) GetSecret(ctx context.Context, key string) ([]byte, error) {
@@ -100,6 +111,12 @@ func (p *vaultProvider) NewClient(ctx context.Context, store StoreConfig) (Secre
 	return &vaultClient{server: store.Server}, nil
 }
 
+func (p *vaultProvider) Capabilities() Capabilities {
+	return CapGetSecret | CapGetSecretMap | CapFindByName
+}
+
+func (p *vaultProvider) ValidateStore(store StoreConfig) error { return validateVaultStore(store) }
+
 type vaultClient struct{ server string }
 
 func (c *vaultClient) GetSecret(ctx context.Context, key string) ([]byte, error) {
@@ -120,6 +137,15 @@ func (p *gcpProvider) NewClient(ctx context.Context, store StoreConfig) (Secrets
 	return &gcpClient{}, nil
 }
 
+func (p *gcpProvider) Capabilities() Capabilities { return CapReadOnly }
+
+func (p *gcpProvider) ValidateStore(store StoreConfig) error {
+	if store.AuthConfig["project"] == "" {
+		return fmt.Errorf("%w: authConfig.project is required", ErrInvalidStore)
+	}
+	return nil
+}
+
 type gcpClient struct{}
 
 func (c *gcpClient) GetSecret(ctx context.Context, key string) ([]byte, error) {
@@ -155,7 +181,7 @@ func ExampleReconciler() {
 
 	// The reconciler gets the provider from the registry (Pattern 2)
 	// It doesn't know or care that it's AWS
-	var provider SecretsProvider = &awsSecretsProvider{} // returned by GetProvider(store)
+	provider, _ := GetProviderByName(store.Provider)
 
 	// Create an authenticated client
 	client, err := provider.NewClient(ctx, store)
@@ -174,7 +200,7 @@ func ExampleReconciler() {
 
 	// Now swap to Vault — the reconciler code is IDENTICAL
 	store2 := StoreConfig{Provider: "vault", Server: "https://vault.example.com"}
-	var provider2 SecretsProvider = &vaultProvider{}
+	provider2, _ := GetProviderByName(store2.Provider)
 	client2, _ := provider2.NewClient(ctx, store2)
 	defer client2.Close(ctx)
 	data2, _ := client2.GetSecret(ctx, "secret/data/my-app")
@@ -18,7 +18,14 @@
 
 package guide
 
-import "fmt"
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+)
 
 // =============================================================================
 // Layer 1: Kubernetes Owner References
@@ -65,6 +72,9 @@ type OwnerReference struct {
 // ExternalSecrets both targeted the same Secret with Controller=true, the second
 // SetControllerReference call would fail, surfacing the conflict immediately
 // rather than allowing a silent update war.
+//
+// BlockOwnerDeletion only matters for foreground deletion; Pattern 28 runs all
+// three propagation policies against the fake API server.
 
 // =============================================================================
 // Layer 2: Label-Based Ownership (for orphan detection)
@@ -87,27 +97,262 @@ type OwnerReference struct {
 //       secret.Labels[esv1.LabelOwner] = lblValue
 //   }
 
+// =============================================================================
+// The Ownership Engine
+// =============================================================================
+//
+// Both layers, implemented against a pluggable KubeSecretClient. The engine never
+// talks to the API server directly, so the same logic runs against the
+// FakeAPIServer (Pattern 24) or any store a test wants to plug in.
+
+const (
+	LabelOwner   = "reconcile.external-secrets.io/owner"
+	LabelManaged = "reconcile.external-secrets.io/managed"
+)
+
+// ErrSecretIsOwned means the target Secret is controlled by another object.
+// It's permanent: retrying won't help until a human resolves the conflict.
+// The controller isn't necessarily an ExternalSecret (a Helm release or an
+// operator can own a Secret too), so errors built with ownedBy name its kind.
+//
+// Real code: externalsecret_controller.go (ErrSecretIsOwned)
+var ErrSecretIsOwned = errors.New("target secret is controlled by another object")
+
+// ownedBy wraps ErrSecretIsOwned with the controller that holds the Secret.
+func ownedBy(ctrl *OwnerReference) error {
+	return fmt.Errorf("%w: %s %s (uid %s)", ErrSecretIsOwned, ctrl.Kind, ctrl.Name, ctrl.UID)
+}
+
+// KubeSecretClient is the slice of the Kubernetes API the ownership engine
+// needs. (Not to be confused with the provider-side SecretsClient in Pattern 05.)
+type KubeSecretClient interface {
+	GetSecret(ctx context.Context, namespace, name string) (*Secret, error)
+	ListSecrets(ctx context.Context, namespace string, labels map[string]string) ([]*Secret, error)
+	CreateSecret(ctx context.Context, secret *Secret) error
+	UpdateSecret(ctx context.Context, secret *Secret) error
+	DeleteSecret(ctx context.Context, secret *Secret) error
+}
+
+// NewAPIServerSecretClient adapts the FakeAPIServer to KubeSecretClient.
+func NewAPIServerSecretClient(server *FakeAPIServer) KubeSecretClient {
+	return apiServerSecrets{server: server}
+}
+
+type apiServerSecrets struct {
+	server *FakeAPIServer
+}
+
+func (c apiServerSecrets) GetSecret(ctx context.Context, namespace, name string) (*Secret, error) {
+	secret := &Secret{}
+	if err := c.server.Get(ctx, namespace, name, secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+func (c apiServerSecrets) ListSecrets(ctx context.Context, namespace string, labels map[string]string) ([]*Secret, error) {
+	objs, err := c.server.List(ctx, ListOptions{Kind: KindOf(&Secret{}), Namespace: namespace, LabelSelector: labels})
+	if err != nil {
+		return nil, err
+	}
+	secrets := make([]*Secret, 0, len(objs))
+	for _, obj := range objs {
+		secrets = append(secrets, obj.(*Secret))
+	}
+	return secrets, nil
+}
+
+func (c apiServerSecrets) CreateSecret(ctx context.Context, secret *Secret) error {
+	return c.server.Create(ctx, secret)
+}
+
+func (c apiServerSecrets) UpdateSecret(ctx context.Context, secret *Secret) error {
+	return c.server.Update(ctx, secret)
+}
+
+func (c apiServerSecrets) DeleteSecret(ctx context.Context, secret *Secret) error {
+	return c.server.Delete(ctx, secret)
+}
+
+// OwnerLabelValue is the owner label for an ExternalSecret. It's a hash rather
+// than the name itself because "namespace/name" can exceed the 63-character
+// label value limit and contains a "/", which labels don't allow.
+//
+// Real code: pkg/utils/utils.go (ObjectHash)
+func OwnerLabelValue(namespace, name string) string {
+	sum := sha256.Sum256([]byte(namespace + "/" + name))
+	return hex.EncodeToString(sum[:16])
+}
+
+// GetControllerOf returns the controller owner reference, or nil.
+//
+// Real code: k8s.io/apimachinery/pkg/apis/meta/v1/controller_ref.go (GetControllerOf)
+func GetControllerOf(meta *ObjectMeta) *OwnerReference {
+	for i := range meta.OwnerReferences {
+		if meta.OwnerReferences[i].Controller {
+			return &meta.OwnerReferences[i]
+		}
+	}
+	return nil
+}
+
+// SetControllerReference makes the ExternalSecret the controller owner of the
+// object. An existing controller with a different UID is a conflict: that
+// includes a deleted-and-recreated ExternalSecret with the same name, whose
+// old Secret the API server's GC is about to delete anyway.
+//
+// Real code: controller-runtime/pkg/controller/controllerutil/controllerutil.go
+// (SetControllerReference)
+func SetControllerReference(owner *ExternalSecret, meta *ObjectMeta) error {
+	if err := checkControllerOf(owner, meta); err != nil {
+		return err
+	}
+
+	ref := OwnerReference{
+		APIVersion:         "external-secrets.io/v1",
+		Kind:               "ExternalSecret",
+		Name:               owner.Name,
+		UID:                owner.UID,
+		Controller:         true,
+		BlockOwnerDeletion: true,
+	}
+	for i := range meta.OwnerReferences {
+		if meta.OwnerReferences[i].UID == owner.UID {
+			meta.OwnerReferences[i] = ref
+			return nil
+		}
+	}
+	meta.OwnerReferences = append(meta.OwnerReferences, ref)
+	return nil
+}
+
+// checkControllerOf refuses an object controlled by anything but owner. An
+// object with no controller, or controlled by owner itself, passes.
+func checkControllerOf(owner *ExternalSecret, meta *ObjectMeta) error {
+	if current := GetControllerOf(meta); current != nil && current.UID != owner.UID {
+		return ownedBy(current)
+	}
+	return nil
+}
+
+// RemoveOwnerReference drops the ExternalSecret's own owner reference and
+// owner label, leaving any other owner alone. A Secret switched from Owner to
+// Orphan must lose both: the reference would let Kubernetes GC delete it with
+// the ExternalSecret, and the label would let orphan detection delete it
+// after a target rename.
+func RemoveOwnerReference(owner *ExternalSecret, meta *ObjectMeta) {
+	meta.OwnerReferences = slices.DeleteFunc(meta.OwnerReferences, func(ref OwnerReference) bool {
+		return ref.UID == owner.UID
+	})
+	if len(meta.OwnerReferences) == 0 {
+		meta.OwnerReferences = nil
+	}
+	if meta.Labels[LabelOwner] == OwnerLabelValue(owner.Namespace, owner.Name) {
+		delete(meta.Labels, LabelOwner)
+	}
+}
+
+// StampOwnerLabels sets the layer 2 labels that orphan detection lists by.
+//
+// Real code: externalsecret_controller.go:519-530
+func StampOwnerLabels(owner *ExternalSecret, meta *ObjectMeta) {
+	if meta.Labels == nil {
+		meta.Labels = make(map[string]string)
+	}
+	meta.Labels[LabelOwner] = OwnerLabelValue(owner.Namespace, owner.Name)
+	meta.Labels[LabelManaged] = "true"
+}
+
+// OwnershipManager applies both layers of ownership to target Secrets.
+type OwnershipManager struct {
+	Client KubeSecretClient
+}
+
+func NewOwnershipManager(client KubeSecretClient) *OwnershipManager {
+	return &OwnershipManager{Client: client}
+}
+
+// EnsureTarget creates or updates the target Secret as a controlled child of
+// the ExternalSecret. The ownership check runs BEFORE any write, so a conflict
+// leaves the other owner's Secret untouched.
+func (m *OwnershipManager) EnsureTarget(ctx context.Context, owner *ExternalSecret, targetName string, data map[string][]byte) (*Secret, error) {
+	secret, err := m.Client.GetSecret(ctx, owner.Namespace, targetName)
+	create := errors.Is(err, ErrNotFound)
+	switch {
+	case create:
+		secret = &Secret{ObjectMeta: ObjectMeta{Name: targetName, Namespace: owner.Namespace}}
+	case err != nil:
+		return nil, err
+	}
+
+	if err := SetControllerReference(owner, &secret.ObjectMeta); err != nil {
+		return nil, err
+	}
+	StampOwnerLabels(owner, &secret.ObjectMeta)
+	secret.Data = data
+
+	if create {
+		return secret, m.Client.CreateSecret(ctx, secret)
+	}
+	return secret, m.Client.UpdateSecret(ctx, secret)
+}
+
+// DeleteOrphans deletes Secrets that carry this ExternalSecret's owner label
+// but aren't its current target — left behind when spec.target.name changed.
+// A labelled Secret controlled by a different UID is skipped: labels can be
+// copied by hand, owner references are what prove ownership. So is every
+// immutable generation of the current target ("<target>-N", Pattern 32):
+// after a Replace the live Secret isn't named currentTarget at all.
+//
+// Real code: externalsecret_controller.go:842-871 (deleteOrphanedSecrets)
+func (m *OwnershipManager) DeleteOrphans(ctx context.Context, owner *ExternalSecret, currentTarget string) ([]string, error) {
+	secrets, err := m.Client.ListSecrets(ctx, owner.Namespace, map[string]string{
+		LabelOwner: OwnerLabelValue(owner.Namespace, owner.Name),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var deleted []string
+	var errs error
+	for _, secret := range secrets {
+		if isImmutableGeneration(currentTarget, secret.Name) {
+			continue
+		}
+		if ctrl := GetControllerOf(&secret.ObjectMeta); ctrl != nil && ctrl.UID != owner.UID {
+			continue
+		}
+		if err := m.Client.DeleteSecret(ctx, secret); err != nil && !errors.Is(err, ErrNotFound) {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		deleted = append(deleted, secret.Name)
+	}
+	return deleted, errs
+}
+
 func ExampleOrphanDetection() {
-	// Step 1: ExternalSecret "my-es" with target.name = "secret-a"
-	//   → Creates Secret "secret-a" with label: reconcile.external-secrets.io/owner=hash("default/my-es")
+	ctx := context.Background()
+	client := NewAPIServerSecretClient(NewFakeAPIServer())
+	mgr := NewOwnershipManager(client)
+	es := &ExternalSecret{Name: "my-es", Namespace: "default", UID: "uid-es-1", CreationPolicy: "Owner"}
+	data := map[string][]byte{"password": []byte("s3cret")}
 
-	// Step 2: User updates ExternalSecret "my-es" with target.name = "secret-b"
-	//   → Creates Secret "secret-b" with label: reconcile.external-secrets.io/owner=hash("default/my-es")
-	//   → Secret "secret-a" still exists with the same owner label!
+	// Step 1: target.name = "secret-a"
+	mgr.EnsureTarget(ctx, es, "secret-a", data)
 
-	// Step 3: Reconcile detects the orphan
-	//   Real code: externalsecret_controller.go:842-871 (deleteOrphanedSecrets)
-	//
-	//   ownerLabel := esutils.ObjectHash("default/my-es")
-	//   secretList := client.List(labels: {"reconcile.external-secrets.io/owner": ownerLabel})
-	//   for _, secret := range secretList {
-	//       if secret.Name != "secret-b" {   // not the current target
-	//           client.Delete(secret)          // delete orphan "secret-a"
-	//       }
-	//   }
+	// Step 2: target.name changed to "secret-b". Both now carry the owner label
+	// and a valid ownerReference — Kubernetes GC won't touch "secret-a".
+	mgr.EnsureTarget(ctx, es, "secret-b", data)
 
-	fmt.Println("Orphan 'secret-a' detected and deleted")
-	fmt.Println("Current target 'secret-b' kept")
+	// Step 3: reconcile lists by owner label and deletes everything else.
+	deleted, _ := mgr.DeleteOrphans(ctx, es, "secret-b")
+	fmt.Println("orphans deleted:", deleted) // [secret-a]
+
+	remaining, _ := client.ListSecrets(ctx, "default", nil)
+	for _, s := range remaining {
+		fmt.Println("kept:", s.Name) // secret-b
+	}
 }
 
 // =============================================================================
@@ -129,14 +374,23 @@ func ExampleOrphanDetection() {
 //   }
 
 func ExampleConflictPrevention() {
-	// ExternalSecret "es-1" creates Secret "shared-secret" with ownerRef → es-1
-	// ExternalSecret "es-2" tries to update Secret "shared-secret"
-	//   → Sees ownerRef points to "es-1", not "es-2"
-	//   → Returns ErrSecretIsOwned
-	//   → Does NOT retry (permanent error)
-	//   → Status shows: "target is owned by another ExternalSecret: es-1"
+	ctx := context.Background()
+	client := NewAPIServerSecretClient(NewFakeAPIServer())
+	mgr := NewOwnershipManager(client)
+	es1 := &ExternalSecret{Name: "es-1", Namespace: "default", UID: "uid-es-1", CreationPolicy: "Owner"}
+	es2 := &ExternalSecret{Name: "es-2", Namespace: "default", UID: "uid-es-2", CreationPolicy: "Owner"}
+
+	mgr.EnsureTarget(ctx, es1, "shared-secret", map[string][]byte{"key": []byte("from-es-1")})
 
-	fmt.Println("es-2 refused: secret is owned by es-1")
+	// es-2 targets the same name. The check runs before any write, and the
+	// error is permanent, so it goes to status instead of the retry queue.
+	_, err := mgr.EnsureTarget(ctx, es2, "shared-secret", map[string][]byte{"key": []byte("from-es-2")})
+	fmt.Println("es-2:", err)
+	// target secret is controlled by another object: ExternalSecret es-1 (uid uid-es-1)
+	fmt.Println("permanent:", errors.Is(err, ErrSecretIsOwned)) // true
+
+	secret, _ := client.GetSecret(ctx, "default", "shared-secret")
+	fmt.Println("data still:", string(secret.Data["key"])) // from-es-1
 }
 
 // =============================================================================
@@ -148,28 +402,44 @@ func ExampleConflictPrevention() {
 // The project supports different ownership models:
 
 func ExampleCreationPolicies() {
-	// Owner (default):
-	//   - Sets ownerReference on the Secret
-	//   - Kubernetes auto-deletes Secret when ExternalSecret is deleted
-	//   - Orphan detection cleans up old secrets when target name changes
-	fmt.Println("Owner: full lifecycle management")
-
-	// Merge:
-	//   - Does NOT create the Secret (must already exist)
-	//   - Merges data into existing Secret
-	//   - No ownerReference (someone else owns it)
-	fmt.Println("Merge: only update existing secrets")
-
-	// Orphan:
-	//   - Creates the Secret if needed
-	//   - No ownerReference (Secret survives ExternalSecret deletion)
-	//   - Secret is "abandoned" when ExternalSecret is deleted
-	fmt.Println("Orphan: create but don't clean up")
-
-	// None:
-	//   - Does nothing to the Secret
-	//   - Only updates status
-	fmt.Println("None: read-only mode")
+	ctx := context.Background()
+	providerData := map[string][]byte{"password": []byte("from-provider")}
+
+	for _, policy := range []string{CreatePolicyOwner, CreatePolicyOrphan, CreatePolicyMerge, CreatePolicyNone} {
+		client := NewAPIServerSecretClient(NewFakeAPIServer())
+		es := &ExternalSecret{Name: "my-es", Namespace: "default", UID: "uid-my-es", CreationPolicy: policy}
+
+		// A Secret created by another tool, with a key that isn't ours.
+		client.CreateSecret(ctx, &Secret{
+			ObjectMeta: ObjectMeta{Name: "app-config", Namespace: "default"},
+			Data:       map[string][]byte{"api-url": []byte("https://example.com")},
+		})
+
+		// Target 1: the shared Secret. Target 2: a Secret that doesn't exist yet.
+		errShared := applyCreationPolicy(ctx, client, es, "app-config", buildMutationFunc(es, providerData))
+		errNew := applyCreationPolicy(ctx, client, es, "my-secret", buildMutationFunc(es, providerData))
+
+		shared, _ := client.GetSecret(ctx, "default", "app-config")
+		fmt.Printf("%-6s app-config: keys=%v ownerRefs=%d err=%v\n",
+			policy, dataKeys(shared), len(shared.OwnerReferences), errShared)
+		if created, err := client.GetSecret(ctx, "default", "my-secret"); err == nil {
+			fmt.Printf("       my-secret: created, ownerRefs=%d\n", len(created.OwnerReferences))
+		} else {
+			fmt.Printf("       my-secret: not created, err=%v\n", errNew)
+		}
+	}
+	// Owner  app-config: keys=[password] ownerRefs=1 err=<nil>
+	//        my-secret: created, ownerRefs=1
+	// Orphan app-config: keys=[password] ownerRefs=0 err=<nil>
+	//        my-secret: created, ownerRefs=0
+	// Merge  app-config: keys=[api-url password] ownerRefs=0 err=<nil>
+	//        my-secret: not created, err=creationPolicy=Merge, secret default/my-secret: target secret does not exist ...
+	// None   app-config: keys=[api-url] ownerRefs=0 err=<nil>
+	//        my-secret: not created, err=<nil>
+	//
+	// The reconciler tells permanent failures apart with errors.Is:
+	//   errors.Is(err, ErrMergeTargetNotFound) → status "waiting for target", no retry storm
+	//   errors.Is(err, ErrSecretIsOwned)       → status "controlled by <kind> <name>"
 }
 
 // KEY INSIGHT:
@@ -0,0 +1,223 @@
+package guide
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// ownershipFixture is an ExternalSecret stored in the API server, so the
+// garbage collector (Pattern 28) sees the same UID the ownership engine
+// writes into owner references.
+type ownershipFixture struct {
+	server *FakeAPIServer
+	client KubeSecretClient
+	mgr    *OwnershipManager
+	gc     *GarbageCollector
+}
+
+func newOwnershipFixture() *ownershipFixture {
+	server := NewFakeAPIServer()
+	client := NewAPIServerSecretClient(server)
+	return &ownershipFixture{server: server, client: client, mgr: NewOwnershipManager(client), gc: NewGarbageCollector(server)}
+}
+
+func (f *ownershipFixture) externalSecret(t *testing.T, name string) (*ExternalSecret, *Unstructured) {
+	t.Helper()
+	obj := &Unstructured{Kind: "ExternalSecret", ObjectMeta: ObjectMeta{Name: name, Namespace: "default"}}
+	if err := f.server.Create(context.Background(), obj); err != nil {
+		t.Fatal(err)
+	}
+	return &ExternalSecret{Name: name, Namespace: "default", UID: obj.UID, CreationPolicy: CreatePolicyOwner}, obj
+}
+
+func (f *ownershipFixture) secretNames(t *testing.T) []string {
+	t.Helper()
+	secrets, err := f.client.ListSecrets(context.Background(), "default", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, s := range secrets {
+		names = append(names, s.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func (f *ownershipFixture) ensure(t *testing.T, es *ExternalSecret, target string) {
+	t.Helper()
+	if _, err := f.mgr.EnsureTarget(context.Background(), es, target, map[string][]byte{"k": []byte(target)}); err != nil {
+		t.Fatalf("EnsureTarget(%s) = %v", target, err)
+	}
+}
+
+func TestEnsureTargetSetsBothOwnershipLayers(t *testing.T) {
+	f := newOwnershipFixture()
+	es, _ := f.externalSecret(t, "my-es")
+	f.ensure(t, es, "secret-a")
+
+	secret, _ := f.client.GetSecret(context.Background(), "default", "secret-a")
+	ctrl := GetControllerOf(&secret.ObjectMeta)
+	if ctrl == nil || ctrl.UID != es.UID || ctrl.Kind != "ExternalSecret" || !ctrl.BlockOwnerDeletion {
+		t.Fatalf("controller ref = %+v, want ExternalSecret uid %s", ctrl, es.UID)
+	}
+	if got := secret.Labels[LabelOwner]; got != OwnerLabelValue("default", "my-es") {
+		t.Fatalf("owner label = %q", got)
+	}
+	if secret.Labels[LabelManaged] != "true" {
+		t.Fatal("managed label missing")
+	}
+
+	// A second ensure updates in place instead of stacking references.
+	f.ensure(t, es, "secret-a")
+	secret, _ = f.client.GetSecret(context.Background(), "default", "secret-a")
+	if len(secret.OwnerReferences) != 1 {
+		t.Fatalf("ownerReferences = %d after re-ensure, want 1", len(secret.OwnerReferences))
+	}
+}
+
+// Kubernetes GC alone never collects a renamed-away target: its owner still
+// exists. Only DeleteOrphans does, and then deleting the ExternalSecret
+// collects the current target.
+func TestRenameThenGCLeavesNothingBehind(t *testing.T) {
+	ctx := context.Background()
+	f := newOwnershipFixture()
+	es, esObj := f.externalSecret(t, "my-es")
+
+	f.ensure(t, es, "secret-a")
+	f.ensure(t, es, "secret-b")
+	f.ensure(t, es, "secret-c")
+
+	if report, _ := f.gc.Sweep(ctx); len(report.Deleted) != 0 {
+		t.Fatalf("GC deleted %v while the owner exists", report.Deleted)
+	}
+
+	deleted, err := f.mgr.DeleteOrphans(ctx, es, "secret-c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(deleted)
+	if !slices.Equal(deleted, []string{"secret-a", "secret-b"}) {
+		t.Fatalf("orphans deleted = %v, want [secret-a secret-b]", deleted)
+	}
+	if got := f.secretNames(t); !slices.Equal(got, []string{"secret-c"}) {
+		t.Fatalf("secrets after orphan sweep = %v", got)
+	}
+
+	if err := f.gc.Delete(ctx, esObj, DeletePropagationBackground); err != nil {
+		t.Fatal(err)
+	}
+	f.gc.Sweep(ctx)
+	if got := f.secretNames(t); len(got) != 0 {
+		t.Fatalf("secrets after deleting the ExternalSecret = %v, want none", got)
+	}
+}
+
+// Without the orphan sweep, every target ever written still points at the
+// owner, so they all go when it does — but not a moment before.
+func TestRenameWithoutOrphanSweepIsCollectedWithOwner(t *testing.T) {
+	ctx := context.Background()
+	f := newOwnershipFixture()
+	es, esObj := f.externalSecret(t, "my-es")
+	f.ensure(t, es, "secret-a")
+	f.ensure(t, es, "secret-b")
+
+	f.gc.Delete(ctx, esObj, DeletePropagationBackground)
+	report, _ := f.gc.Sweep(ctx)
+	if len(report.Deleted) != 2 {
+		t.Fatalf("GC deleted %v, want both targets", report.Deleted)
+	}
+}
+
+func TestRenameBackKeepsTheReusedTarget(t *testing.T) {
+	ctx := context.Background()
+	f := newOwnershipFixture()
+	es, _ := f.externalSecret(t, "my-es")
+	f.ensure(t, es, "secret-a")
+	f.ensure(t, es, "secret-b")
+	f.ensure(t, es, "secret-a") // renamed back before the sweep ran
+
+	deleted, _ := f.mgr.DeleteOrphans(ctx, es, "secret-a")
+	if !slices.Equal(deleted, []string{"secret-b"}) {
+		t.Fatalf("orphans deleted = %v, want [secret-b]", deleted)
+	}
+	if got := f.secretNames(t); !slices.Equal(got, []string{"secret-a"}) {
+		t.Fatalf("secrets = %v, want [secret-a]", got)
+	}
+}
+
+// Labels can be copied; the controller reference decides. A Secret carrying
+// our owner label but controlled by someone else is never an orphan of ours.
+func TestDeleteOrphansSkipsSecretsControlledByAnotherUID(t *testing.T) {
+	ctx := context.Background()
+	f := newOwnershipFixture()
+	es, _ := f.externalSecret(t, "my-es")
+	other, _ := f.externalSecret(t, "other-es")
+
+	f.ensure(t, es, "secret-a")
+	f.ensure(t, other, "copied")
+	copied, _ := f.client.GetSecret(ctx, "default", "copied")
+	copied.Labels[LabelOwner] = OwnerLabelValue("default", "my-es")
+	f.client.UpdateSecret(ctx, copied)
+
+	deleted, _ := f.mgr.DeleteOrphans(ctx, es, "secret-a")
+	if len(deleted) != 0 {
+		t.Fatalf("deleted %v, want nothing", deleted)
+	}
+}
+
+// An ExternalSecret deleted with Orphan propagation and recreated under the
+// same name gets a new UID. Its old target still carries the same owner
+// label but points at the dead UID; the new one must not adopt or delete it.
+func TestRecreatedExternalSecretDoesNotAdoptOldTarget(t *testing.T) {
+	ctx := context.Background()
+	f := newOwnershipFixture()
+	es, esObj := f.externalSecret(t, "my-es")
+	f.ensure(t, es, "secret-a")
+
+	f.gc.Delete(ctx, esObj, DeletePropagationOrphan)
+	f.gc.Sweep(ctx)
+	// The orphan finalizer removed the ownerRef; put a stale one back, as a
+	// Secret restored from a backup would have.
+	restored, _ := f.client.GetSecret(ctx, "default", "secret-a")
+	restored.OwnerReferences = []OwnerReference{{Kind: "ExternalSecret", Name: "my-es", UID: es.UID, Controller: true}}
+	f.client.UpdateSecret(ctx, restored)
+
+	recreated, _ := f.externalSecret(t, "my-es")
+	if recreated.UID == es.UID {
+		t.Fatal("fixture: recreated ExternalSecret reused the UID")
+	}
+
+	_, err := f.mgr.EnsureTarget(ctx, recreated, "secret-a", map[string][]byte{"k": []byte("new")})
+	if !errors.Is(err, ErrSecretIsOwned) {
+		t.Fatalf("EnsureTarget on the old target = %v, want ErrSecretIsOwned", err)
+	}
+	f.ensure(t, recreated, "secret-b")
+	if deleted, _ := f.mgr.DeleteOrphans(ctx, recreated, "secret-b"); len(deleted) != 0 {
+		t.Fatalf("deleted %v: the old target is not ours", deleted)
+	}
+	if got := f.secretNames(t); !slices.Equal(got, []string{"secret-a", "secret-b"}) {
+		t.Fatalf("secrets = %v", got)
+	}
+}
+
+func TestEnsureTargetConflictLeavesOtherOwnersSecretUntouched(t *testing.T) {
+	ctx := context.Background()
+	f := newOwnershipFixture()
+	es1, _ := f.externalSecret(t, "es-1")
+	es2, _ := f.externalSecret(t, "es-2")
+	f.ensure(t, es1, "shared")
+	before, _ := f.client.GetSecret(ctx, "default", "shared")
+
+	_, err := f.mgr.EnsureTarget(ctx, es2, "shared", map[string][]byte{"k": []byte("es-2")})
+	if !errors.Is(err, ErrSecretIsOwned) {
+		t.Fatalf("err = %v, want ErrSecretIsOwned", err)
+	}
+	after, _ := f.client.GetSecret(ctx, "default", "shared")
+	if after.ResourceVersion != before.ResourceVersion || string(after.Data["k"]) != "shared" {
+		t.Fatalf("conflicting ensure wrote to the Secret: rv %s→%s data %q",
+			before.ResourceVersion, after.ResourceVersion, after.Data["k"])
+	}
+}
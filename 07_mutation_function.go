@@ -16,7 +16,10 @@
 package guide
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sort"
 )
 
 // =============================================================================
@@ -27,19 +30,73 @@ import (
 // then applies the desired state to ANY Secret object (new or existing).
 
 type Secret struct {
-	Name            string
-	Namespace       string
-	Labels          map[string]string
-	Annotations     map[string]string
-	Data            map[string][]byte
-	OwnerReferences []string
+	ObjectMeta // Name, Namespace, Labels, Annotations, OwnerReferences
+	Data       map[string][]byte
+	Immutable  bool // once true, the API server rejects every change to Data (Pattern 32)
 }
 
 type ExternalSecret struct {
-	Name           string
-	Namespace      string
-	CreationPolicy string
-	Immutable      bool
+	Name              string
+	Namespace         string
+	UID               string          // metadata.uid; identifies the owner across renames (Pattern 06)
+	CreationPolicy    string          // "Owner" (default), "Merge", "Orphan", "None"
+	Immutable         bool            // spec.target.immutable: create the Secret immutable (Pattern 32)
+	ImmutableStrategy string          // "Fail" (default) or "Replace" when provider data changes (Pattern 32)
+	Template          *SecretTemplate // spec.target.template; nil copies provider keys verbatim (Pattern 30)
+	Rewrite           []KeyRewrite    // spec.dataFrom[].rewrite, applied before the template (Pattern 31)
+}
+
+// =============================================================================
+// Creation Policies
+// =============================================================================
+//
+// Real code: apis/externalsecrets/v1/externalsecret_types.go (ExternalSecretCreationPolicy)
+//
+//   Owner   create/update the Secret and own it (ownerRef + owner label);
+//           its data is exactly the provider data, nothing else
+//   Orphan  create/update the Secret but never own it; it survives deletion.
+//           Its data is replaced the same way as Owner's
+//   Merge   never create; write only our keys into a Secret someone else made
+//   None    never write; status only
+
+const (
+	CreatePolicyOwner  = "Owner"
+	CreatePolicyOrphan = "Orphan"
+	CreatePolicyMerge  = "Merge"
+	CreatePolicyNone   = "None"
+)
+
+// Sentinel causes, checked with errors.Is. Each is wrapped in a
+// CreationPolicyError that says which policy and Secret were involved.
+var (
+	ErrMergeTargetNotFound   = errors.New("target secret does not exist and creationPolicy=Merge never creates it")
+	ErrCreationPolicyNone    = errors.New("creationPolicy=None never writes the target secret")
+	ErrUnknownCreationPolicy = errors.New("unknown creationPolicy")
+)
+
+// CreationPolicyError is what the create/update path returns. A reconciler
+// uses errors.As to read the policy, and errors.Is on the cause to decide
+// whether retrying can help: ErrSecretIsOwned, ErrMergeTargetNotFound and
+// ErrMergeKeyConflict (Pattern 33) all need a human (or another controller)
+// to act first.
+type CreationPolicyError struct {
+	Policy    string
+	Namespace string
+	Name      string
+	Err       error
+}
+
+func (e *CreationPolicyError) Error() string {
+	return fmt.Sprintf("creationPolicy=%s, secret %s/%s: %v", e.Policy, e.Namespace, e.Name, e.Err)
+}
+
+func (e *CreationPolicyError) Unwrap() error { return e.Err }
+
+func creationPolicy(es *ExternalSecret) string {
+	if es.CreationPolicy == "" {
+		return CreatePolicyOwner
+	}
+	return es.CreationPolicy
 }
 
 // This is how the real code defines it:
@@ -63,6 +120,25 @@ type ExternalSecret struct {
 // secret should look like, regardless of when or where it's called.
 func buildMutationFunc(es *ExternalSecret, providerData map[string][]byte) func(*Secret) error {
 	return func(secret *Secret) error {
+		policy := creationPolicy(es)
+		switch policy {
+		case CreatePolicyOwner, CreatePolicyOrphan, CreatePolicyMerge:
+		case CreatePolicyNone:
+			return ErrCreationPolicyNone
+		default:
+			return fmt.Errorf("%w %q", ErrUnknownCreationPolicy, es.CreationPolicy)
+		}
+
+		// Every policy that writes refuses BEFORE touching anything if
+		// another object controls the Secret. Orphan and Merge never set an
+		// ownerRef themselves, but writing a Secret another ExternalSecret
+		// (or a Certificate, or a Helm release) controls would have the two
+		// overwrite each other on every sync.
+		// Real code: externalsecret_controller.go:446-468
+		if err := checkControllerOf(es, &secret.ObjectMeta); err != nil {
+			return err
+		}
+
 		// Initialize maps to avoid nil pointer panics when setting values.
 		// This is defensive coding — the secret might be brand new (create path)
 		// with nil maps, or it might be an existing secret (update path) that
@@ -78,29 +154,83 @@ func buildMutationFunc(es *ExternalSecret, providerData map[string][]byte) func(
 			secret.Data = make(map[string][]byte)
 		}
 
-		// Set owner reference if policy is "Owner"
-		// Real code: externalsecret_controller.go:463-468
-		if es.CreationPolicy == "Owner" {
-			secret.OwnerReferences = []string{es.Name}
+		// Ownership depends on the creation policy (Pattern 06).
+		// Real code: externalsecret_controller.go:446-468, 519-527
+		switch policy {
+		case CreatePolicyOwner:
+			if err := SetControllerReference(es, &secret.ObjectMeta); err != nil {
+				return err
+			}
+			StampOwnerLabels(es, &secret.ObjectMeta)
+		case CreatePolicyOrphan:
+			// Ours to manage, but no ownerRef (survives ExternalSecret deletion)
+			// and no owner label (orphan detection must never delete it). Both
+			// are removed if a previous Owner policy set them.
+			RemoveOwnerReference(es, &secret.ObjectMeta)
+			secret.Labels[LabelManaged] = "true"
+		case CreatePolicyMerge:
+			// Someone else's Secret: no ownerRef, no labels. Only our data
+			// keys are written below; every other key is left untouched.
 		}
 
-		// Apply provider data (or template transformation)
+		// Rewrite provider keys (Pattern 31), then apply the data or the
+		// template transformation over the rewritten keys.
 		// Real code: externalsecret_controller.go:513
 		//   err = r.ApplyTemplate(ctx, externalSecret, secret, dataMap)
-		for k, v := range providerData {
-			secret.Data[k] = v
+		data, err := ApplyKeyRewrites(providerData, es.Rewrite)
+		if err != nil {
+			return err
 		}
+		var rendered *RenderedSecret
+		if es.Template != nil {
+			if rendered, err = es.Template.Render(data); err != nil {
+				return err
+			}
+			data = rendered.Data
+		}
+		switch policy {
+		case CreatePolicyMerge:
+			// Shared Secret: remove keys we wrote last time and no longer
+			// want, never anyone else's (Pattern 33). Template labels and
+			// annotations are skipped: the Secret's metadata belongs to
+			// whoever created it.
+			if _, err := threeWayMergeData(secret, es, data); err != nil {
+				return err
+			}
+		default:
+			// Template labels and annotations go on after the owner labels;
+			// Render refused any under ControllerMetadataPrefix, so they
+			// can't overwrite them. Ones the template dropped are removed.
+			applyTemplateMetadata(secret, rendered)
 
-		// Set tracking labels used by other patterns:
-		//   - "managed" label: used by the layered cache (Pattern 09) to filter
-		//     which secrets are cached, and by refresh gating (Pattern 08) to
-		//     verify the secret was created by this controller.
+			// Owner and Orphan: we manage the whole Secret, so its data is
+			// exactly the desired map. Overlaying would keep every key a
+			// previous sync wrote — raw provider keys from before a template
+			// (Replace) was added, remote keys since deleted — and, on a
+			// takeover, the keys someone else left behind.
+			secret.Data = make(map[string][]byte, len(data))
+			for k, v := range data {
+				secret.Data[k] = v
+			}
+		}
+		if es.Immutable {
+			// Real code: externalsecret_controller.go (mutationFunc)
+			//   secret.Immutable = &externalSecret.Spec.Target.Immutable
+			secret.Immutable = true
+		}
+
+		// Tracking metadata used by other patterns:
+		//   - "managed" label (set above for Owner/Orphan): used by the layered
+		//     cache (Pattern 09) to filter which secrets are cached, and by
+		//     refresh gating (Pattern 08) to verify this controller created it.
 		//   - "data-hash" annotation: used by refresh gating (Pattern 08) to
 		//     detect tampering — if someone manually edits the secret, the hash
 		//     won't match and the reconciler will re-sync from the provider.
+		//     It's computed LAST, over the final data and controller-owned
+		//     metadata, in the versioned format from Pattern 29. A Merge
+		//     target gets one hash per ExternalSecret, over its own keys only.
 		// Real code: externalsecret_controller.go:529-530
-		secret.Labels["reconcile.external-secrets.io/managed"] = "true"
-		secret.Annotations["reconcile.external-secrets.io/data-hash"] = "abc123"
+		secret.Annotations[dataHashAnnotation(es)] = DataHash(hashedFieldsOf(secret, es))
 
 		return nil
 	}
@@ -111,11 +241,10 @@ func buildMutationFunc(es *ExternalSecret, providerData map[string][]byte) func(
 // =============================================================================
 
 // Real code: externalsecret_controller.go:874-902
-func createSecret(mutationFunc func(*Secret) error, name, namespace string) error {
+func createSecret(ctx context.Context, client KubeSecretClient, mutationFunc func(*Secret) error, name, namespace string) error {
 	// Start with a blank secret
 	newSecret := &Secret{
-		Name:      name,
-		Namespace: namespace,
+		ObjectMeta: ObjectMeta{Name: name, Namespace: namespace},
 	}
 
 	// Apply the mutation — same function used for update
@@ -124,22 +253,64 @@ func createSecret(mutationFunc func(*Secret) error, name, namespace string) erro
 	}
 
 	// Create in Kubernetes
-	fmt.Printf("CREATE secret %s/%s with data keys: %v\n", namespace, name, dataKeys(newSecret))
-	return nil
+	return client.CreateSecret(ctx, newSecret)
 }
 
 // Real code: externalsecret_controller.go:904-978
-func updateSecret(existingSecret *Secret, mutationFunc func(*Secret) error) error {
-	// Apply the mutation to a copy of the existing secret
+func updateSecret(ctx context.Context, client KubeSecretClient, existingSecret *Secret, mutationFunc func(*Secret) error) error {
+	// Apply the mutation to the existing secret (a fresh copy from Get).
 	// The same function handles owner refs, labels, data — everything
 	if err := mutationFunc(existingSecret); err != nil {
 		return err
 	}
 
-	// Update in Kubernetes
-	fmt.Printf("UPDATE secret %s/%s with data keys: %v\n",
-		existingSecret.Namespace, existingSecret.Name, dataKeys(existingSecret))
-	return nil
+	// Update in Kubernetes. The resourceVersion from Get makes this fail
+	// with ErrConflict if someone else wrote in between.
+	return client.UpdateSecret(ctx, existingSecret)
+}
+
+// applyCreationPolicy picks create, update or nothing for the target Secret.
+// Every error comes back as a *CreationPolicyError. Immutable targets
+// (es.Immutable) go through SyncImmutableSecret instead (Pattern 32): they
+// can't be updated in place, and which Secret is current lives in status.
+//
+// Real code: externalsecret_controller.go:535-573
+//
+//	switch externalSecret.Spec.Target.CreationPolicy {
+//	case esv1.CreatePolicyMerge:
+//	    // update only, error if missing
+//	case esv1.CreatePolicyNone:
+//	    // skip
+//	default:
+//	    // create or update
+//	}
+func applyCreationPolicy(ctx context.Context, client KubeSecretClient, es *ExternalSecret, targetName string, mutationFunc func(*Secret) error) error {
+	policy := creationPolicy(es)
+	wrap := func(err error) error {
+		if err == nil {
+			return nil
+		}
+		return &CreationPolicyError{Policy: policy, Namespace: es.Namespace, Name: targetName, Err: err}
+	}
+
+	switch policy {
+	case CreatePolicyNone:
+		return nil // status-only: the reconciler still reports Ready
+	case CreatePolicyOwner, CreatePolicyOrphan, CreatePolicyMerge:
+	default:
+		return wrap(fmt.Errorf("%w %q", ErrUnknownCreationPolicy, policy))
+	}
+
+	existing, err := client.GetSecret(ctx, es.Namespace, targetName)
+	switch {
+	case errors.Is(err, ErrNotFound) && policy == CreatePolicyMerge:
+		return wrap(ErrMergeTargetNotFound)
+	case errors.Is(err, ErrNotFound):
+		return wrap(createSecret(ctx, client, mutationFunc, targetName, es.Namespace))
+	case err != nil:
+		return wrap(err)
+	}
+	return wrap(updateSecret(ctx, client, existing, mutationFunc))
 }
 
 // =============================================================================
@@ -149,9 +320,13 @@ func updateSecret(existingSecret *Secret, mutationFunc func(*Secret) error) erro
 // Real code: externalsecret_controller.go:535-573
 
 func ExampleReconcilerUsage2() {
+	ctx := context.Background()
+	client := NewAPIServerSecretClient(NewFakeAPIServer())
+
 	es := &ExternalSecret{
 		Name:           "my-es",
 		Namespace:      "default",
+		UID:            "uid-my-es",
 		CreationPolicy: "Owner",
 	}
 
@@ -164,15 +339,14 @@ func ExampleReconcilerUsage2() {
 	// Build the mutation function ONCE
 	mutationFunc := buildMutationFunc(es, providerData)
 
-	// Branch: does the target secret exist?
-	existingSecret := findSecret("my-secret", "default")
-
-	if existingSecret == nil {
-		// Secret doesn't exist → create it
-		createSecret(mutationFunc, "my-secret", "default")
-	} else {
-		// Secret exists → update it
-		updateSecret(existingSecret, mutationFunc)
+	// First reconcile: the secret doesn't exist → create path.
+	// Second reconcile: it does → update path.
+	for i := 0; i < 2; i++ {
+		if err := applyCreationPolicy(ctx, client, es, "my-secret", mutationFunc); err != nil {
+			fmt.Println("error:", err)
+		}
+		secret, _ := client.GetSecret(ctx, "default", "my-secret")
+		fmt.Printf("secret %s/%s rv=%s keys=%v\n", secret.Namespace, secret.Name, secret.ResourceVersion, dataKeys(secret))
 	}
 
 	// BOTH paths use the SAME mutationFunc.
@@ -181,16 +355,12 @@ func ExampleReconcilerUsage2() {
 
 // --- helpers ---
 
-func findSecret(name, namespace string) *Secret {
-	// Simulate: secret doesn't exist
-	return nil
-}
-
 func dataKeys(s *Secret) []string {
 	keys := make([]string, 0, len(s.Data))
 	for k := range s.Data {
 		keys = append(keys, k)
 	}
+	sort.Strings(keys)
 	return keys
 }
 
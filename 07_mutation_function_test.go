@@ -0,0 +1,150 @@
+package guide
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestOwnerTakeoverDropsForeignKeys(t *testing.T) {
+	ctx := context.Background()
+	client := NewAPIServerSecretClient(NewFakeAPIServer())
+	client.CreateSecret(ctx, &Secret{
+		ObjectMeta: ObjectMeta{Name: "app", Namespace: "default"},
+		Data:       map[string][]byte{"left-behind": []byte("x")},
+	})
+	es := &ExternalSecret{Name: "my-es", Namespace: "default", UID: "uid-my-es", CreationPolicy: CreatePolicyOwner}
+
+	providerData := map[string][]byte{"password": []byte("s3cret")}
+	if err := applyCreationPolicy(ctx, client, es, "app", buildMutationFunc(es, providerData)); err != nil {
+		t.Fatal(err)
+	}
+	secret, _ := client.GetSecret(ctx, "default", "app")
+	if got := dataKeys(secret); !slices.Equal(got, []string{"password"}) {
+		t.Fatalf("keys after takeover = %v, want [password]", got)
+	}
+	providerData["password"] = []byte("rotated")
+	if string(secret.Data["password"]) != "s3cret" {
+		t.Fatal("the Secret aliases the provider map")
+	}
+}
+
+// Switching Owner → Orphan must leave a Secret that survives both the
+// ExternalSecret's deletion and a later target rename.
+func TestOwnerToOrphanReleasesTheSecret(t *testing.T) {
+	ctx := context.Background()
+	server := NewFakeAPIServer()
+	client := NewAPIServerSecretClient(server)
+	esObj := &Unstructured{Kind: "ExternalSecret", ObjectMeta: ObjectMeta{Name: "my-es", Namespace: "default"}}
+	server.Create(ctx, esObj)
+	es := &ExternalSecret{Name: "my-es", Namespace: "default", UID: esObj.UID, CreationPolicy: CreatePolicyOwner}
+	data := map[string][]byte{"k": []byte("v")}
+
+	if err := applyCreationPolicy(ctx, client, es, "target", buildMutationFunc(es, data)); err != nil {
+		t.Fatal(err)
+	}
+	// Someone else also holds a (non-controller) reference; it must survive.
+	bundle := &Unstructured{Kind: "ConfigMap", ObjectMeta: ObjectMeta{Name: "bundle", Namespace: "default"}}
+	server.Create(ctx, bundle)
+	secret, _ := client.GetSecret(ctx, "default", "target")
+	secret.OwnerReferences = append(secret.OwnerReferences, OwnerReference{Kind: "ConfigMap", Name: "bundle", UID: bundle.UID})
+	client.UpdateSecret(ctx, secret)
+
+	es.CreationPolicy = CreatePolicyOrphan
+	if err := applyCreationPolicy(ctx, client, es, "target", buildMutationFunc(es, data)); err != nil {
+		t.Fatal(err)
+	}
+	secret, _ = client.GetSecret(ctx, "default", "target")
+	if len(secret.OwnerReferences) != 1 || secret.OwnerReferences[0].UID != bundle.UID {
+		t.Fatalf("ownerReferences = %+v, want only the foreign one", secret.OwnerReferences)
+	}
+	if _, ok := secret.Labels[LabelOwner]; ok {
+		t.Fatal("owner label kept: orphan detection would delete the Secret after a rename")
+	}
+	if secret.Labels[LabelManaged] != "true" {
+		t.Fatal("managed label missing")
+	}
+
+	if deleted, _ := NewOwnershipManager(client).DeleteOrphans(ctx, es, "renamed"); len(deleted) != 0 {
+		t.Fatalf("orphan detection deleted %v", deleted)
+	}
+	gc := NewGarbageCollector(server)
+	gc.Delete(ctx, esObj, DeletePropagationBackground)
+	gc.Sweep(ctx)
+	if _, err := client.GetSecret(ctx, "default", "target"); err != nil {
+		t.Fatalf("Orphan Secret collected with its ExternalSecret: %v", err)
+	}
+}
+
+func TestSecretIsOwnedNamesTheControllerKind(t *testing.T) {
+	ctx := context.Background()
+	client := NewAPIServerSecretClient(NewFakeAPIServer())
+	client.CreateSecret(ctx, &Secret{ObjectMeta: ObjectMeta{Name: "tls", Namespace: "default",
+		OwnerReferences: []OwnerReference{{Kind: "Certificate", Name: "web", UID: "uid-cert", Controller: true}}}})
+	es := &ExternalSecret{Name: "my-es", Namespace: "default", UID: "uid-my-es"}
+
+	err := applyCreationPolicy(ctx, client, es, "tls", buildMutationFunc(es, map[string][]byte{"k": []byte("v")}))
+	if !errors.Is(err, ErrSecretIsOwned) {
+		t.Fatalf("err = %v, want ErrSecretIsOwned", err)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "Certificate web") || strings.Contains(msg, "ExternalSecret") {
+		t.Fatalf("error %q should name the Certificate controller, not an ExternalSecret", msg)
+	}
+}
+
+// Orphan and Merge never take the controller reference, but they must still
+// refuse a Secret another ExternalSecret controls — before writing anything.
+func TestOrphanAndMergeRefuseASecretAnotherControllerOwns(t *testing.T) {
+	for _, policy := range []string{CreatePolicyOrphan, CreatePolicyMerge} {
+		t.Run(policy, func(t *testing.T) {
+			ctx := context.Background()
+			client := NewAPIServerSecretClient(NewFakeAPIServer())
+			owner := &ExternalSecret{Name: "db", Namespace: "default", UID: "uid-db", CreationPolicy: CreatePolicyOwner}
+			if err := applyCreationPolicy(ctx, client, owner, "shared", buildMutationFunc(owner, map[string][]byte{"password": []byte("owned")})); err != nil {
+				t.Fatal(err)
+			}
+			before, _ := client.GetSecret(ctx, "default", "shared")
+
+			es := &ExternalSecret{Name: "intruder", Namespace: "default", UID: "uid-intruder", CreationPolicy: policy}
+			err := applyCreationPolicy(ctx, client, es, "shared", buildMutationFunc(es, map[string][]byte{"password": []byte("mine")}))
+			if !errors.Is(err, ErrSecretIsOwned) {
+				t.Fatalf("err = %v, want ErrSecretIsOwned", err)
+			}
+			var policyErr *CreationPolicyError
+			if !errors.As(err, &policyErr) || policyErr.Policy != policy {
+				t.Fatalf("err = %v, want a CreationPolicyError for %s", err, policy)
+			}
+			if msg := err.Error(); !strings.Contains(msg, "ExternalSecret db") {
+				t.Fatalf("error %q should name the controlling ExternalSecret", msg)
+			}
+			after, _ := client.GetSecret(ctx, "default", "shared")
+			if after.ResourceVersion != before.ResourceVersion || string(after.Data["password"]) != "owned" {
+				t.Fatalf("Secret was written: rv %s → %s, password=%q", before.ResourceVersion, after.ResourceVersion, after.Data["password"])
+			}
+		})
+	}
+}
+
+// A Secret with no controller, or controlled by this ExternalSecret, is
+// still writable under Orphan and Merge.
+func TestOrphanAndMergeWriteUncontrolledSecrets(t *testing.T) {
+	for _, policy := range []string{CreatePolicyOrphan, CreatePolicyMerge} {
+		t.Run(policy, func(t *testing.T) {
+			ctx := context.Background()
+			client := NewAPIServerSecretClient(NewFakeAPIServer())
+			client.CreateSecret(ctx, &Secret{ObjectMeta: ObjectMeta{Name: "shared", Namespace: "default",
+				OwnerReferences: []OwnerReference{{Kind: "ConfigMap", Name: "bundle", UID: "uid-bundle"}}}}) // not a controller
+			es := &ExternalSecret{Name: "mine", Namespace: "default", UID: "uid-mine", CreationPolicy: policy}
+
+			if err := applyCreationPolicy(ctx, client, es, "shared", buildMutationFunc(es, map[string][]byte{"password": []byte("mine")})); err != nil {
+				t.Fatal(err)
+			}
+			secret, _ := client.GetSecret(ctx, "default", "shared")
+			if string(secret.Data["password"]) != "mine" {
+				t.Fatalf("password = %q, want mine", secret.Data["password"])
+			}
+		})
+	}
+}
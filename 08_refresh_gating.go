@@ -40,16 +40,11 @@ type ExternalSecretStatus struct {
 
 type ExternalSecretSpec struct {
 	RefreshInterval time.Duration
-	RefreshPolicy   string // "Periodic", "OnChange", "CreatedOnce"
+	RefreshPolicy   string // "Periodic", "OnChange", "CreatedOnce", "Schedule"
+	RefreshSchedule string // five-field cron expression, used by "Schedule" (Pattern 26)
 	Generation      int64  // incremented by Kubernetes on every spec change
 }
 
-type SecretState struct {
-	Exists          bool
-	HasManagedLabel bool
-	DataHash        string // hash of the secret's data
-}
-
 // shouldRefresh determines if we need to call the external provider.
 //
 // Real code: externalsecret_controller.go:1103-1149
@@ -58,7 +53,10 @@ type SecretState struct {
 // The `currentGeneration` is Kubernetes' built-in counter that increments every time
 // the .spec is modified — it's free metadata that lets us detect spec changes without
 // deep-comparing the entire spec.
-func shouldRefresh(spec ExternalSecretSpec, status ExternalSecretStatus, currentGeneration int64) bool {
+//
+// Time comes from the injected Clock (Pattern 25), so the interval check can
+// be simulated without waiting.
+func shouldRefresh(clock Clock, spec ExternalSecretSpec, status ExternalSecretStatus, currentGeneration int64) bool {
 	switch spec.RefreshPolicy {
 
 	// "CreatedOnce" — only fetch once, ever.
@@ -81,16 +79,21 @@ func shouldRefresh(spec ExternalSecretSpec, status ExternalSecretStatus, current
 		}
 		return status.SyncedResourceVersion != fmt.Sprint(currentGeneration)
 
+	// "Schedule" — fetch at cron firings (e.g. "0 2 * * SUN") AND when spec changes.
+	// Useful when rotation happens on a business calendar rather than an interval.
+	case "Schedule":
+		return shouldRefreshScheduled(clock, spec, status, currentGeneration)
+
 	// "Periodic" (default) — fetch on a timer AND when spec changes.
 	default:
-		return shouldRefreshPeriodic(spec, status, currentGeneration)
+		return shouldRefreshPeriodic(clock, spec, status, currentGeneration)
 	}
 }
 
 // shouldRefreshPeriodic checks if the refresh interval has elapsed.
 //
 // Real code: externalsecret_controller.go:1126-1149
-func shouldRefreshPeriodic(spec ExternalSecretSpec, status ExternalSecretStatus, currentGeneration int64) bool {
+func shouldRefreshPeriodic(clock Clock, spec ExternalSecretSpec, status ExternalSecretStatus, currentGeneration int64) bool {
 	// If refresh interval is 0 and we've synced before, never refresh again
 	if spec.RefreshInterval <= 0 && status.SyncedResourceVersion != "" {
 		return false
@@ -107,7 +110,56 @@ func shouldRefreshPeriodic(spec ExternalSecretSpec, status ExternalSecretStatus,
 	}
 
 	// If the refresh interval has elapsed, refresh
-	return time.Since(status.RefreshTime) >= spec.RefreshInterval
+	return clock.Since(status.RefreshTime) >= spec.RefreshInterval
+}
+
+// shouldRefreshScheduled checks whether a cron firing has happened since the
+// last refresh. Comparing against RefreshTime (not "is it firing right now?")
+// means a firing missed while the controller was down is caught up on start.
+//
+// An unparsable schedule never fires; the webhook (advanced Pattern 11) is
+// what rejects it, so here it only degrades to OnChange behavior.
+func shouldRefreshScheduled(clock Clock, spec ExternalSecretSpec, status ExternalSecretStatus, currentGeneration int64) bool {
+	if status.SyncedResourceVersion != fmt.Sprint(currentGeneration) || status.RefreshTime.IsZero() {
+		return true
+	}
+	sched, err := ParseCron(spec.RefreshSchedule)
+	if err != nil {
+		return false
+	}
+	next := sched.Next(status.RefreshTime)
+	return !next.IsZero() && !next.After(clock.Now())
+}
+
+// refreshRequeueAfter is the RequeueAfter returned when gating skips the
+// provider call: the time remaining until the next refresh is due.
+// Zero means "don't requeue" (CreatedOnce, OnChange, interval 0).
+// RefreshJitter (Pattern 27) adds a per-object offset on top.
+//
+// Real code: externalsecret_controller.go (getRequeueResult)
+func refreshRequeueAfter(clock Clock, spec ExternalSecretSpec, status ExternalSecretStatus) time.Duration {
+	switch spec.RefreshPolicy {
+	case "CreatedOnce", "OnChange":
+		return 0
+	case "Schedule":
+		sched, err := ParseCron(spec.RefreshSchedule)
+		if err != nil {
+			return 0
+		}
+		next := sched.Next(clock.Now())
+		if next.IsZero() {
+			return 0
+		}
+		return next.Sub(clock.Now())
+	default:
+		if spec.RefreshInterval <= 0 {
+			return 0
+		}
+		if remaining := spec.RefreshInterval - clock.Since(status.RefreshTime); remaining > 0 {
+			return remaining
+		}
+		return spec.RefreshInterval
+	}
 }
 
 // isSecretValid is the second gating check. Even if shouldRefresh says "no refresh
@@ -118,25 +170,24 @@ func shouldRefreshPeriodic(spec ExternalSecretSpec, status ExternalSecretStatus,
 //   - The secret was created by a different process and lacks the "managed" label
 //
 // Real code: externalsecret_controller.go:1152-1174
-func isSecretValidCheck(secret SecretState, expectedDataHash string) bool {
+func isTargetSecretValid(secret *Secret, es *ExternalSecret) bool {
 	// Secret must exist
-	if !secret.Exists {
-		return false
-	}
-
-	// Must have the "managed" label (proves we created it)
-	if !secret.HasManagedLabel {
+	if secret == nil {
 		return false
 	}
 
-	// Data hash must match (proves the data hasn't been tampered with)
-	// If someone manually edited the secret, the hash won't match,
-	// and we'll re-sync from the provider.
-	if secret.DataHash != expectedDataHash {
+	// Must have the "managed" label (proves we created it). Merge targets
+	// belong to someone else and never carry it.
+	if creationPolicy(es) != CreatePolicyMerge && secret.Labels[LabelManaged] != "true" {
 		return false
 	}
 
-	return true
+	// Data hash must match (proves the data hasn't been tampered with).
+	// It's recomputed from the live Secret (Pattern 29), so a manual edit,
+	// a deleted key or a removed controller label all fail here and we
+	// re-sync from the provider.
+	ok, err := VerifyDataHash(secret.Annotations[dataHashAnnotation(es)], hashedFieldsOf(secret, es))
+	return ok && err == nil
 }
 
 // =============================================================================
@@ -144,28 +195,29 @@ func isSecretValidCheck(secret SecretState, expectedDataHash string) bool {
 // =============================================================================
 
 func ExampleRefreshGating() {
+	clock := NewFakeClock(time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC))
+
 	spec := ExternalSecretSpec{
 		RefreshInterval: 1 * time.Hour,
 		RefreshPolicy:   "Periodic",
 		Generation:      3,
 	}
 	status := ExternalSecretStatus{
-		SyncedResourceVersion: "3",            // matches current generation
-		RefreshTime:           time.Now().Add(-30 * time.Minute), // 30 min ago
-	}
-	secret := SecretState{
-		Exists:          true,
-		HasManagedLabel: true,
-		DataHash:        "abc123",
+		SyncedResourceVersion: "3",                                // matches current generation
+		RefreshTime:           clock.Now().Add(-30 * time.Minute), // 30 min ago
 	}
+	// The Secret as the last sync wrote it (Pattern 07), hash annotation included.
+	es := &ExternalSecret{Name: "my-es", Namespace: "default", UID: "uid-my-es"}
+	secret := &Secret{ObjectMeta: ObjectMeta{Name: "my-secret", Namespace: "default"}}
+	buildMutationFunc(es, map[string][]byte{"password": []byte("s3cret")})(secret)
 
 	// Check 1: Should we refresh?
-	refresh := shouldRefresh(spec, status, spec.Generation)
+	refresh := shouldRefresh(clock, spec, status, spec.Generation)
 	fmt.Println("shouldRefresh:", refresh)
 	// false — generation matches, refresh interval (1h) not elapsed (only 30min ago)
 
 	// Check 2: Is the secret valid?
-	valid := isSecretValidCheck(secret, "abc123")
+	valid := isTargetSecretValid(secret, es)
 	fmt.Println("isSecretValid:", valid)
 	// true — exists, has label, hash matches
 
@@ -173,22 +225,22 @@ func ExampleRefreshGating() {
 	// Reconcile() returns immediately with RequeueAfter: 30min (remaining time)
 	if !refresh && valid {
 		fmt.Println("SKIPPED: no provider call needed")
-		fmt.Println("Requeue after:", spec.RefreshInterval-time.Since(status.RefreshTime))
+		fmt.Println("Requeue after:", refreshRequeueAfter(clock, spec, status))
 	}
 
 	// ==========================================================
 
 	// Now simulate: someone manually edited the secret's data
-	secret.DataHash = "xyz789" // hash no longer matches
+	secret.Data["password"] = []byte("hunter2") // recomputed hash no longer matches
 
-	valid2 := isSecretValidCheck(secret, "abc123")
+	valid2 := isTargetSecretValid(secret, es)
 	fmt.Println("\nAfter manual edit:")
 	fmt.Println("isSecretValid:", valid2)
 	// false — hash mismatch! Must re-sync from provider.
 
 	// Now simulate: spec.generation changed (user updated ExternalSecret)
 	status.SyncedResourceVersion = "2" // doesn't match generation 3
-	refresh2 := shouldRefresh(spec, status, spec.Generation)
+	refresh2 := shouldRefresh(clock, spec, status, spec.Generation)
 	fmt.Println("\nAfter spec change:")
 	fmt.Println("shouldRefresh:", refresh2)
 	// true — generation mismatch, must refresh
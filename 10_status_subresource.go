@@ -55,6 +55,10 @@ type ESStatus struct {
 
 	// Which K8s Secret is this ExternalSecret managing?
 	Binding string // e.g., "my-secret"
+
+	// For an immutable target: which generation Binding points at. Each
+	// replace creates a new Secret and bumps it (Pattern 32).
+	ImmutableGeneration int64
 }
 
 // Condition follows the standard Kubernetes condition convention (KEP-1623).
@@ -91,7 +95,9 @@ type Condition struct {
 //       }
 //   }()
 
-type StatusReconciler struct{}
+type StatusReconciler struct {
+	Clock Clock // nil = RealClock; a FakeClock makes condition timestamps deterministic
+}
 
 func (r *StatusReconciler) Reconcile(ctx context.Context, name, namespace string) (err error) {
 	status := &ESStatus{}
@@ -128,19 +134,19 @@ func (r *StatusReconciler) Reconcile(ctx context.Context, name, namespace string
 	providerData, providerErr := callProvider(ctx)
 	if providerErr != nil {
 		// Mark as failed — the deferred function will persist this
-		markFailed(status, "could not get secret data from provider", providerErr)
+		markFailed(r.clock(), status, "could not get secret data from provider", providerErr)
 		return providerErr // deferred func runs, status is updated
 	}
 
 	// Scenario 2: Secret update fails
 	updateErr := updateTargetSecret(ctx, providerData)
 	if updateErr != nil {
-		markFailed(status, "could not update secret", updateErr)
+		markFailed(r.clock(), status, "could not update secret", updateErr)
 		return updateErr // deferred func runs, status is updated
 	}
 
 	// Scenario 3: Success
-	markDone(status, name)
+	markDone(r.clock(), status, name)
 	return nil // deferred func runs, status is updated
 }
 
@@ -150,27 +156,29 @@ func (r *StatusReconciler) Reconcile(ctx context.Context, name, namespace string
 //
 // Real code: externalsecret_controller.go:777-802
 
-func markDone(status *ESStatus, secretName string) {
+func (r *StatusReconciler) clock() Clock { return orRealClock(r.Clock) }
+
+func markDone(clock Clock, status *ESStatus, secretName string) {
 	// Real code: externalsecret_controller.go:777-795
 	status.Conditions = []Condition{{
 		Type:               "Ready",
 		Status:             "True",
 		Reason:             "SecretSynced",
 		Message:            "secret synced",
-		LastTransitionTime: time.Now(),
+		LastTransitionTime: clock.Now(),
 	}}
-	status.RefreshTime = time.Now()
+	status.RefreshTime = clock.Now()
 	status.Binding = secretName
 }
 
-func markFailed(status *ESStatus, msg string, err error) {
+func markFailed(clock Clock, status *ESStatus, msg string, err error) {
 	// Real code: externalsecret_controller.go:797-802
 	status.Conditions = []Condition{{
 		Type:               "Ready",
 		Status:             "False",
 		Reason:             "SecretSyncedError",
 		Message:            fmt.Sprintf("%s: %v", msg, err),
-		LastTransitionTime: time.Now(),
+		LastTransitionTime: clock.Now(),
 	}}
 }
 
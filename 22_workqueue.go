@@ -0,0 +1,339 @@
+// Pattern 22: A Real In-Process Workqueue
+//
+// Pattern 04 describes what the workqueue does: deduplicate keys, rate limit
+// failures, and delay periodic requeues. This file implements it, so the
+// documented behavior can be exercised end-to-end instead of read as printed
+// strings.
+//
+// THE THREE DATA STRUCTURES:
+//   queue      — FIFO order of keys waiting for a worker
+//   dirty      — every key that needs processing (queued OR re-added while in flight)
+//   processing — keys currently handed out by Get() and not yet marked Done()
+//
+// The interplay between dirty and processing is what makes deduplication work:
+//   - Add(key) while key is dirty          → no-op (already scheduled)
+//   - Add(key) while key is processing     → marked dirty, NOT queued
+//   - Done(key) while key is dirty         → queued again, exactly once
+//
+// So a key enqueued ten times while a worker is reconciling it collapses into
+// a single follow-up reconcile, and no two workers ever hold the same key.
+//
+// REAL CODE REFERENCE:
+//   client-go/util/workqueue/queue.go                (Add, Get, Done, ShutDown)
+//   client-go/util/workqueue/delaying_queue.go       (AddAfter)
+//   client-go/util/workqueue/rate_limiting_queue.go  (AddRateLimited, Forget)
+//   client-go/util/workqueue/default_rate_limiters.go
+
+package guide
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// Rate Limiter
+// =============================================================================
+//
+// The queue doesn't decide HOW LONG to back off — it delegates to a RateLimiter.
+// This is the same split as client-go: the queue owns ordering and dedup, the
+// limiter owns retry policy. Pattern 15 shows how to compose limiters.
+//
+// Real code: client-go/util/workqueue/default_rate_limiters.go:27-35
+
+type RateLimiter[T comparable] interface {
+	// When returns how long to wait before the item should be processed again.
+	// Every call counts as one more failure for the item.
+	When(item T) time.Duration
+	// Forget stops tracking the item, resetting its backoff to the base delay.
+	Forget(item T)
+	// NumRequeues returns how many times the item has been rate limited.
+	NumRequeues(item T) int
+}
+
+// ItemExponentialFailureRateLimiter backs off per item: baseDelay * 2^failures,
+// capped at maxDelay. This is the per-item half of controller-runtime's default
+// limiter (5ms base, 1000s max — see Pattern 04 CASE 2).
+//
+// Real code: client-go/util/workqueue/default_rate_limiters.go:73-127
+type ItemExponentialFailureRateLimiter[T comparable] struct {
+	mu       sync.Mutex
+	failures map[T]int
+
+	baseDelay time.Duration
+	maxDelay  time.Duration
+}
+
+func NewItemExponentialFailureRateLimiter[T comparable](baseDelay, maxDelay time.Duration) *ItemExponentialFailureRateLimiter[T] {
+	return &ItemExponentialFailureRateLimiter[T]{
+		failures:  make(map[T]int),
+		baseDelay: baseDelay,
+		maxDelay:  maxDelay,
+	}
+}
+
+func (r *ItemExponentialFailureRateLimiter[T]) When(item T) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	exp := r.failures[item]
+	r.failures[item]++
+
+	// Compute in float64 so a large failure count saturates at maxDelay
+	// instead of overflowing time.Duration.
+	backoff := float64(r.baseDelay) * math.Pow(2, float64(exp))
+	if backoff > float64(r.maxDelay) {
+		return r.maxDelay
+	}
+	return time.Duration(backoff)
+}
+
+func (r *ItemExponentialFailureRateLimiter[T]) Forget(item T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.failures, item)
+}
+
+func (r *ItemExponentialFailureRateLimiter[T]) NumRequeues(item T) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.failures[item]
+}
+
+// =============================================================================
+// The Workqueue
+// =============================================================================
+
+// Workqueue is a deduplicating, delaying, rate-limited FIFO of keys.
+// T is usually a "namespace/name" string or a NamespacedName-like struct.
+type Workqueue[T comparable] struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	// queue holds keys in the order they should be handed to workers.
+	// Every key in queue is also in dirty.
+	queue []T
+
+	// dirty holds every key that needs processing. A key can be dirty
+	// without being in queue: that means it was re-added while in flight
+	// and will be queued again when Done() is called.
+	dirty map[T]struct{}
+
+	// processing holds keys handed out by Get() and not yet marked Done().
+	processing map[T]struct{}
+
+	// waiting holds the earliest ready time of each key added via AddAfter.
+	// A later AddAfter for the same key never postpones an earlier one.
+	waiting map[T]time.Time
+
+	rateLimiter  RateLimiter[T]
+	clock        Clock
+	shuttingDown bool
+}
+
+// NewWorkqueue creates a queue backed by the given rate limiter.
+// A nil limiter falls back to the controller-runtime default of 5ms..1000s.
+//
+// Real code: client-go/util/workqueue/rate_limiting_queue.go:59-75
+func NewWorkqueue[T comparable](rateLimiter RateLimiter[T]) *Workqueue[T] {
+	return NewWorkqueueWithClock(rateLimiter, RealClock{})
+}
+
+// NewWorkqueueWithClock is NewWorkqueue with an injected clock (Pattern 25).
+// With a FakeClock, AddAfter items become ready when the clock is stepped.
+//
+// Real code: client-go/util/workqueue/delaying_queue.go:75-90
+func NewWorkqueueWithClock[T comparable](rateLimiter RateLimiter[T], clock Clock) *Workqueue[T] {
+	if rateLimiter == nil {
+		rateLimiter = NewItemExponentialFailureRateLimiter[T](5*time.Millisecond, 1000*time.Second)
+	}
+	q := &Workqueue[T]{
+		dirty:       make(map[T]struct{}),
+		processing:  make(map[T]struct{}),
+		waiting:     make(map[T]time.Time),
+		rateLimiter: rateLimiter,
+		clock:       orRealClock(clock),
+	}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// Add marks the key as needing processing.
+//
+// Real code: client-go/util/workqueue/queue.go:219-240
+func (q *Workqueue[T]) Add(item T) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.add(item)
+}
+
+func (q *Workqueue[T]) add(item T) {
+	if q.shuttingDown {
+		return
+	}
+	// Already scheduled — this is the deduplication from Pattern 04.
+	if _, ok := q.dirty[item]; ok {
+		return
+	}
+	q.dirty[item] = struct{}{}
+
+	// A worker is reconciling this key right now. Don't queue it (that would
+	// let a second worker pick it up concurrently); Done() will queue it.
+	if _, ok := q.processing[item]; ok {
+		return
+	}
+	q.queue = append(q.queue, item)
+	q.cond.Signal()
+}
+
+// Get blocks until a key is available or the queue is shut down.
+// The caller MUST call Done(item) when finished processing it.
+// shutdown=true means the queue is shut down AND empty; the worker should exit.
+//
+// Real code: client-go/util/workqueue/queue.go:254-277
+func (q *Workqueue[T]) Get() (item T, shutdown bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.queue) == 0 && !q.shuttingDown {
+		q.cond.Wait()
+	}
+	if len(q.queue) == 0 {
+		return item, true
+	}
+
+	item = q.queue[0]
+	var zero T
+	q.queue[0] = zero // don't pin the key in the backing array
+	q.queue = q.queue[1:]
+
+	q.processing[item] = struct{}{}
+	delete(q.dirty, item)
+	return item, false
+}
+
+// Done marks the key as no longer in flight. If it was re-added while being
+// processed, it is queued again now — exactly once, however many times it
+// was added.
+//
+// Real code: client-go/util/workqueue/queue.go:282-296
+func (q *Workqueue[T]) Done(item T) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.processing, item)
+	if _, ok := q.dirty[item]; ok {
+		q.queue = append(q.queue, item)
+		q.cond.Signal()
+	}
+}
+
+// AddAfter adds the key once the delay has elapsed. This is what backs
+// ReconcileResult.RequeueAfter: "call me again in 1 hour".
+//
+// Real code: client-go/util/workqueue/delaying_queue.go:242-268
+func (q *Workqueue[T]) AddAfter(item T, delay time.Duration) {
+	if delay <= 0 {
+		q.Add(item)
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.shuttingDown {
+		return
+	}
+
+	// Keep only the earliest deadline per key. If a key is already waiting
+	// to fire sooner, this call is redundant.
+	readyAt := q.clock.Now().Add(delay)
+	if existing, ok := q.waiting[item]; ok && !readyAt.Before(existing) {
+		return
+	}
+	q.waiting[item] = readyAt
+
+	q.clock.AfterFunc(delay, func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		// A later AddAfter with an earlier deadline replaced this entry;
+		// its own timer owns the add.
+		if !q.waiting[item].Equal(readyAt) {
+			return
+		}
+		delete(q.waiting, item)
+		q.add(item)
+	})
+}
+
+// AddRateLimited adds the key after the rate limiter says it's OK.
+// Each call counts as a failure, so repeated calls back off exponentially.
+//
+// Real code: client-go/util/workqueue/rate_limiting_queue.go:105-108
+func (q *Workqueue[T]) AddRateLimited(item T) {
+	q.AddAfter(item, q.rateLimiter.When(item))
+}
+
+// Forget tells the rate limiter the key succeeded, resetting its backoff.
+// It does NOT remove the key from the queue.
+func (q *Workqueue[T]) Forget(item T) {
+	q.rateLimiter.Forget(item)
+}
+
+// NumRequeues returns how many times the key has been rate limited since
+// it was last forgotten.
+func (q *Workqueue[T]) NumRequeues(item T) int {
+	return q.rateLimiter.NumRequeues(item)
+}
+
+// Len returns the number of keys waiting for a worker. Keys in flight or
+// waiting on a delay are not counted.
+func (q *Workqueue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.queue)
+}
+
+// ShutDown stops accepting new keys and wakes all blocked Get() calls.
+// Keys already queued are still handed out; Get() reports shutdown once
+// the queue drains.
+//
+// Real code: client-go/util/workqueue/queue.go:300-330
+func (q *Workqueue[T]) ShutDown() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.shuttingDown = true
+	q.cond.Broadcast()
+}
+
+func (q *Workqueue[T]) ShuttingDown() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.shuttingDown
+}
+
+// =============================================================================
+// Example: Ten Adds While In Flight = One Follow-Up Reconcile
+// =============================================================================
+
+func ExampleWorkqueueDeduplication() {
+	q := NewWorkqueue[string](nil)
+
+	q.Add("default/my-es")
+	key, _ := q.Get() // worker 1 starts reconciling
+
+	// Ten watch events arrive while the reconcile is running.
+	for i := 0; i < 10; i++ {
+		q.Add("default/my-es")
+	}
+	fmt.Println("queued while in flight:", q.Len()) // 0 — not handed to another worker
+
+	q.Done(key)
+	fmt.Println("queued after Done:", q.Len()) // 1 — ten events collapsed into one
+}
+
+// KEY INSIGHT:
+// The workqueue never tracks WHY a key was added. It only tracks THAT it
+// needs processing. That's the level-triggered model (Pattern 01) applied
+// to scheduling: ten events and one event mean the same thing — "go look".
@@ -0,0 +1,233 @@
+package guide
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+var testEpoch = time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+func TestWorkqueueDeduplicatesQueuedKeys(t *testing.T) {
+	q := NewWorkqueue[string](nil)
+	for i := 0; i < 10; i++ {
+		q.Add("default/a")
+	}
+	q.Add("default/b")
+
+	if got := q.Len(); got != 2 {
+		t.Fatalf("Len() = %d, want 2", got)
+	}
+	if key, _ := q.Get(); key != "default/a" {
+		t.Fatalf("first Get() = %q, want FIFO order", key)
+	}
+}
+
+func TestWorkqueueAddWhileProcessingQueuesOnceOnDone(t *testing.T) {
+	q := NewWorkqueue[string](nil)
+	q.Add("default/my-es")
+	key, _ := q.Get()
+
+	for i := 0; i < 10; i++ {
+		q.Add(key)
+	}
+	if got := q.Len(); got != 0 {
+		t.Fatalf("Len() while in flight = %d, want 0: a second worker must not get the key", got)
+	}
+
+	q.Done(key)
+	if got := q.Len(); got != 1 {
+		t.Fatalf("Len() after Done = %d, want 1", got)
+	}
+	again, _ := q.Get()
+	q.Done(again)
+	if got := q.Len(); got != 0 {
+		t.Fatalf("Len() after second Done = %d, want 0", got)
+	}
+}
+
+func TestWorkqueueAddAfterKeepsEarliestDeadline(t *testing.T) {
+	clock := NewFakeClock(testEpoch)
+	q := NewWorkqueueWithClock[string](nil, clock)
+
+	q.AddAfter("k", time.Hour)
+	q.AddAfter("k", time.Minute) // earlier: replaces the hour
+	q.AddAfter("k", 2*time.Hour) // later: ignored
+
+	clock.Step(59 * time.Second)
+	if got := q.Len(); got != 0 {
+		t.Fatalf("Len() before the deadline = %d, want 0", got)
+	}
+	clock.Step(time.Second)
+	if got := q.Len(); got != 1 {
+		t.Fatalf("Len() at the deadline = %d, want 1", got)
+	}
+	key, _ := q.Get()
+	q.Done(key)
+
+	clock.Step(2 * time.Hour) // the superseded timers fire but must not re-add
+	if got := q.Len(); got != 0 {
+		t.Fatalf("Len() after superseded timers = %d, want 0", got)
+	}
+}
+
+func TestWorkqueueAddRateLimitedBacksOffAndForgetResets(t *testing.T) {
+	clock := NewFakeClock(testEpoch)
+	limiter := NewItemExponentialFailureRateLimiter[string](time.Second, 10*time.Second)
+	q := NewWorkqueueWithClock[string](limiter, clock)
+
+	wantDelays := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second}
+	for i, want := range wantDelays {
+		q.AddRateLimited("k")
+		clock.Step(want - time.Millisecond)
+		if q.Len() != 0 {
+			t.Fatalf("failure %d: ready before %v", i+1, want)
+		}
+		clock.Step(time.Millisecond)
+		if q.Len() != 1 {
+			t.Fatalf("failure %d: not ready after %v", i+1, want)
+		}
+		key, _ := q.Get()
+		q.Done(key)
+	}
+	if got := q.NumRequeues("k"); got != len(wantDelays) {
+		t.Fatalf("NumRequeues = %d, want %d", got, len(wantDelays))
+	}
+
+	q.Forget("k")
+	if got := q.NumRequeues("k"); got != 0 {
+		t.Fatalf("NumRequeues after Forget = %d, want 0", got)
+	}
+	if got := limiter.When("k"); got != time.Second {
+		t.Fatalf("first delay after Forget = %v, want the base delay", got)
+	}
+}
+
+func TestWorkqueueShutDownDrainsThenReportsShutdown(t *testing.T) {
+	q := NewWorkqueue[string](nil)
+	q.Add("a")
+	q.ShutDown()
+	q.Add("b") // ignored after shutdown
+
+	if key, shutdown := q.Get(); shutdown || key != "a" {
+		t.Fatalf("Get() = %q, %v; want the queued key first", key, shutdown)
+	}
+	if _, shutdown := q.Get(); !shutdown {
+		t.Fatal("Get() on a drained, shut-down queue did not report shutdown")
+	}
+}
+
+func TestWorkqueueShutDownWakesBlockedGet(t *testing.T) {
+	q := NewWorkqueue[string](nil)
+	done := make(chan bool)
+	go func() {
+		_, shutdown := q.Get()
+		done <- shutdown
+	}()
+	q.ShutDown()
+	select {
+	case shutdown := <-done:
+		if !shutdown {
+			t.Fatal("blocked Get() returned without shutdown")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ShutDown did not wake a blocked Get()")
+	}
+}
+
+// TestControllerTenEventsWhileInFlightCauseOneFollowUp runs Pattern 04's
+// claim end to end: a real controller, real workers, a key re-added ten
+// times while its reconcile is blocked, and exactly one follow-up reconcile.
+func TestControllerTenEventsWhileInFlightCauseOneFollowUp(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		calls    int
+		started  = make(chan struct{})
+		release  = make(chan struct{})
+		finished = make(chan struct{}, 10)
+	)
+	ctrl := NewController("test", ReconcilerFunc(func(ctx context.Context, req Request) (ReconcileResult, error) {
+		mu.Lock()
+		calls++
+		first := calls == 1
+		mu.Unlock()
+		if first {
+			close(started)
+			<-release
+		}
+		finished <- struct{}{}
+		return ReconcileResult{}, nil
+	}), ControllerOptions{MaxConcurrentReconciles: 4})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error)
+	go func() { stopped <- ctrl.Start(ctx) }()
+
+	req := Request{Namespace: "default", Name: "my-es"}
+	ctrl.Enqueue(req)
+	<-started
+	for i := 0; i < 10; i++ {
+		ctrl.Enqueue(req)
+	}
+	close(release)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-finished:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("only %d reconciles finished", i)
+		}
+	}
+	select {
+	case <-finished:
+		t.Fatal("a third reconcile ran: the ten adds were not collapsed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancel()
+	if err := <-stopped; err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("reconciles = %d, want 2", calls)
+	}
+}
+
+func TestControllerErrorRequeuesWithBackoff(t *testing.T) {
+	clock := NewFakeClock(testEpoch)
+	errTransient := errors.New("provider unavailable")
+	var attempts int
+	ctrl := NewController("test", ReconcilerFunc(func(ctx context.Context, req Request) (ReconcileResult, error) {
+		attempts++
+		if attempts < 3 {
+			return ReconcileResult{}, errTransient
+		}
+		return ReconcileResult{}, nil
+	}), ControllerOptions{
+		RateLimiter: NewItemExponentialFailureRateLimiter[Request](time.Second, time.Minute),
+		Clock:       clock,
+	})
+
+	ctx := context.Background()
+	req := Request{Namespace: "default", Name: "flaky"}
+	ctrl.Enqueue(req)
+
+	ctrl.processNextWorkItem(ctx) // fails, retry in 1s
+	clock.Step(time.Second)
+	ctrl.processNextWorkItem(ctx) // fails, retry in 2s
+	clock.Step(time.Second)
+	if ctrl.Queue.Len() != 0 {
+		t.Fatal("second retry became ready before its 2s backoff")
+	}
+	clock.Step(time.Second)
+	ctrl.processNextWorkItem(ctx) // succeeds, forgotten
+
+	if attempts != 3 {
+		t.Fatalf("attempts = %d, want 3", attempts)
+	}
+	if got := ctrl.Queue.NumRequeues(req); got != 0 {
+		t.Fatalf("NumRequeues after success = %d, want 0 (forgotten)", got)
+	}
+}
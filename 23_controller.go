@@ -0,0 +1,225 @@
+// Pattern 23: Controller Runtime (Worker Pool + Result Handling)
+//
+// Pattern 01 defines a Reconcile() method, Pattern 04 describes the worker loop
+// and the reconcileHandler switch, and Pattern 22 implements the queue. This
+// file is the glue: a Controller that owns N worker goroutines, pulls keys
+// from the queue, calls Reconcile(), and maps the result back onto the queue.
+//
+// THE CONTRACT:
+//   - A key is NEVER reconciled by two workers at once (the queue guarantees it)
+//   - Every Get() is paired with exactly one Done(), even if Reconcile() panics
+//   - The ReconcileResult/error decides what happens next:
+//       error             → AddRateLimited  (exponential backoff)
+//       RequeueAfter > 0  → Forget + AddAfter (reset backoff, periodic refresh)
+//       Requeue           → AddRateLimited  (retry soon, still rate limited)
+//       otherwise         → Forget          (success, clear failure count)
+//   - Cancelling the context stops new reconciles and waits for in-flight ones
+//
+// REAL CODE REFERENCE:
+//   controller-runtime/pkg/internal/controller/controller.go:259-313  (Start, worker loop)
+//   controller-runtime/pkg/internal/controller/controller.go:403-423  (processNextWorkItem)
+//   controller-runtime/pkg/internal/controller/controller.go:444-495  (reconcileHandler)
+
+package guide
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// =============================================================================
+// The Reconciler Interface
+// =============================================================================
+//
+// Request carries ONLY the key. No event type, no old/new object — that's
+// the level-triggered contract from Pattern 01.
+//
+// Real code: controller-runtime/pkg/reconcile/reconcile.go:93-102
+
+type Request struct {
+	Namespace string
+	Name      string
+}
+
+func (r Request) String() string {
+	return r.Namespace + "/" + r.Name
+}
+
+type Reconciler interface {
+	Reconcile(ctx context.Context, req Request) (ReconcileResult, error)
+}
+
+// ReconcilerFunc adapts a plain function to the Reconciler interface.
+// Real code: controller-runtime/pkg/reconcile/reconcile.go:111-117
+type ReconcilerFunc func(ctx context.Context, req Request) (ReconcileResult, error)
+
+func (f ReconcilerFunc) Reconcile(ctx context.Context, req Request) (ReconcileResult, error) {
+	return f(ctx, req)
+}
+
+// =============================================================================
+// The Controller
+// =============================================================================
+
+type ControllerOptions struct {
+	// MaxConcurrentReconciles is the number of worker goroutines (--concurrent).
+	// Defaults to 1.
+	MaxConcurrentReconciles int
+
+	// RateLimiter decides the backoff for AddRateLimited. Nil uses the
+	// controller-runtime default (see NewWorkqueue).
+	RateLimiter RateLimiter[Request]
+
+	// Clock drives RequeueAfter and backoff delays. Nil uses real time.
+	Clock Clock
+}
+
+type Controller struct {
+	Name       string
+	Reconciler Reconciler
+	Queue      *Workqueue[Request]
+
+	maxConcurrentReconciles int
+
+	mu      sync.Mutex
+	started bool
+}
+
+func NewController(name string, reconciler Reconciler, opts ControllerOptions) *Controller {
+	if opts.MaxConcurrentReconciles <= 0 {
+		opts.MaxConcurrentReconciles = 1
+	}
+	return &Controller{
+		Name:                    name,
+		Reconciler:              reconciler,
+		Queue:                   NewWorkqueueWithClock(opts.RateLimiter, opts.Clock),
+		maxConcurrentReconciles: opts.MaxConcurrentReconciles,
+	}
+}
+
+// Enqueue is what event handlers call. The queue deduplicates.
+func (c *Controller) Enqueue(req Request) {
+	c.Queue.Add(req)
+}
+
+// Start runs the workers and blocks until ctx is cancelled. On return, the
+// queue is shut down and every in-flight Reconcile() has finished.
+//
+// Real code: controller.go:259-313
+//
+//	for i := 0; i < c.MaxConcurrentReconciles; i++ {
+//	    go func() {
+//	        defer wg.Done()
+//	        for c.processNextWorkItem(ctx) {
+//	        }
+//	    }()
+//	}
+//	<-ctx.Done()
+//	wg.Wait()
+func (c *Controller) Start(ctx context.Context) error {
+	c.mu.Lock()
+	if c.started {
+		c.mu.Unlock()
+		return fmt.Errorf("controller %q was started more than once", c.Name)
+	}
+	c.started = true
+	c.mu.Unlock()
+
+	var wg sync.WaitGroup
+	wg.Add(c.maxConcurrentReconciles)
+	for i := 0; i < c.maxConcurrentReconciles; i++ {
+		go func() {
+			defer wg.Done()
+			for c.processNextWorkItem(ctx) {
+			}
+		}()
+	}
+
+	<-ctx.Done()
+
+	// ShutDown wakes every worker blocked in Get(). Workers that are mid-
+	// reconcile finish that item, then see shutdown on their next Get().
+	c.Queue.ShutDown()
+	wg.Wait()
+	return nil
+}
+
+// processNextWorkItem handles one key. Returns false when the worker should exit.
+//
+// Real code: controller.go:403-423
+func (c *Controller) processNextWorkItem(ctx context.Context) bool {
+	req, shutdown := c.Queue.Get()
+	if shutdown {
+		return false
+	}
+	// Done() MUST run even if Reconcile panics, otherwise the key is stuck
+	// in the processing set forever and can never be reconciled again.
+	defer c.Queue.Done(req)
+
+	// Keys still queued at shutdown are dropped: the next leader (or the next
+	// process start) will resync them. Level-triggered means nothing is lost.
+	if ctx.Err() != nil {
+		return false
+	}
+
+	c.reconcileHandler(ctx, req)
+	return true
+}
+
+// reconcileHandler maps the reconcile outcome onto the queue.
+//
+// Real code: controller.go:444-495
+func (c *Controller) reconcileHandler(ctx context.Context, req Request) {
+	result, err := c.reconcile(ctx, req)
+	switch {
+	case err != nil:
+		// Transient error — retry with exponential backoff.
+		c.Queue.AddRateLimited(req)
+	case result.RequeueAfter > 0:
+		// Success with a periodic refresh. Forget FIRST so the next failure
+		// starts backing off from the base delay, not from where it left off.
+		c.Queue.Forget(req)
+		c.Queue.AddAfter(req, result.RequeueAfter)
+	case result.Requeue:
+		c.Queue.AddRateLimited(req)
+	default:
+		// Success (or a permanent error reported via status) — stop tracking.
+		c.Queue.Forget(req)
+	}
+}
+
+// reconcile calls the user's Reconciler, converting a panic into an error so
+// one bad object can't take down the whole worker pool.
+//
+// Real code: controller.go:105-128 (RecoverPanic)
+func (c *Controller) reconcile(ctx context.Context, req Request) (result ReconcileResult, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Join(ErrReconcilePanic, fmt.Errorf("controller %q, request %s: %v", c.Name, req, r))
+		}
+	}()
+	return c.Reconciler.Reconcile(ctx, req)
+}
+
+// ErrReconcilePanic marks errors produced by a recovered Reconcile() panic.
+var ErrReconcilePanic = errors.New("panic in reconciler")
+
+// =============================================================================
+// Wiring the Level-Triggered Reconciler from Pattern 01
+// =============================================================================
+//
+// LevelTriggeredReconciler predates the Request type, so it's adapted with a
+// ReconcilerFunc. A nil error with no requeue means "in sync, forget the key".
+
+func NewLevelTriggeredController(r *LevelTriggeredReconciler, workers int) *Controller {
+	return NewController("externalsecret", ReconcilerFunc(func(ctx context.Context, req Request) (ReconcileResult, error) {
+		return ReconcileResult{}, r.Reconcile(ctx, req.Name, req.Namespace)
+	}), ControllerOptions{MaxConcurrentReconciles: workers})
+}
+
+// KEY INSIGHT:
+// The controller has no idea what it's reconciling. It only knows keys and
+// results. Everything resource-specific lives in Reconcile(); everything
+// about scheduling, concurrency and retries lives here and in the queue.
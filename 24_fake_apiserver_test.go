@@ -0,0 +1,73 @@
+package guide
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// unserializable fails to marshal whenever Field.Broken is set.
+type unserializable struct {
+	ObjectMeta
+	Field failingField
+}
+
+type failingField struct{ Broken bool }
+
+func (f failingField) MarshalJSON() ([]byte, error) {
+	if f.Broken {
+		return nil, errors.New("cannot encode")
+	}
+	return []byte("false"), nil
+}
+
+func (f *failingField) UnmarshalJSON(data []byte) error { return json.Unmarshal(data, &f.Broken) }
+
+func TestFakeAPIServerRejectsUnserializableObjects(t *testing.T) {
+	ctx := context.Background()
+	api := NewFakeAPIServer()
+
+	obj := &unserializable{ObjectMeta: ObjectMeta{Name: "x", Namespace: "default"}, Field: failingField{Broken: true}}
+	if err := api.Create(ctx, obj); !errors.Is(err, ErrNotSerializable) {
+		t.Fatalf("Create() = %v, want ErrNotSerializable", err)
+	}
+	if err := api.Get(ctx, "default", "x", &unserializable{}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("a rejected Create was stored: Get() = %v", err)
+	}
+
+	// Stored while encodable, then updated with a value that isn't.
+	if err := api.Create(ctx, &unserializable{ObjectMeta: ObjectMeta{Name: "y", Namespace: "default"}}); err != nil {
+		t.Fatalf("Create() = %v", err)
+	}
+	bad := &unserializable{ObjectMeta: ObjectMeta{Name: "y", Namespace: "default"}, Field: failingField{Broken: true}}
+	if err := api.Update(ctx, bad); !errors.Is(err, ErrNotSerializable) {
+		t.Fatalf("Update() = %v, want ErrNotSerializable", err)
+	}
+}
+
+func TestFakeAPIServerRejectsStaleUpdates(t *testing.T) {
+	ctx := context.Background()
+	api := NewFakeAPIServer()
+
+	secret := &Secret{ObjectMeta: ObjectMeta{Name: "s", Namespace: "default"}, Data: map[string][]byte{"k": []byte("v1")}}
+	if err := api.Create(ctx, secret); err != nil {
+		t.Fatal(err)
+	}
+	stale := *secret
+	stale.Data = map[string][]byte{"k": []byte("stale")}
+
+	secret.Data["k"] = []byte("v2")
+	if err := api.Update(ctx, secret); err != nil {
+		t.Fatalf("Update() = %v", err)
+	}
+	if err := api.Update(ctx, &stale); !errors.Is(err, ErrConflict) {
+		t.Fatalf("stale Update() = %v, want ErrConflict", err)
+	}
+
+	got := &Secret{}
+	api.Get(ctx, "default", "s", got)
+	if string(got.Data["k"]) != "v2" {
+		t.Fatalf("stored data = %q, want v2", got.Data["k"])
+	}
+}
@@ -0,0 +1,189 @@
+// Pattern 25: Injectable Clock
+//
+// Problem: Refresh gating (Pattern 08), condition timestamps (Patterns 10, 12),
+// retry sleeps (Pattern 14) and workqueue delays (Pattern 22) all depend on the
+// passage of time. If they call time.Now() and time.Sleep() directly, the only
+// way to observe "what happens after an hour" is to wait an hour.
+//
+// Solution: Depend on a Clock interface. Production code gets RealClock; tests
+// and simulations get a FakeClock that only moves when told to. Stepping the
+// fake clock fires any timers that came due, in deadline order, so delayed
+// requeues behave exactly as they would in real time — just instantly.
+//
+// REAL CODE REFERENCE:
+//   k8s.io/utils/clock/clock.go               (Clock, PassiveClock)
+//   k8s.io/utils/clock/testing/fake_clock.go  (FakeClock.Step, SetTime)
+//   client-go/util/workqueue/delaying_queue.go:75-90 (queue takes a clock)
+
+package guide
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// The Interface
+// =============================================================================
+
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	Sleep(d time.Duration)
+	// AfterFunc calls f in its own goroutine once d has elapsed.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+type Timer interface {
+	// Stop prevents the timer from firing. Returns false if it already fired.
+	Stop() bool
+}
+
+// =============================================================================
+// RealClock
+// =============================================================================
+
+type RealClock struct{}
+
+func (RealClock) Now() time.Time                  { return time.Now() }
+func (RealClock) Since(t time.Time) time.Duration { return time.Since(t) }
+func (RealClock) Sleep(d time.Duration)           { time.Sleep(d) }
+func (RealClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// orRealClock lets structs treat a nil Clock field as "use real time", so
+// their zero values stay usable.
+func orRealClock(c Clock) Clock {
+	if c == nil {
+		return RealClock{}
+	}
+	return c
+}
+
+// =============================================================================
+// FakeClock
+// =============================================================================
+
+// FakeClock only moves when Step, SetTime or Sleep is called.
+//
+// Real code: k8s.io/utils/clock/testing/fake_clock.go
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeTimer
+}
+
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// Sleep doesn't block: it advances the clock by d, firing anything due.
+// A retry loop that "sleeps" through 10 minutes of backoff finishes instantly.
+func (c *FakeClock) Sleep(d time.Duration) {
+	c.Step(d)
+}
+
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{clock: c, deadline: c.now.Add(d), f: f}
+	c.waiters = append(c.waiters, t)
+	return t
+}
+
+// Step advances the clock by d.
+func (c *FakeClock) Step(d time.Duration) {
+	c.mu.Lock()
+	now := c.now.Add(d)
+	c.mu.Unlock()
+	c.SetTime(now)
+}
+
+// SetTime moves the clock to t and runs every timer whose deadline has
+// passed, earliest first. Callbacks run synchronously, outside the lock, so
+// by the time SetTime returns the workqueue has already seen its AddAfter
+// items — no sleeping or polling needed in the caller.
+func (c *FakeClock) SetTime(t time.Time) {
+	c.mu.Lock()
+	c.now = t
+	var due, pending []*fakeTimer
+	for _, w := range c.waiters {
+		if !w.deadline.After(t) {
+			due = append(due, w)
+		} else {
+			pending = append(pending, w)
+		}
+	}
+	c.waiters = pending
+	c.mu.Unlock()
+
+	sort.SliceStable(due, func(i, j int) bool { return due[i].deadline.Before(due[j].deadline) })
+	for _, w := range due {
+		w.f()
+	}
+}
+
+// Waiters returns how many timers have not fired yet.
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+type fakeTimer struct {
+	clock    *FakeClock
+	deadline time.Time
+	f        func()
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	for i, w := range t.clock.waiters {
+		if w == t {
+			t.clock.waiters = append(t.clock.waiters[:i], t.clock.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// =============================================================================
+// Example: An Hour of Refresh Cycles in Microseconds
+// =============================================================================
+
+func ExampleSimulatedRefreshHour() {
+	clock := NewFakeClock(time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC))
+
+	spec := ExternalSecretSpec{RefreshInterval: 10 * time.Minute, RefreshPolicy: "Periodic", Generation: 1}
+	status := ExternalSecretStatus{}
+
+	refreshes := 0
+	for minute := 0; minute < 60; minute++ {
+		if shouldRefresh(clock, spec, status, spec.Generation) {
+			refreshes++
+			status.SyncedResourceVersion = fmt.Sprint(spec.Generation)
+			status.RefreshTime = clock.Now()
+		}
+		clock.Step(time.Minute)
+	}
+	fmt.Println("provider calls in one simulated hour:", refreshes) // 6
+}
+
+// KEY INSIGHT:
+// Time is an input like any other. Once it's injected, "does the secret
+// refresh after an hour?" becomes a loop over Step() instead of a flaky test
+// with a sleep in it.
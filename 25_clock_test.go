@@ -0,0 +1,113 @@
+package guide
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFakeClockFiresDueTimersInDeadlineOrderSynchronously(t *testing.T) {
+	clock := NewFakeClock(testEpoch)
+	var fired []string
+	record := func(name string) func() { return func() { fired = append(fired, name) } }
+
+	clock.AfterFunc(3*time.Second, record("3s"))
+	clock.AfterFunc(time.Second, record("1s-a"))
+	clock.AfterFunc(2*time.Second, record("2s"))
+	clock.AfterFunc(time.Second, record("1s-b")) // same deadline: registration order
+	clock.AfterFunc(time.Minute, record("1m"))
+
+	clock.Step(999 * time.Millisecond)
+	if len(fired) != 0 {
+		t.Fatalf("fired before any deadline: %v", fired)
+	}
+	clock.Step(2001 * time.Millisecond) // now at exactly 3s: deadlines are inclusive
+	// No waiting: SetTime has already run every callback when it returns.
+	if got := strings.Join(fired, ","); got != "1s-a,1s-b,2s,3s" {
+		t.Fatalf("fired = %s, want 1s-a,1s-b,2s,3s", got)
+	}
+	if got := clock.Waiters(); got != 1 {
+		t.Fatalf("Waiters() = %d, want 1 (the 1m timer)", got)
+	}
+
+	clock.SetTime(testEpoch.Add(time.Hour))
+	if fired[len(fired)-1] != "1m" {
+		t.Fatalf("SetTime did not fire the 1m timer: %v", fired)
+	}
+	if !clock.Now().Equal(testEpoch.Add(time.Hour)) {
+		t.Fatalf("Now() = %v", clock.Now())
+	}
+}
+
+func TestFakeClockStopBeforeDeadlinePreventsFiring(t *testing.T) {
+	clock := NewFakeClock(testEpoch)
+	fired := 0
+	timer := clock.AfterFunc(time.Second, func() { fired++ })
+	other := clock.AfterFunc(time.Second, func() { fired += 10 })
+
+	if !timer.Stop() {
+		t.Fatal("Stop() on a pending timer = false, want true")
+	}
+	if timer.Stop() {
+		t.Fatal("second Stop() = true, want false")
+	}
+	clock.Step(time.Second)
+	if fired != 10 {
+		t.Fatalf("fired = %d, want only the other timer (10)", fired)
+	}
+	if other.Stop() {
+		t.Fatal("Stop() after firing = true, want false")
+	}
+}
+
+// A callback that arms the next timer — what FileWatcher.poll and the
+// workqueue's delayed adds do — must not deadlock on the clock's lock, and
+// its timer counts from the time the callback ran.
+func TestFakeClockCallbackCanRearm(t *testing.T) {
+	clock := NewFakeClock(testEpoch)
+	var ticks []time.Duration
+	var tick func()
+	tick = func() {
+		ticks = append(ticks, clock.Since(testEpoch))
+		if len(ticks) < 3 {
+			clock.AfterFunc(5*time.Second, tick)
+		}
+	}
+	clock.AfterFunc(5*time.Second, tick)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 4; i++ {
+			clock.Step(5 * time.Second)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Step deadlocked while a callback re-armed a timer")
+	}
+
+	want := []time.Duration{5 * time.Second, 10 * time.Second, 15 * time.Second}
+	if len(ticks) != len(want) {
+		t.Fatalf("ticks = %v, want %v", ticks, want)
+	}
+	for i := range want {
+		if ticks[i] != want[i] {
+			t.Fatalf("ticks = %v, want %v", ticks, want)
+		}
+	}
+	if got := clock.Waiters(); got != 0 {
+		t.Fatalf("Waiters() = %d after the last tick stopped re-arming", got)
+	}
+}
+
+func TestFakeClockSleepAdvancesAndFires(t *testing.T) {
+	clock := NewFakeClock(testEpoch)
+	fired := false
+	clock.AfterFunc(time.Minute, func() { fired = true })
+	clock.Sleep(10 * time.Minute) // returns at once
+	if !fired || clock.Since(testEpoch) != 10*time.Minute {
+		t.Fatalf("after Sleep: fired=%v elapsed=%v", fired, clock.Since(testEpoch))
+	}
+}
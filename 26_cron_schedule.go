@@ -0,0 +1,288 @@
+// Pattern 26: Cron Schedules for Refresh
+//
+// Problem: RefreshInterval answers "how often", but credential rotation is
+// usually agreed as "when": every Sunday at 02:00 UTC, the first of the month,
+// weekdays at 06:00. An interval can't express that — a 168h interval drifts
+// with every restart and every spec change, because it's measured from the
+// last refresh, not from the calendar.
+//
+// Solution: A "Schedule" RefreshPolicy (Pattern 08) that takes a standard
+// five-field cron expression. Gating asks "has a firing happened since
+// status.RefreshTime?", and the requeue is the exact time until the next
+// firing — no polling, one reconcile per firing.
+//
+// FIELDS (all evaluated in UTC):
+//   minute        0-59
+//   hour          0-23
+//   day of month  1-31
+//   month         1-12 or JAN-DEC
+//   day of week   0-7 or SUN-SAT (0 and 7 are both Sunday)
+//
+// Each field accepts *, N, A-B, */S, A-B/S, N/S and comma-separated lists.
+// As in every cron, if BOTH day-of-month and day-of-week are restricted, a
+// day matches when EITHER does ("0 2 1 * SUN" = the 1st AND every Sunday).
+// "*/1" counts as unrestricted, like "*".
+//
+// REAL CODE REFERENCE:
+//   github.com/robfig/cron/v3/parser.go  (field parsing, getRange)
+//   github.com/robfig/cron/v3/spec.go    (SpecSchedule.Next, dayMatches)
+
+package guide
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// Parsing
+// =============================================================================
+
+// CronSchedule is a parsed cron expression. Each field is a bitset: bit N is
+// set when value N matches.
+type CronSchedule struct {
+	expr string
+
+	minute, hour, dom, month, dow uint64
+
+	// domStar/dowStar record an unrestricted field ("*", "?" or either with
+	// step 1), which decides between AND and OR when matching days.
+	domStar, dowStar bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	cronMinute = cronField{name: "minute", min: 0, max: 59}
+	cronHour   = cronField{name: "hour", min: 0, max: 23}
+	cronDom    = cronField{name: "day-of-month", min: 1, max: 31}
+	cronMonth  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+	}}
+	cronDow = cronField{name: "day-of-week", min: 0, max: 7, names: map[string]int{
+		"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+	}}
+)
+
+// ErrInvalidCron is wrapped by every error ParseCron returns.
+var ErrInvalidCron = errors.New("invalid cron expression")
+
+// ParseCron parses a five-field cron expression. Every bad field is reported,
+// not just the first, joined with errors.Join so a webhook can show them all
+// at once (advanced Pattern 11).
+//
+// Real code: robfig/cron/v3/parser.go (Parser.Parse)
+func ParseCron(expr string) (*CronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w %q: expected 5 fields (minute hour day-of-month month day-of-week), got %d",
+			ErrInvalidCron, expr, len(fields))
+	}
+
+	s := &CronSchedule{expr: expr}
+	var errs error
+	parse := func(value string, f cronField) uint64 {
+		bits, err := f.parse(value)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("%w %q: %s field: %v", ErrInvalidCron, expr, f.name, err))
+		}
+		return bits
+	}
+	s.minute = parse(fields[0], cronMinute)
+	s.hour = parse(fields[1], cronHour)
+	s.dom = parse(fields[2], cronDom)
+	s.month = parse(fields[3], cronMonth)
+	s.dow = parse(fields[4], cronDow)
+	if errs != nil {
+		return nil, errs
+	}
+
+	// 7 is an alias for Sunday; fold it onto 0 so matching only checks 0-6.
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1<<0
+	}
+	s.domStar = isCronStar(fields[2])
+	s.dowStar = isCronStar(fields[4])
+	return s, nil
+}
+
+// isCronStar reports whether a day field is unrestricted. "*/1" selects the
+// same days as "*", so it must not switch day matching to OR: "0 2 */1 * MON"
+// is every Monday, not every day.
+//
+// Real code: robfig/cron/v3/parser.go (getRange keeps starBit when step is 1)
+func isCronStar(field string) bool {
+	rangeExpr, stepExpr, hasStep := strings.Cut(field, "/")
+	if rangeExpr != "*" && rangeExpr != "?" {
+		return false
+	}
+	if !hasStep {
+		return true
+	}
+	step, err := strconv.Atoi(stepExpr)
+	return err == nil && step == 1
+}
+
+// parse handles one field: a comma-separated list of ranges.
+//
+// Real code: robfig/cron/v3/parser.go (getField, getRange)
+func (f cronField) parse(value string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(value, ",") {
+		b, err := f.parseRange(part)
+		if err != nil {
+			return 0, err
+		}
+		bits |= b
+	}
+	return bits, nil
+}
+
+func (f cronField) parseRange(part string) (uint64, error) {
+	rangeExpr, stepExpr, hasStep := strings.Cut(part, "/")
+
+	var lo, hi int
+	switch {
+	case rangeExpr == "*" || rangeExpr == "?":
+		lo, hi = f.min, f.max
+	case strings.Contains(rangeExpr, "-"):
+		a, b, _ := strings.Cut(rangeExpr, "-")
+		var err error
+		if lo, err = f.value(a); err != nil {
+			return 0, err
+		}
+		if hi, err = f.value(b); err != nil {
+			return 0, err
+		}
+		if lo > hi {
+			return 0, fmt.Errorf("range %q: start is after end", rangeExpr)
+		}
+	default:
+		var err error
+		if lo, err = f.value(rangeExpr); err != nil {
+			return 0, err
+		}
+		hi = lo
+		if hasStep {
+			// "5/15" means "from 5 to the end, every 15".
+			hi = f.max
+		}
+	}
+
+	step := 1
+	if hasStep {
+		n, err := strconv.Atoi(stepExpr)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("step %q: must be a positive integer", stepExpr)
+		}
+		step = n
+	}
+
+	var bits uint64
+	for v := lo; v <= hi; v += step {
+		bits |= 1 << uint(v)
+	}
+	return bits, nil
+}
+
+func (f cronField) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToUpper(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("value %q: not a number", s)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("value %d: out of range [%d, %d]", v, f.min, f.max)
+	}
+	return v, nil
+}
+
+func (s *CronSchedule) String() string { return s.expr }
+
+// =============================================================================
+// Computing the Next Firing
+// =============================================================================
+
+// Next returns the first firing strictly after t, in UTC. It skips whole
+// months, days and hours that can't match instead of stepping minute by
+// minute. Returns the zero time if nothing matches within five years
+// ("0 0 30 FEB *").
+//
+// Real code: robfig/cron/v3/spec.go (SpecSchedule.Next)
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	t = t.UTC().Add(time.Minute - time.Duration(t.Second())*time.Second - time.Duration(t.Nanosecond()))
+	yearLimit := t.Year() + 5
+
+	for t.Year() <= yearLimit {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches applies cron's day rule: AND when either day field is "*",
+// OR when both are restricted.
+//
+// Real code: robfig/cron/v3/spec.go (dayMatches)
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// =============================================================================
+// Example: Weekly Rotation Window
+// =============================================================================
+
+func ExampleScheduledRefresh() {
+	// Saturday 2024-01-13 09:00 UTC; last sync was Friday.
+	clock := NewFakeClock(time.Date(2024, 1, 13, 9, 0, 0, 0, time.UTC))
+	spec := ExternalSecretSpec{RefreshPolicy: "Schedule", RefreshSchedule: "0 2 * * SUN", Generation: 1}
+	status := ExternalSecretStatus{SyncedResourceVersion: "1", RefreshTime: clock.Now().Add(-24 * time.Hour)}
+
+	fmt.Println("due now:", shouldRefresh(clock, spec, status, spec.Generation)) // false
+	fmt.Println("requeue after:", refreshRequeueAfter(clock, spec, status))      // 17h0m0s — Sunday 02:00
+
+	clock.Step(17 * time.Hour)
+	fmt.Println("due at Sunday 02:00:", shouldRefresh(clock, spec, status, spec.Generation)) // true
+
+	_, err := ParseCron("61 25 * FOO MON")
+	fmt.Println(err)
+	// invalid cron expression "61 25 * FOO MON": minute field: value 61: out of range [0, 59]
+	// invalid cron expression "61 25 * FOO MON": hour field: value 25: out of range [0, 23]
+	// invalid cron expression "61 25 * FOO MON": month field: value "FOO": not a number
+}
+
+// KEY INSIGHT:
+// An interval is measured from the last refresh; a schedule is measured from
+// the calendar. Asking "did a firing happen since RefreshTime?" instead of
+// "is it firing right now?" means a controller that was down at 02:00 still
+// rotates as soon as it comes back — the missed firing isn't lost.
@@ -0,0 +1,73 @@
+package guide
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	// Saturday 2024-01-13 09:00 UTC.
+	from := time.Date(2024, 1, 13, 9, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		expr string
+		want time.Time
+	}{
+		{"0 2 * * SUN", time.Date(2024, 1, 14, 2, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 1, 13, 9, 15, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 6 * * MON-FRI", time.Date(2024, 1, 15, 6, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 FEB *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 FEB *", time.Time{}},
+	} {
+		s, err := ParseCron(tc.expr)
+		if err != nil {
+			t.Fatalf("ParseCron(%q) = %v", tc.expr, err)
+		}
+		if got := s.Next(from); !got.Equal(tc.want) {
+			t.Errorf("%q: Next = %v, want %v", tc.expr, got, tc.want)
+		}
+	}
+}
+
+func TestCronDayFieldsOrOnlyWhenBothRestricted(t *testing.T) {
+	// Friday 2024-03-01.
+	friday := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		expr  string
+		match bool
+	}{
+		{"0 2 1 * SUN", true},    // both restricted: the 1st OR Sunday
+		{"0 2 * * MON", false},   // only Mondays
+		{"0 2 */1 * MON", false}, // */1 is *, so still only Mondays
+		{"0 2 ?/1 * MON", false},
+		{"0 2 1 * */1", true},   // the 1st, every weekday
+		{"0 2 2 * */1", false},  // */1 is *, so only the 2nd
+		{"0 2 */2 * MON", true}, // */2 is a real restriction: odd days OR Monday
+	} {
+		s, err := ParseCron(tc.expr)
+		if err != nil {
+			t.Fatalf("ParseCron(%q) = %v", tc.expr, err)
+		}
+		if got := s.dayMatches(friday); got != tc.match {
+			t.Errorf("%q on Friday the 1st: match = %v, want %v", tc.expr, got, tc.match)
+		}
+	}
+}
+
+func TestParseCronReportsEveryBadField(t *testing.T) {
+	_, err := ParseCron("61 25 * FOO MON")
+	if !errors.Is(err, ErrInvalidCron) {
+		t.Fatalf("err = %v, want ErrInvalidCron", err)
+	}
+	for _, field := range []string{"minute field", "hour field", "month field"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("error does not mention the %s:\n%v", field, err)
+		}
+	}
+	if _, err := ParseCron("0 2 * *"); !errors.Is(err, ErrInvalidCron) {
+		t.Errorf("four fields: err = %v, want ErrInvalidCron", err)
+	}
+}
@@ -0,0 +1,164 @@
+// Pattern 27: Jittered Refresh Scheduling
+//
+// Problem: refreshRequeueAfter (Pattern 08) returns "interval minus elapsed".
+// 5,000 ExternalSecrets applied by one `kubectl apply` all sync within the
+// same few seconds, so they all come due within the same few seconds one hour
+// later — and every hour after that. The provider sees a wall of requests once
+// per interval and silence in between, and that's exactly when it starts
+// returning 429s.
+//
+// Solution: Add a per-object offset to the requeue delay. Two properties matter:
+//   - BOUNDED: the offset is at most Percent × interval, so no secret is ever
+//     refreshed later than the operator allowed for
+//   - DETERMINISTIC: the offset is a hash of namespace/name, not math/rand, so
+//     a restarted controller (or the other replica after failover) schedules
+//     each object at the same offset instead of reshuffling the whole fleet
+//
+// Because each object's effective period is interval + its own offset, a fleet
+// that starts in lockstep is spread flat over [interval, interval×(1+Percent))
+// after ONE cycle, and never re-synchronizes.
+//
+// For a fleet that's already synchronized (e.g. enabling jitter on an existing
+// cluster), Spread() gives each object a one-off first delay uniformly spread
+// over the whole interval.
+//
+// REAL CODE REFERENCE:
+//   k8s.io/apimachinery/pkg/util/wait (Jitter)
+//   controller-runtime/pkg/cache (jittered SyncPeriod)
+
+package guide
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+// =============================================================================
+// The Jitter Policy
+// =============================================================================
+
+// RefreshJitter adds a deterministic, bounded offset to refresh requeues.
+// The zero value adds no jitter.
+type RefreshJitter struct {
+	// Percent is the maximum offset as a fraction of the refresh interval:
+	// 0.1 means up to +10%. Values outside [0, 1] are clamped.
+	Percent float64
+}
+
+// jitterFraction maps a key to a stable value in [0, 1).
+func jitterFraction(req Request) float64 {
+	h := fnv.New64a()
+	h.Write([]byte(req.String()))
+	// FNV-1a alone leaves the high bits of near-identical names ("es-1",
+	// "es-2", ...) correlated, which shows up as lumps in the histogram.
+	// The murmur3 finalizer avalanches every input bit across the word.
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	// The top 53 bits fill a float64 mantissa exactly: uniform, never 1.0.
+	return float64(x>>11) / (1 << 53)
+}
+
+func (j RefreshJitter) percent() float64 {
+	switch {
+	case j.Percent < 0:
+		return 0
+	case j.Percent > 1:
+		return 1
+	}
+	return j.Percent
+}
+
+// Offset is the object's fixed share of the jitter window.
+func (j RefreshJitter) Offset(req Request, interval time.Duration) time.Duration {
+	return time.Duration(jitterFraction(req) * j.percent() * float64(interval))
+}
+
+// RequeueAfter jitters a base delay. A zero base means "don't requeue" and is
+// left alone, so CreatedOnce/OnChange stay unscheduled.
+func (j RefreshJitter) RequeueAfter(req Request, base, interval time.Duration) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	return base + j.Offset(req, interval)
+}
+
+// Spread returns a one-off first delay uniformly spread over [0, interval),
+// independent of Percent. Use it once per object when rolling jitter out to a
+// fleet whose refreshes are already lined up.
+func (j RefreshJitter) Spread(req Request, interval time.Duration) time.Duration {
+	return time.Duration(jitterFraction(req) * float64(interval))
+}
+
+// RefreshRequeueAfter is refreshRequeueAfter with this object's offset added.
+// Schedule policies have no interval and stay exact: a cron window is a
+// promise about wall-clock time.
+func (j RefreshJitter) RefreshRequeueAfter(clock Clock, req Request, spec ExternalSecretSpec, status ExternalSecretStatus) time.Duration {
+	base := refreshRequeueAfter(clock, spec, status)
+	if spec.RefreshPolicy == "Schedule" {
+		return base
+	}
+	return j.RequeueAfter(req, base, spec.RefreshInterval)
+}
+
+// =============================================================================
+// Example: 10,000 Secrets Created Together
+// =============================================================================
+
+func ExampleJitteredRefresh() {
+	clock := NewFakeClock(time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC))
+	spec := ExternalSecretSpec{RefreshInterval: time.Hour, RefreshPolicy: "Periodic", Generation: 1}
+	status := ExternalSecretStatus{SyncedResourceVersion: "1", RefreshTime: clock.Now()}
+	jitter := RefreshJitter{Percent: 0.2}
+
+	// Bucket the next refresh of every object into 6-minute slots over the
+	// jitter window [1h, 1h12m). Without jitter all 10,000 land in slot 0.
+	const n, slots = 10000, 10
+	window := time.Duration(jitter.Percent * float64(spec.RefreshInterval))
+	var hist [slots]int
+	outside := 0
+	for i := 0; i < n; i++ {
+		req := Request{Namespace: fmt.Sprintf("team-%d", i%7), Name: fmt.Sprintf("es-%d", i)}
+		delay := jitter.RefreshRequeueAfter(clock, req, spec, status)
+		if delay < spec.RefreshInterval || delay >= spec.RefreshInterval+window {
+			outside++ // never happens: the offset is bounded by Percent
+			continue
+		}
+		hist[int((delay-spec.RefreshInterval)*slots/window)]++
+	}
+	printHistogram("refreshes per slot after jitter:", hist[:], n)
+	fmt.Println("  outside [1h, 1h12m):", outside) // 0
+	// Each slot holds ~1000 (within ~5%): flat, so the provider sees ~14 req/s
+	// for 12 minutes instead of 10,000 requests in one burst.
+
+	// Rolling jitter out to a fleet that's already lined up: Spread() moves
+	// each object to a stable point across the WHOLE hour.
+	var spread [slots]int
+	for i := 0; i < n; i++ {
+		req := Request{Namespace: "default", Name: fmt.Sprintf("legacy-%d", i)}
+		spread[int(jitter.Spread(req, spec.RefreshInterval)*slots/spec.RefreshInterval)]++
+	}
+	printHistogram("first refresh per 6m slot after Spread:", spread[:], n)
+}
+
+// printHistogram prints slot counts and the worst deviation from a flat line.
+func printHistogram(title string, hist []int, total int) {
+	expected := float64(total) / float64(len(hist))
+	worst := 0.0
+	for _, c := range hist {
+		if d := (float64(c) - expected) / expected; d > worst || -d > worst {
+			worst = max(d, -d)
+		}
+	}
+	fmt.Println(title, hist)
+	fmt.Printf("  max deviation from flat: %.1f%%\n", worst*100)
+}
+
+// KEY INSIGHT:
+// Random jitter fixes the burst but reshuffles on every restart; no jitter is
+// stable but bursty. Hashing the object key gives both: the fleet is spread,
+// and each object's slot survives restarts, leader failover and upgrades.
@@ -0,0 +1,94 @@
+package guide
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// jitterFleet is a fixed set of keys shaped like a real cluster: a few
+// namespaces, sequential names. The hash is deterministic, so the same keys
+// always land in the same slots and the test can't flake.
+func jitterFleet(n int) []Request {
+	reqs := make([]Request, n)
+	for i := range reqs {
+		reqs[i] = Request{Namespace: fmt.Sprintf("team-%d", i%7), Name: fmt.Sprintf("es-%d", i)}
+	}
+	return reqs
+}
+
+// chiSquare is the statistic for observed counts against a flat expectation.
+func chiSquare(hist []int, total int) float64 {
+	expected := float64(total) / float64(len(hist))
+	var chi2 float64
+	for _, c := range hist {
+		d := float64(c) - expected
+		chi2 += d * d / expected
+	}
+	return chi2
+}
+
+// 9 degrees of freedom, p = 0.001.
+const chiSquareCritical9 = 27.877
+
+func TestRefreshJitterStaysWithinBounds(t *testing.T) {
+	clock := NewFakeClock(testEpoch)
+	spec := ExternalSecretSpec{RefreshInterval: time.Hour, RefreshPolicy: "Periodic", Generation: 1}
+	status := ExternalSecretStatus{SyncedResourceVersion: "1", RefreshTime: clock.Now()}
+
+	for _, percent := range []float64{0, 0.1, 0.2, 1, 1.5, -0.3} {
+		jitter := RefreshJitter{Percent: percent}
+		window := time.Duration(jitter.percent() * float64(spec.RefreshInterval))
+		for _, req := range jitterFleet(2000) {
+			delay := jitter.RefreshRequeueAfter(clock, req, spec, status)
+			if delay < spec.RefreshInterval || (window > 0 && delay >= spec.RefreshInterval+window) ||
+				(window == 0 && delay != spec.RefreshInterval) {
+				t.Fatalf("Percent=%v %s: delay %v outside [1h, 1h+%v)", percent, req, delay, window)
+			}
+		}
+	}
+}
+
+func TestRefreshJitterIsDeterministicAndLeavesZeroAlone(t *testing.T) {
+	jitter := RefreshJitter{Percent: 0.2}
+	req := Request{Namespace: "default", Name: "db-creds"}
+	first := jitter.Offset(req, time.Hour)
+	for i := 0; i < 10; i++ {
+		if got := jitter.Offset(req, time.Hour); got != first {
+			t.Fatalf("Offset changed between calls: %v then %v", first, got)
+		}
+	}
+	if got := jitter.RequeueAfter(req, 0, time.Hour); got != 0 {
+		t.Fatalf("RequeueAfter(base=0) = %v, want 0 (no requeue stays no requeue)", got)
+	}
+}
+
+func TestRefreshJitterSpreadsFlat(t *testing.T) {
+	const n, slots = 10000, 10
+	jitter := RefreshJitter{Percent: 0.2}
+	window := time.Duration(jitter.Percent * float64(time.Hour))
+
+	var offsets, spread [slots]int
+	for _, req := range jitterFleet(n) {
+		offsets[int(jitter.Offset(req, time.Hour)*slots/window)]++
+		spread[int(jitter.Spread(req, time.Hour)*slots/time.Hour)]++
+	}
+	for name, hist := range map[string][]int{"Offset": offsets[:], "Spread": spread[:]} {
+		if chi2 := chiSquare(hist, n); chi2 > chiSquareCritical9 {
+			t.Errorf("%s: chi-square %.1f > %.1f, not flat: %v", name, chi2, chiSquareCritical9, hist)
+		}
+		for i, c := range hist {
+			if c < n/slots*9/10 || c > n/slots*11/10 {
+				t.Errorf("%s: slot %d has %d, want %d ± 10%%", name, i, c, n/slots)
+			}
+		}
+	}
+}
+
+// The statistic must actually catch a lump, or the flatness test proves nothing.
+func TestChiSquareRejectsLumpyHistogram(t *testing.T) {
+	lumpy := []int{1400, 1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000, 600}
+	if chi2 := chiSquare(lumpy, 10000); chi2 <= chiSquareCritical9 {
+		t.Fatalf("chi-square %.1f accepted a lumpy histogram", chi2)
+	}
+}
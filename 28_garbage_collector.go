@@ -0,0 +1,397 @@
+// Pattern 28: Cascading Garbage Collection
+//
+// Problem: Pattern 06 sets OwnerReferences with Controller and BlockOwnerDeletion
+// and says "Kubernetes deletes the child when the parent is deleted". That's
+// only true for one of three propagation policies, and what "deleted" means —
+// before the parent, after it, or never — depends on fields nothing in this
+// guide interprets.
+//
+// Solution: A small garbage collector over the FakeAPIServer (Pattern 24) that
+// implements the three policies the same way kube-controller-manager does:
+//
+//   Background  owner is deleted immediately; dependents are deleted afterwards,
+//               once the GC notices all their owners are gone
+//   Foreground  owner gets the "foregroundDeletion" finalizer and stays visible
+//               (terminating) until every dependent with BlockOwnerDeletion=true
+//               is gone; dependents are deleted first
+//   Orphan      owner gets the "orphan" finalizer; the GC strips the owner's
+//               references from every dependent, then lets the owner go
+//
+// The GC only ever adds finalizers, removes owner references and issues
+// deletes — all through the normal API. Finalizers on dependents are
+// respected, so a dependent stuck terminating also blocks a foreground owner.
+//
+// REAL CODE REFERENCE:
+//   k8s.io/kubernetes/pkg/controller/garbagecollector/garbagecollector.go
+//     (attemptToDeleteItem, processDeletingDependentsItem, orphanDependents)
+//   k8s.io/apiserver/pkg/registry/generic/registry/store.go
+//     (deletionFinalizersForGarbageCollection)
+
+package guide
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+)
+
+// =============================================================================
+// Propagation Policies
+// =============================================================================
+
+type PropagationPolicy string
+
+const (
+	DeletePropagationBackground PropagationPolicy = "Background"
+	DeletePropagationForeground PropagationPolicy = "Foreground"
+	DeletePropagationOrphan     PropagationPolicy = "Orphan"
+)
+
+// Finalizers the API server adds for Foreground and Orphan deletes.
+//
+// Real code: k8s.io/apimachinery/pkg/apis/meta/v1/types.go (FinalizerOrphanDependents,
+// FinalizerDeleteDependents)
+const (
+	FinalizerDeleteDependents = "foregroundDeletion"
+	FinalizerOrphanDependents = "orphan"
+)
+
+// Unstructured is an object whose kind is data rather than a Go type, so a
+// test can build any owner graph (ExternalSecret → Secret, PushSecret → ...)
+// without declaring a type per kind.
+type Unstructured struct {
+	ObjectMeta
+	Kind string
+}
+
+func (u *Unstructured) GetKind() string { return u.Kind }
+
+// =============================================================================
+// The Collector
+// =============================================================================
+
+type GarbageCollector struct {
+	Server *FakeAPIServer
+}
+
+func NewGarbageCollector(server *FakeAPIServer) *GarbageCollector {
+	return &GarbageCollector{Server: server}
+}
+
+// GCReport describes what one Sweep did. Entries are "Kind namespace/name".
+type GCReport struct {
+	Deleted  []string // delete issued by the GC (the object may still be terminating)
+	Orphaned []string // dependents whose owner reference was removed
+	Blocked  []string // foreground owners still waiting, with what they wait on
+}
+
+// Delete issues a delete with the given propagation policy. Like kubectl, it
+// only talks to the API server; dependents are handled by the next Sweep.
+//
+// Real code: store.go (deletionFinalizersForGarbageCollection) — the API
+// server turns the policy into a finalizer before starting graceful deletion.
+func (gc *GarbageCollector) Delete(ctx context.Context, obj Object, policy PropagationPolicy) error {
+	var finalizer string
+	switch policy {
+	case DeletePropagationBackground, "":
+		return gc.Server.Delete(ctx, obj)
+	case DeletePropagationForeground:
+		finalizer = FinalizerDeleteDependents
+	case DeletePropagationOrphan:
+		finalizer = FinalizerOrphanDependents
+	default:
+		return fmt.Errorf("unknown propagation policy %q", policy)
+	}
+
+	current := newLike(obj)
+	meta := obj.GetObjectMeta()
+	if err := gc.Server.Get(ctx, meta.Namespace, meta.Name, current); err != nil {
+		return err
+	}
+	if !slices.Contains(current.GetObjectMeta().Finalizers, finalizer) {
+		current.GetObjectMeta().Finalizers = append(current.GetObjectMeta().Finalizers, finalizer)
+		if err := gc.Server.Update(ctx, current); err != nil {
+			return err
+		}
+	}
+	return gc.Server.Delete(ctx, current)
+}
+
+// Sweep runs the collector until nothing changes: one call settles every
+// cascade that can currently make progress.
+//
+// Real code: garbagecollector.go (attemptToDeleteWorker, runProcessGraphChanges)
+func (gc *GarbageCollector) Sweep(ctx context.Context) (GCReport, error) {
+	var report GCReport
+	for {
+		changed, err := gc.sweepOnce(ctx, &report)
+		if err != nil || !changed {
+			report.Blocked = gc.blocked(ctx)
+			return report, err
+		}
+	}
+}
+
+func (gc *GarbageCollector) sweepOnce(ctx context.Context, report *GCReport) (bool, error) {
+	objs, err := gc.Server.List(ctx, ListOptions{})
+	if err != nil {
+		return false, err
+	}
+	byUID := make(map[string]Object, len(objs))
+	for _, obj := range objs {
+		byUID[obj.GetObjectMeta().UID] = obj
+	}
+
+	for _, obj := range objs {
+		meta := obj.GetObjectMeta()
+
+		// Owner being deleted with Orphan: detach dependents, then release.
+		// Real code: garbagecollector.go (orphanDependents)
+		if meta.DeletionTimestamp != nil && slices.Contains(meta.Finalizers, FinalizerOrphanDependents) {
+			for _, dep := range dependentsOf(objs, meta.UID) {
+				depMeta := dep.GetObjectMeta()
+				depMeta.OwnerReferences = slices.DeleteFunc(depMeta.OwnerReferences, func(r OwnerReference) bool {
+					return r.UID == meta.UID
+				})
+				if err := gc.Server.Update(ctx, dep); err != nil {
+					return false, err
+				}
+				report.Orphaned = append(report.Orphaned, describe(dep))
+			}
+			return true, gc.removeFinalizer(ctx, obj, FinalizerOrphanDependents)
+		}
+
+		// Owner being deleted with Foreground: delete dependents first, and
+		// release the owner only when no blocking dependent is left.
+		// Real code: garbagecollector.go (processDeletingDependentsItem)
+		if meta.DeletionTimestamp != nil && slices.Contains(meta.Finalizers, FinalizerDeleteDependents) {
+			deps := dependentsOf(objs, meta.UID)
+			for _, dep := range deps {
+				if dep.GetObjectMeta().DeletionTimestamp != nil {
+					continue // already terminating
+				}
+				// A blocking dependent is deleted in foreground too, so the
+				// wait propagates down the whole chain.
+				policy := DeletePropagationBackground
+				if refTo(dep, meta.UID).BlockOwnerDeletion {
+					policy = DeletePropagationForeground
+				}
+				if err := gc.Delete(ctx, dep, policy); err != nil && !errors.Is(err, ErrNotFound) {
+					return false, err
+				}
+				report.Deleted = append(report.Deleted, describe(dep))
+				return true, nil
+			}
+			if !slices.ContainsFunc(deps, func(dep Object) bool { return refTo(dep, meta.UID).BlockOwnerDeletion }) {
+				return true, gc.removeFinalizer(ctx, obj, FinalizerDeleteDependents)
+			}
+			continue // waiting on blocking dependents
+		}
+
+		// Dependent with owner references: delete once every owner is gone;
+		// if only some are gone, just drop the dangling references.
+		// Real code: garbagecollector.go (attemptToDeleteItem, classifyReferences)
+		if len(meta.OwnerReferences) == 0 || meta.DeletionTimestamp != nil {
+			continue
+		}
+		var live []OwnerReference
+		for _, ref := range meta.OwnerReferences {
+			if _, ok := byUID[ref.UID]; ok {
+				live = append(live, ref)
+			}
+		}
+		switch {
+		case len(live) == len(meta.OwnerReferences):
+			continue
+		case len(live) == 0:
+			if err := gc.Server.Delete(ctx, obj); err != nil && !errors.Is(err, ErrNotFound) {
+				return false, err
+			}
+			report.Deleted = append(report.Deleted, describe(obj))
+		default:
+			meta.OwnerReferences = live
+			if err := gc.Server.Update(ctx, obj); err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+func (gc *GarbageCollector) removeFinalizer(ctx context.Context, obj Object, finalizer string) error {
+	meta := obj.GetObjectMeta()
+	meta.Finalizers = slices.DeleteFunc(meta.Finalizers, func(f string) bool { return f == finalizer })
+	return gc.Server.Update(ctx, obj)
+}
+
+// blocked lists foreground owners that can't finish yet.
+func (gc *GarbageCollector) blocked(ctx context.Context) []string {
+	objs, _ := gc.Server.List(ctx, ListOptions{})
+	var out []string
+	for _, obj := range objs {
+		meta := obj.GetObjectMeta()
+		if meta.DeletionTimestamp == nil || !slices.Contains(meta.Finalizers, FinalizerDeleteDependents) {
+			continue
+		}
+		var waits []string
+		for _, dep := range dependentsOf(objs, meta.UID) {
+			if refTo(dep, meta.UID).BlockOwnerDeletion {
+				waits = append(waits, describe(dep))
+			}
+		}
+		out = append(out, fmt.Sprintf("%s waits on %v", describe(obj), waits))
+	}
+	return out
+}
+
+// =============================================================================
+// Dangling Owner References
+// =============================================================================
+
+// DanglingRef is an owner reference that points at nothing. Reason is
+// "owner not found", or "uid mismatch" when an object with the referenced
+// kind and name exists but is a different incarnation (deleted and recreated).
+type DanglingRef struct {
+	Dependent string
+	Owner     OwnerReference
+	Reason    string
+}
+
+// DanglingReferences reports without changing anything — run it before Sweep
+// to see what the GC is about to act on.
+//
+// Real code: garbagecollector.go (isDangling)
+func (gc *GarbageCollector) DanglingReferences(ctx context.Context) ([]DanglingRef, error) {
+	objs, err := gc.Server.List(ctx, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	byUID := make(map[string]bool, len(objs))
+	byName := make(map[string]bool, len(objs))
+	for _, obj := range objs {
+		meta := obj.GetObjectMeta()
+		byUID[meta.UID] = true
+		byName[KindOf(obj)+" "+meta.Namespace+"/"+meta.Name] = true
+	}
+
+	var dangling []DanglingRef
+	for _, obj := range objs {
+		meta := obj.GetObjectMeta()
+		for _, ref := range meta.OwnerReferences {
+			if byUID[ref.UID] {
+				continue
+			}
+			reason := "owner not found"
+			if byName[ref.Kind+" "+meta.Namespace+"/"+ref.Name] {
+				reason = "uid mismatch"
+			}
+			dangling = append(dangling, DanglingRef{Dependent: describe(obj), Owner: ref, Reason: reason})
+		}
+	}
+	sort.Slice(dangling, func(i, j int) bool { return dangling[i].Dependent < dangling[j].Dependent })
+	return dangling, nil
+}
+
+// --- helpers ---
+
+func dependentsOf(objs []Object, ownerUID string) []Object {
+	var deps []Object
+	for _, obj := range objs {
+		if refTo(obj, ownerUID) != nil {
+			deps = append(deps, obj)
+		}
+	}
+	return deps
+}
+
+func refTo(obj Object, ownerUID string) *OwnerReference {
+	refs := obj.GetObjectMeta().OwnerReferences
+	for i := range refs {
+		if refs[i].UID == ownerUID {
+			return &refs[i]
+		}
+	}
+	return nil
+}
+
+// newLike returns an empty object that the server will read as the same kind.
+func newLike(obj Object) Object {
+	if u, ok := obj.(*Unstructured); ok {
+		return &Unstructured{Kind: u.Kind}
+	}
+	return reflect.New(reflect.TypeOf(obj).Elem()).Interface().(Object)
+}
+
+func describe(obj Object) string {
+	meta := obj.GetObjectMeta()
+	return fmt.Sprintf("%s %s/%s", KindOf(obj), meta.Namespace, meta.Name)
+}
+
+// =============================================================================
+// Example: Deleting an ExternalSecret Under Each Policy
+// =============================================================================
+
+func ExampleCascadingDeletion() {
+	ctx := context.Background()
+
+	for _, policy := range []PropagationPolicy{DeletePropagationBackground, DeletePropagationForeground, DeletePropagationOrphan} {
+		server := NewFakeAPIServer()
+		gc := NewGarbageCollector(server)
+
+		es := &Unstructured{Kind: "ExternalSecret", ObjectMeta: ObjectMeta{Name: "db", Namespace: "default"}}
+		server.Create(ctx, es)
+		owned := OwnerReference{APIVersion: "external-secrets.io/v1", Kind: "ExternalSecret", Name: "db", UID: es.UID, Controller: true, BlockOwnerDeletion: true}
+
+		// The generated Secret also has a finalizer held by some other controller
+		// (e.g. a backup operator) that only lets go when it's done.
+		secret := &Secret{ObjectMeta: ObjectMeta{Name: "db-credentials", Namespace: "default",
+			OwnerReferences: []OwnerReference{owned}, Finalizers: []string{"backup.example.com/snapshot"}}}
+		server.Create(ctx, secret)
+
+		gc.Delete(ctx, es, policy)
+		report, _ := gc.Sweep(ctx)
+		fmt.Printf("%s: deleted=%v orphaned=%v blocked=%v\n", policy, report.Deleted, report.Orphaned, report.Blocked)
+
+		// The backup operator finishes and removes its finalizer.
+		server.Get(ctx, "default", "db-credentials", secret)
+		secret.Finalizers = nil
+		server.Update(ctx, secret)
+		gc.Sweep(ctx)
+
+		esErr := server.Get(ctx, "default", "db", &Unstructured{Kind: "ExternalSecret"})
+		secretErr := server.Get(ctx, "default", "db-credentials", &Secret{})
+		fmt.Printf("  afterwards: ExternalSecret gone=%v, Secret gone=%v\n",
+			errors.Is(esErr, ErrNotFound), errors.Is(secretErr, ErrNotFound))
+	}
+	// Background: deleted=[Secret default/db-credentials] orphaned=[] blocked=[]
+	//   afterwards: ExternalSecret gone=true, Secret gone=true
+	// Foreground: deleted=[Secret default/db-credentials] orphaned=[]
+	//             blocked=[ExternalSecret default/db waits on [Secret default/db-credentials]]
+	//   afterwards: ExternalSecret gone=true, Secret gone=true
+	// Orphan: deleted=[] orphaned=[Secret default/db-credentials] blocked=[]
+	//   afterwards: ExternalSecret gone=true, Secret gone=false
+
+	// A Secret whose ExternalSecret was deleted and recreated under the same
+	// name still points at the OLD uid — the GC treats it as ownerless.
+	server := NewFakeAPIServer()
+	gc := NewGarbageCollector(server)
+	es := &Unstructured{Kind: "ExternalSecret", ObjectMeta: ObjectMeta{Name: "db", Namespace: "default"}}
+	server.Create(ctx, es)
+	server.Create(ctx, &Secret{ObjectMeta: ObjectMeta{Name: "db-credentials", Namespace: "default",
+		OwnerReferences: []OwnerReference{{Kind: "ExternalSecret", Name: "db", UID: "uid-from-last-week", Controller: true}}}})
+	dangling, _ := gc.DanglingReferences(ctx)
+	for _, d := range dangling {
+		fmt.Printf("dangling: %s → %s %s (%s)\n", d.Dependent, d.Owner.Kind, d.Owner.Name, d.Reason)
+	}
+	// dangling: Secret default/db-credentials → ExternalSecret db (uid mismatch)
+}
+
+// KEY INSIGHT:
+// "Delete the parent and the child goes too" is the Background default, and
+// it's asynchronous. Foreground is the only policy where the parent's absence
+// PROVES the children are gone — and BlockOwnerDeletion is what it waits on.
+// Orphan is how you delete an ExternalSecret but keep the Secret it made.
@@ -0,0 +1,266 @@
+package guide
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+const foreignFinalizer = "backup.example.com/snapshot"
+
+type gcFixture struct {
+	t      *testing.T
+	ctx    context.Context
+	server *FakeAPIServer
+	gc     *GarbageCollector
+}
+
+func newGCFixture(t *testing.T) *gcFixture {
+	server := NewFakeAPIServer()
+	return &gcFixture{t: t, ctx: context.Background(), server: server, gc: NewGarbageCollector(server)}
+}
+
+// create stores an Unstructured of kind/name owned by owners, blocking
+// owner deletion when block is set.
+func (f *gcFixture) create(kind, name string, block bool, finalizers []string, owners ...*Unstructured) *Unstructured {
+	f.t.Helper()
+	obj := &Unstructured{Kind: kind, ObjectMeta: ObjectMeta{Name: name, Namespace: "default", Finalizers: finalizers}}
+	for i, o := range owners {
+		obj.OwnerReferences = append(obj.OwnerReferences, OwnerReference{
+			Kind: o.Kind, Name: o.Name, UID: o.UID, Controller: i == 0, BlockOwnerDeletion: block,
+		})
+	}
+	if err := f.server.Create(f.ctx, obj); err != nil {
+		f.t.Fatal(err)
+	}
+	return obj
+}
+
+// get returns the stored object, or nil once it is gone.
+func (f *gcFixture) get(obj *Unstructured) *Unstructured {
+	f.t.Helper()
+	got := &Unstructured{Kind: obj.Kind}
+	if err := f.server.Get(f.ctx, obj.Namespace, obj.Name, got); err != nil {
+		if isNotFoundErr(err) {
+			return nil
+		}
+		f.t.Fatal(err)
+	}
+	return got
+}
+
+func (f *gcFixture) terminating(obj *Unstructured) bool {
+	got := f.get(obj)
+	return got != nil && got.DeletionTimestamp != nil
+}
+
+func (f *gcFixture) release(obj *Unstructured) {
+	f.t.Helper()
+	got := f.get(obj)
+	got.Finalizers = slices.DeleteFunc(got.Finalizers, func(s string) bool { return s == foreignFinalizer })
+	if err := f.server.Update(f.ctx, got); err != nil {
+		f.t.Fatal(err)
+	}
+}
+
+func (f *gcFixture) sweep() GCReport {
+	f.t.Helper()
+	report, err := f.gc.Sweep(f.ctx)
+	if err != nil {
+		f.t.Fatalf("Sweep() = %v", err)
+	}
+	return report
+}
+
+func TestBackgroundDeletesOwnerFirstThenDependent(t *testing.T) {
+	f := newGCFixture(t)
+	es := f.create("ExternalSecret", "db", false, nil)
+	secret := f.create("Secret", "db-creds", true, []string{foreignFinalizer}, es)
+
+	if err := f.gc.Delete(f.ctx, es, DeletePropagationBackground); err != nil {
+		t.Fatal(err)
+	}
+	if f.get(es) != nil {
+		t.Fatal("background owner still present: it must not wait for dependents")
+	}
+	report := f.sweep()
+	if !slices.Equal(report.Deleted, []string{"Secret default/db-creds"}) || len(report.Blocked) != 0 {
+		t.Fatalf("report = %+v", report)
+	}
+	if !f.terminating(secret) {
+		t.Fatal("dependent not terminating: its foreign finalizer must hold it, not be removed")
+	}
+
+	f.release(secret)
+	if f.get(secret) != nil {
+		t.Fatal("dependent still present after its finalizer was released")
+	}
+}
+
+func TestForegroundWaitsForBlockingDependent(t *testing.T) {
+	f := newGCFixture(t)
+	es := f.create("ExternalSecret", "db", false, nil)
+	secret := f.create("Secret", "db-creds", true, []string{foreignFinalizer}, es)
+	log := f.create("ConfigMap", "audit", false, nil, es) // BlockOwnerDeletion=false
+
+	if err := f.gc.Delete(f.ctx, es, DeletePropagationForeground); err != nil {
+		t.Fatal(err)
+	}
+	report := f.sweep()
+	if !f.terminating(es) || !slices.Contains(f.get(es).Finalizers, FinalizerDeleteDependents) {
+		t.Fatal("foreground owner must stay, terminating, while a blocking dependent exists")
+	}
+	if !f.terminating(secret) {
+		t.Fatal("blocking dependent not deleted first")
+	}
+	if f.get(log) != nil {
+		t.Fatal("non-blocking dependent not deleted")
+	}
+	if want := []string{"ExternalSecret default/db waits on [Secret default/db-creds]"}; !slices.Equal(report.Blocked, want) {
+		t.Fatalf("Blocked = %v, want %v", report.Blocked, want)
+	}
+
+	f.release(secret)
+	if report := f.sweep(); len(report.Blocked) != 0 {
+		t.Fatalf("still blocked after the dependent went: %v", report.Blocked)
+	}
+	if f.get(es) != nil || f.get(secret) != nil {
+		t.Fatal("owner or dependent left behind")
+	}
+}
+
+func TestOrphanDetachesDependentAndKeepsIt(t *testing.T) {
+	f := newGCFixture(t)
+	es := f.create("ExternalSecret", "db", false, nil)
+	other := f.create("PushSecret", "mirror", false, nil)
+	secret := f.create("Secret", "db-creds", true, []string{foreignFinalizer}, es, other)
+
+	if err := f.gc.Delete(f.ctx, es, DeletePropagationOrphan); err != nil {
+		t.Fatal(err)
+	}
+	report := f.sweep()
+	if !slices.Equal(report.Orphaned, []string{"Secret default/db-creds"}) || len(report.Deleted) != 0 {
+		t.Fatalf("report = %+v", report)
+	}
+	if f.get(es) != nil {
+		t.Fatal("orphaning owner still present")
+	}
+	got := f.get(secret)
+	if got == nil || got.DeletionTimestamp != nil {
+		t.Fatal("orphaned dependent was deleted")
+	}
+	if len(got.OwnerReferences) != 1 || got.OwnerReferences[0].UID != other.UID {
+		t.Fatalf("owner references = %+v, want only the PushSecret's", got.OwnerReferences)
+	}
+	if !slices.Contains(got.Finalizers, foreignFinalizer) {
+		t.Fatal("the GC removed another controller's finalizer")
+	}
+}
+
+// ExternalSecret → Secret → ConfigMap, all blocking: nothing above a
+// dependent goes until the dependent has.
+func TestForegroundChainDeletesBottomUp(t *testing.T) {
+	f := newGCFixture(t)
+	es := f.create("ExternalSecret", "db", false, nil)
+	secret := f.create("Secret", "db-creds", true, nil, es)
+	leaf := f.create("ConfigMap", "db-cache", true, []string{foreignFinalizer}, secret)
+
+	ctx, cancel := context.WithCancel(f.ctx)
+	defer cancel()
+	events := f.server.Watch(ctx, ListOptions{})
+
+	if err := f.gc.Delete(f.ctx, es, DeletePropagationForeground); err != nil {
+		t.Fatal(err)
+	}
+	report := f.sweep()
+	for _, obj := range []*Unstructured{es, secret, leaf} {
+		if !f.terminating(obj) {
+			t.Fatalf("%s/%s not terminating while the leaf holds the chain", obj.Kind, obj.Name)
+		}
+	}
+	if len(report.Blocked) != 2 {
+		t.Fatalf("Blocked = %v, want the ExternalSecret and the Secret", report.Blocked)
+	}
+
+	f.release(leaf)
+	f.sweep()
+
+	var order []string
+	for len(order) < 3 {
+		select {
+		case ev := <-events:
+			if ev.Type == Deleted {
+				order = append(order, KindOf(ev.Object))
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("deletions seen = %v, want three", order)
+		}
+	}
+	if want := []string{"ConfigMap", "Secret", "ExternalSecret"}; !slices.Equal(order, want) {
+		t.Fatalf("deletion order = %v, want %v", order, want)
+	}
+}
+
+func TestDependentWithLiveAndDeadOwnersIsTrimmedNotDeleted(t *testing.T) {
+	f := newGCFixture(t)
+	live := f.create("ExternalSecret", "live", false, nil)
+	dead := f.create("ExternalSecret", "dead", false, nil)
+	secret := f.create("Secret", "shared", false, nil, live, dead)
+	if err := f.server.Delete(f.ctx, dead); err != nil {
+		t.Fatal(err)
+	}
+
+	report := f.sweep()
+	got := f.get(secret)
+	if got == nil || got.DeletionTimestamp != nil || len(report.Deleted) != 0 {
+		t.Fatalf("dependent with a live owner was deleted: %+v", report)
+	}
+	if len(got.OwnerReferences) != 1 || got.OwnerReferences[0].UID != live.UID {
+		t.Fatalf("owner references = %+v, want only the live owner", got.OwnerReferences)
+	}
+
+	// The last owner going takes it with it.
+	if err := f.gc.Delete(f.ctx, live, DeletePropagationBackground); err != nil {
+		t.Fatal(err)
+	}
+	f.sweep()
+	if f.get(secret) != nil {
+		t.Fatal("dependent survived its last owner")
+	}
+}
+
+func TestDanglingReferencesReportsWithoutChanging(t *testing.T) {
+	f := newGCFixture(t)
+	f.create("ExternalSecret", "db", false, nil) // recreated: a new uid
+	stale := &Unstructured{Kind: "Secret", ObjectMeta: ObjectMeta{Name: "db-creds", Namespace: "default",
+		OwnerReferences: []OwnerReference{{Kind: "ExternalSecret", Name: "db", UID: "uid-from-last-week", Controller: true}}}}
+	gone := &Unstructured{Kind: "Secret", ObjectMeta: ObjectMeta{Name: "old-creds", Namespace: "default",
+		OwnerReferences: []OwnerReference{{Kind: "ExternalSecret", Name: "old", UID: "uid-deleted", Controller: true}}}}
+	for _, obj := range []*Unstructured{stale, gone} {
+		if err := f.server.Create(f.ctx, obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dangling, err := f.gc.DanglingReferences(f.ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DanglingRef{
+		{Dependent: "Secret default/db-creds", Owner: stale.OwnerReferences[0], Reason: "uid mismatch"},
+		{Dependent: "Secret default/old-creds", Owner: gone.OwnerReferences[0], Reason: "owner not found"},
+	}
+	if !slices.Equal(dangling, want) {
+		t.Fatalf("DanglingReferences() = %+v, want %+v", dangling, want)
+	}
+	if f.get(stale) == nil || f.get(gone) == nil || f.get(stale).DeletionTimestamp != nil {
+		t.Fatal("DanglingReferences changed something")
+	}
+
+	// Sweep then treats a uid mismatch like a missing owner.
+	f.sweep()
+	if f.get(stale) != nil || f.get(gone) != nil {
+		t.Fatal("dependents of dangling owners survived the sweep")
+	}
+}
@@ -0,0 +1,227 @@
+// Pattern 29: Versioned Data Hash
+//
+// Problem: Refresh gating (Pattern 08) skips the provider call when the target
+// Secret "hasn't changed". To know that without remembering every Secret's
+// contents, the controller stamps a hash of what it wrote into an annotation
+// and recomputes it on read. Two things go wrong with a naive hash:
+//   - ORDER: hashing a map by iterating it gives a different hash every run
+//   - EVOLUTION: changing the algorithm makes every existing annotation look
+//     stale, so the next resync rewrites every Secret in the cluster at once
+//
+// Solution: A canonical, order-independent encoding (sorted keys, length-
+// prefixed fields), and a version prefix on the annotation value. Verification
+// uses the algorithm named by the annotation, so old hashes stay valid; only
+// Secrets that are written anyway pick up the current version.
+//
+//   reconcile.external-secrets.io/data-hash: v1:9f86d081884c7d659a2feaa0c55ad015...
+//   reconcile.external-secrets.io/data-hash: 5d41402abc4b2a76b9719d911017c592   ← legacy, no prefix
+//
+// REAL CODE REFERENCE:
+//   pkg/utils/utils.go (ObjectHash — the legacy md5-of-%+v format)
+//   externalsecret_controller.go:529-530 (annotation set in mutationFunc)
+//   externalsecret_controller.go:1152-1174 (isSecretValid compares it)
+
+package guide
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"sort"
+	"strings"
+)
+
+const AnnotationDataHash = "reconcile.external-secrets.io/data-hash"
+
+// ControllerMetadataPrefix marks labels and annotations the controller owns.
+// Only those are hashed: other tools may add their own metadata freely.
+const ControllerMetadataPrefix = "reconcile.external-secrets.io/"
+
+// ErrUnknownHashVersion means the annotation was written by a newer
+// controller (or by hand). The Secret is treated as invalid and rewritten.
+var ErrUnknownHashVersion = errors.New("unknown data-hash version")
+
+// HashedFields is what the hash covers.
+type HashedFields struct {
+	Data        map[string][]byte
+	Labels      map[string]string // controller-owned labels only
+	Annotations map[string]string // controller-owned annotations only, never the hash itself
+}
+
+// AnnotationMergeDataHashPrefix names the per-ExternalSecret hash on a Merge
+// target. Several ExternalSecrets can merge into one Secret (Pattern 33); a
+// shared annotation would be overwritten by each in turn and every one of
+// them would see the others' writes as tampering.
+const AnnotationMergeDataHashPrefix = AnnotationDataHash + "."
+
+// dataHashAnnotation is where es stamps and verifies its hash.
+func dataHashAnnotation(es *ExternalSecret) string {
+	if creationPolicy(es) == CreatePolicyMerge {
+		return AnnotationMergeDataHashPrefix + OwnerLabelValue(es.Namespace, es.Name)
+	}
+	return AnnotationDataHash
+}
+
+// hashedFieldsOf picks the fields es is responsible for out of a Secret. For
+// Owner and Orphan that's the whole Secret. A Merge target is shared, so only
+// the keys in es's last-applied record and that record itself are hashed:
+// another writer's keys and metadata can change without invalidating it.
+func hashedFieldsOf(secret *Secret, es *ExternalSecret) HashedFields {
+	if creationPolicy(es) != CreatePolicyMerge {
+		return HashedFields{
+			Data:        secret.Data,
+			Labels:      controllerOwned(secret.Labels),
+			Annotations: controllerOwned(secret.Annotations),
+		}
+	}
+
+	data := make(map[string][]byte)
+	for _, k := range lastAppliedKeys(secret, es) {
+		if v, ok := secret.Data[k]; ok {
+			data[k] = v
+		}
+	}
+	annotations := make(map[string]string)
+	if record, ok := secret.Annotations[lastAppliedKeysAnnotation(es)]; ok {
+		annotations[lastAppliedKeysAnnotation(es)] = record
+	}
+	return HashedFields{Data: data, Labels: map[string]string{}, Annotations: annotations}
+}
+
+// controllerOwned keeps the controller's metadata, minus every data-hash
+// annotation: a hash can't cover itself, nor another ExternalSecret's.
+func controllerOwned(m map[string]string) map[string]string {
+	owned := make(map[string]string)
+	for k, v := range m {
+		if strings.HasPrefix(k, ControllerMetadataPrefix) && !strings.HasPrefix(k, AnnotationDataHash) {
+			owned[k] = v
+		}
+	}
+	return owned
+}
+
+// =============================================================================
+// Versions
+// =============================================================================
+//
+// To evolve the algorithm: add "v2" here, point currentDataHashVersion at it,
+// and keep "v1" forever. Nothing is rewritten until its data changes.
+
+const currentDataHashVersion = "v1"
+
+var dataHashers = map[string]func(HashedFields) string{
+	"v1": dataHashV1,
+}
+
+// legacyDataHash is the unversioned format: md5 of the %+v rendering of the
+// data map. fmt prints maps with sorted keys, so it IS order-independent —
+// it just can't cover metadata and can't be told apart from a future format
+// except by its missing prefix.
+//
+// Real code: pkg/utils/utils.go (ObjectHash)
+func legacyDataHash(f HashedFields) string {
+	return fmt.Sprintf("%x", md5.Sum([]byte(fmt.Sprintf("%+v", f.Data))))
+}
+
+// dataHashV1 is SHA-256 over a canonical encoding: three sections (data,
+// labels, annotations), each a count followed by length-prefixed key/value
+// pairs in sorted key order. Length prefixes make ("ab","c") and ("a","bc")
+// hash differently, which plain concatenation wouldn't.
+func dataHashV1(f HashedFields) string {
+	h := sha256.New()
+	data := make(map[string]string, len(f.Data))
+	for k, v := range f.Data {
+		data[k] = string(v)
+	}
+	writeHashSection(h, data)
+	writeHashSection(h, f.Labels)
+	writeHashSection(h, f.Annotations)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func writeHashSection(h hash.Hash, m map[string]string) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf [8]byte
+	writeLen := func(n int) {
+		binary.BigEndian.PutUint64(buf[:], uint64(n))
+		h.Write(buf[:])
+	}
+	writeLen(len(keys))
+	for _, k := range keys {
+		writeLen(len(k))
+		h.Write([]byte(k))
+		writeLen(len(m[k]))
+		h.Write([]byte(m[k]))
+	}
+}
+
+// =============================================================================
+// Compute and Verify
+// =============================================================================
+
+// DataHash returns the annotation value for the current version.
+func DataHash(f HashedFields) string {
+	return currentDataHashVersion + ":" + dataHashers[currentDataHashVersion](f)
+}
+
+// VerifyDataHash recomputes the hash with the algorithm the annotation names.
+// An empty annotation never verifies.
+func VerifyDataHash(recorded string, f HashedFields) (bool, error) {
+	if recorded == "" {
+		return false, nil
+	}
+	version, sum, versioned := strings.Cut(recorded, ":")
+	if !versioned {
+		return recorded == legacyDataHash(f), nil
+	}
+	hasher, ok := dataHashers[version]
+	if !ok {
+		return false, fmt.Errorf("%w %q", ErrUnknownHashVersion, version)
+	}
+	return sum == hasher(f), nil
+}
+
+// =============================================================================
+// Example: Detecting a Manual Edit Without a Provider Call
+// =============================================================================
+
+func ExampleDataHash() {
+	es := &ExternalSecret{Name: "my-es", Namespace: "default", UID: "uid-my-es", CreationPolicy: CreatePolicyOwner}
+	secret := &Secret{ObjectMeta: ObjectMeta{Name: "my-secret", Namespace: "default"}}
+	buildMutationFunc(es, map[string][]byte{"username": []byte("admin"), "password": []byte("s3cret")})(secret)
+
+	fmt.Println("annotation:", secret.Annotations[AnnotationDataHash][:12]+"...") // v1:...
+	fmt.Println("valid after write:", isTargetSecretValid(secret, es))            // true
+
+	// Another tool adds its own label: not ours, not hashed, still valid.
+	secret.Labels["team"] = "payments"
+	fmt.Println("valid after foreign label:", isTargetSecretValid(secret, es)) // true
+
+	// Someone runs kubectl edit and changes the password.
+	secret.Data["password"] = []byte("hunter2")
+	fmt.Println("valid after manual edit:", isTargetSecretValid(secret, es)) // false → resync
+
+	// A Secret written by an older controller carries the legacy hash. It
+	// still verifies, so upgrading the controller doesn't rewrite the fleet.
+	legacy := &Secret{
+		ObjectMeta: ObjectMeta{Labels: map[string]string{LabelManaged: "true"}},
+		Data:       map[string][]byte{"password": []byte("s3cret")},
+	}
+	legacy.Annotations = map[string]string{AnnotationDataHash: legacyDataHash(HashedFields{Data: legacy.Data})}
+	fmt.Println("legacy hash valid:", isTargetSecretValid(legacy, es)) // true
+}
+
+// KEY INSIGHT:
+// A hash that's stored must be reproducible forever. Canonical encoding makes
+// it reproducible across runs; the version prefix makes it reproducible across
+// releases. Without both, "skip if unchanged" quietly becomes "rewrite
+// everything" the day the map order or the algorithm changes.
@@ -0,0 +1,90 @@
+package guide
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDataHashIsOrderIndependentAndVersioned(t *testing.T) {
+	a := HashedFields{Data: map[string][]byte{"a": []byte("1"), "b": []byte("2")}}
+	b := HashedFields{Data: map[string][]byte{"b": []byte("2"), "a": []byte("1")}}
+	if DataHash(a) != DataHash(b) {
+		t.Fatal("hash depends on map order")
+	}
+	if !strings.HasPrefix(DataHash(a), currentDataHashVersion+":") {
+		t.Fatalf("hash %q has no version prefix", DataHash(a))
+	}
+	if ok, err := VerifyDataHash(DataHash(a), b); !ok || err != nil {
+		t.Fatalf("VerifyDataHash = %v, %v", ok, err)
+	}
+	// Moving a byte between key and value must change the hash.
+	c := HashedFields{Data: map[string][]byte{"a1": []byte(""), "b": []byte("2")}}
+	if DataHash(a) == DataHash(c) {
+		t.Fatal("ambiguous encoding: {a:1} and {a1:} hash the same")
+	}
+}
+
+// Two ExternalSecrets merge into one Secret next to a hand-maintained key.
+// Each one's hash covers only its own keys, so the others' writes are not
+// tampering — but an edit to one of its own keys is.
+func TestMergeTargetHashCoversOnlyOwnKeys(t *testing.T) {
+	ctx := context.Background()
+	client := NewAPIServerSecretClient(NewFakeAPIServer())
+	client.CreateSecret(ctx, &Secret{
+		ObjectMeta: ObjectMeta{Name: "app-config", Namespace: "default"},
+		Data:       map[string][]byte{"api-url": []byte("https://example.com")},
+	})
+	tokens := &ExternalSecret{Name: "tokens", Namespace: "default", UID: "uid-tokens", CreationPolicy: CreatePolicyMerge}
+	db := &ExternalSecret{Name: "db", Namespace: "default", UID: "uid-db", CreationPolicy: CreatePolicyMerge}
+
+	sync := func(es *ExternalSecret, data map[string][]byte) {
+		t.Helper()
+		if err := applyCreationPolicy(ctx, client, es, "app-config", buildMutationFunc(es, data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	valid := func(es *ExternalSecret) bool {
+		secret, _ := client.GetSecret(ctx, "default", "app-config")
+		return isTargetSecretValid(secret, es)
+	}
+
+	sync(tokens, map[string][]byte{"token": []byte("t1")})
+	sync(db, map[string][]byte{"password": []byte("p1")})
+	if !valid(tokens) || !valid(db) {
+		t.Fatalf("after both syncs: tokens valid=%v db valid=%v, want both", valid(tokens), valid(db))
+	}
+
+	// db's key set changes: its record and data move, tokens is unaffected.
+	sync(db, map[string][]byte{"password": []byte("p2"), "user": []byte("app")})
+	if !valid(tokens) {
+		t.Fatal("another ExternalSecret's sync invalidated tokens' hash")
+	}
+
+	// The platform team edits their own key.
+	secret, _ := client.GetSecret(ctx, "default", "app-config")
+	secret.Data["api-url"] = []byte("https://example.org")
+	client.UpdateSecret(ctx, secret)
+	if !valid(tokens) || !valid(db) {
+		t.Fatal("a foreign key edit invalidated a Merge hash")
+	}
+
+	// Someone edits a key tokens owns: only tokens must resync.
+	secret, _ = client.GetSecret(ctx, "default", "app-config")
+	secret.Data["token"] = []byte("forged")
+	client.UpdateSecret(ctx, secret)
+	if valid(tokens) {
+		t.Fatal("edit to tokens' own key not detected")
+	}
+	if !valid(db) {
+		t.Fatal("edit to tokens' key invalidated db")
+	}
+
+	// Deleting one of db's keys is tampering too.
+	secret, _ = client.GetSecret(ctx, "default", "app-config")
+	delete(secret.Data, "user")
+	client.UpdateSecret(ctx, secret)
+	if valid(db) {
+		t.Fatal("deleted key not detected")
+	}
+}
@@ -0,0 +1,203 @@
+package guide
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestTemplateReplaceDropsRawKeysOnUpdate(t *testing.T) {
+	ctx := context.Background()
+	providerData := map[string][]byte{"username": []byte("app"), "password": []byte("s3cret")}
+	tpl := &SecretTemplate{Data: map[string]string{"DSN": `{{ .username }}:{{ .password }}`}}
+
+	for _, policy := range []string{CreatePolicyOwner, CreatePolicyOrphan} {
+		client := NewAPIServerSecretClient(NewFakeAPIServer())
+		es := &ExternalSecret{Name: "db", Namespace: "default", UID: "uid-db", CreationPolicy: policy}
+
+		// First sync without a template writes the raw keys.
+		if err := applyCreationPolicy(ctx, client, es, "db", buildMutationFunc(es, providerData)); err != nil {
+			t.Fatal(err)
+		}
+		es.Template = tpl
+		if err := applyCreationPolicy(ctx, client, es, "db", buildMutationFunc(es, providerData)); err != nil {
+			t.Fatal(err)
+		}
+		secret, _ := client.GetSecret(ctx, "default", "db")
+		if got := dataKeys(secret); !slices.Equal(got, []string{"DSN"}) {
+			t.Errorf("%s: keys after adding a Replace template = %v, want [DSN]", policy, got)
+		}
+		if !isTargetSecretValid(secret, es) {
+			t.Errorf("%s: freshly synced Secret fails its own hash", policy)
+		}
+	}
+}
+
+func TestTemplateMergePolicyDropsRemovedProviderKeys(t *testing.T) {
+	ctx := context.Background()
+	client := NewAPIServerSecretClient(NewFakeAPIServer())
+	tpl := &SecretTemplate{MergePolicy: TemplateMergePolicyMerge, Data: map[string]string{"greeting": `hi {{ .user }}`}}
+	es := &ExternalSecret{Name: "app", Namespace: "default", UID: "uid-app", Template: tpl}
+
+	sync := func(data map[string][]byte) []string {
+		t.Helper()
+		if err := applyCreationPolicy(ctx, client, es, "app", buildMutationFunc(es, data)); err != nil {
+			t.Fatal(err)
+		}
+		secret, _ := client.GetSecret(ctx, "default", "app")
+		return dataKeys(secret)
+	}
+	sync(map[string][]byte{"user": []byte("a"), "old": []byte("x")})
+	if got := sync(map[string][]byte{"user": []byte("a")}); !slices.Equal(got, []string{"greeting", "user"}) {
+		t.Fatalf("keys = %v, want [greeting user]: a removed provider key survived", got)
+	}
+}
+
+// A Merge target keeps everyone else's keys, but a rendered key this
+// ExternalSecret stopped producing is removed (Pattern 33).
+func TestTemplateOnMergeTargetRemovesOnlyOwnStaleKeys(t *testing.T) {
+	ctx := context.Background()
+	client := NewAPIServerSecretClient(NewFakeAPIServer())
+	client.CreateSecret(ctx, &Secret{
+		ObjectMeta: ObjectMeta{Name: "shared", Namespace: "default"},
+		Data:       map[string][]byte{"theirs": []byte("keep")},
+	})
+	es := &ExternalSecret{Name: "app", Namespace: "default", UID: "uid-app", CreationPolicy: CreatePolicyMerge,
+		Template: &SecretTemplate{Data: map[string]string{"A": `{{ .v }}`, "B": `{{ .v }}`}}}
+	providerData := map[string][]byte{"v": []byte("1")}
+
+	if err := applyCreationPolicy(ctx, client, es, "shared", buildMutationFunc(es, providerData)); err != nil {
+		t.Fatal(err)
+	}
+	delete(es.Template.Data, "B")
+	if err := applyCreationPolicy(ctx, client, es, "shared", buildMutationFunc(es, providerData)); err != nil {
+		t.Fatal(err)
+	}
+	secret, _ := client.GetSecret(ctx, "default", "shared")
+	if got := dataKeys(secret); !slices.Equal(got, []string{"A", "theirs"}) {
+		t.Fatalf("keys = %v, want [A theirs]", got)
+	}
+}
+
+func TestTemplateErrorsReportEveryKeyAndLeaveSecretUntouched(t *testing.T) {
+	es := &ExternalSecret{Name: "db", Namespace: "default", UID: "uid-db",
+		Template: &SecretTemplate{Data: map[string]string{"a": `{{ .nope }}`, "b": `{{ .missing }}`}}}
+	secret := &Secret{Data: map[string][]byte{"keep": []byte("v")}}
+
+	err := buildMutationFunc(es, map[string][]byte{"x": []byte("1")})(secret)
+	var tplErr *TemplateError
+	if !errors.As(err, &tplErr) || tplErr.Key != "a" {
+		t.Fatalf("err = %v, want a TemplateError for key a first", err)
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); !ok || len(joined.Unwrap()) != 2 {
+		t.Fatalf("want both failing keys reported, got %v", err)
+	}
+	if got := dataKeys(secret); !slices.Equal(got, []string{"keep"}) {
+		t.Fatalf("a failed render wrote data: %v", got)
+	}
+}
+
+func TestTemplateCannotWriteControllerMetadata(t *testing.T) {
+	ctx := context.Background()
+	client := NewAPIServerSecretClient(NewFakeAPIServer())
+	es := &ExternalSecret{Name: "db", Namespace: "default", UID: "uid-db"}
+	if err := applyCreationPolicy(ctx, client, es, "db", buildMutationFunc(es, map[string][]byte{"k": []byte("v")})); err != nil {
+		t.Fatal(err)
+	}
+
+	es.Template = &SecretTemplate{
+		Data:        map[string]string{"k": `{{ .k }}`},
+		Labels:      map[string]string{LabelOwner: "someone-else", "team": "payments"},
+		Annotations: map[string]string{AnnotationDataHash: "v1:forged"},
+	}
+	err := applyCreationPolicy(ctx, client, es, "db", buildMutationFunc(es, map[string][]byte{"k": []byte("v")}))
+	if !errors.Is(err, ErrReservedMetadataKey) {
+		t.Fatalf("err = %v, want ErrReservedMetadataKey", err)
+	}
+	if joined, ok := errors.Unwrap(err).(interface{ Unwrap() []error }); !ok || len(joined.Unwrap()) != 2 {
+		t.Fatalf("want both reserved keys reported, got %v", err)
+	}
+	secret, _ := client.GetSecret(ctx, "default", "db")
+	if secret.Labels[LabelOwner] != OwnerLabelValue("default", "db") || secret.Labels["team"] != "" {
+		t.Fatalf("labels = %v, want the Secret untouched", secret.Labels)
+	}
+}
+
+func TestTemplateMetadataIsSkippedOnMergeTargets(t *testing.T) {
+	ctx := context.Background()
+	client := NewAPIServerSecretClient(NewFakeAPIServer())
+	client.CreateSecret(ctx, &Secret{ObjectMeta: ObjectMeta{Name: "app", Namespace: "default",
+		Labels: map[string]string{"team": "platform"}}})
+	es := &ExternalSecret{Name: "db", Namespace: "default", UID: "uid-db", CreationPolicy: CreatePolicyMerge,
+		Template: &SecretTemplate{
+			Data:        map[string]string{"dsn": `{{ .k }}`},
+			Labels:      map[string]string{"team": "payments"},
+			Annotations: map[string]string{"note": "synced"},
+		}}
+
+	if err := applyCreationPolicy(ctx, client, es, "app", buildMutationFunc(es, map[string][]byte{"k": []byte("v")})); err != nil {
+		t.Fatal(err)
+	}
+	secret, _ := client.GetSecret(ctx, "default", "app")
+	if secret.Labels["team"] != "platform" {
+		t.Fatalf("team label = %q: the template overwrote the owner's label", secret.Labels["team"])
+	}
+	if _, ok := secret.Annotations["note"]; ok {
+		t.Fatal("template annotation written to a Merge target")
+	}
+	if string(secret.Data["dsn"]) != "v" {
+		t.Fatal("template data not merged")
+	}
+}
+
+func TestTemplateMetadataDroppedByTheTemplateIsRemoved(t *testing.T) {
+	ctx := context.Background()
+	client := NewAPIServerSecretClient(NewFakeAPIServer())
+	data := map[string][]byte{"env": []byte("prod")}
+	es := &ExternalSecret{Name: "db", Namespace: "default", UID: "uid-db", Template: &SecretTemplate{
+		Data:        map[string]string{"k": `{{ .env }}`},
+		Labels:      map[string]string{"env": `{{ .env }}`, "tier": "db"},
+		Annotations: map[string]string{"owner-team": "payments"},
+	}}
+	sync := func() *Secret {
+		t.Helper()
+		if err := applyCreationPolicy(ctx, client, es, "db", buildMutationFunc(es, data)); err != nil {
+			t.Fatal(err)
+		}
+		secret, _ := client.GetSecret(ctx, "default", "db")
+		return secret
+	}
+
+	secret := sync()
+	// Another tool labels the Secret too.
+	secret.Labels["backup"] = "daily"
+	client.UpdateSecret(ctx, secret)
+
+	delete(es.Template.Labels, "tier")
+	secret = sync()
+	if _, ok := secret.Labels["tier"]; ok {
+		t.Fatal("label dropped from the template is still on the Secret")
+	}
+	if secret.Labels["env"] != "prod" || secret.Labels["backup"] != "daily" || secret.Annotations["owner-team"] != "payments" {
+		t.Fatalf("labels = %v, annotations = %v", secret.Labels, secret.Annotations)
+	}
+	if !isTargetSecretValid(secret, es) {
+		t.Fatal("freshly synced Secret fails its own hash")
+	}
+
+	es.Template = nil
+	secret = sync()
+	if _, ok := secret.Labels["env"]; ok {
+		t.Fatal("template label kept after the template was removed")
+	}
+	if _, ok := secret.Annotations["owner-team"]; ok {
+		t.Fatal("template annotation kept after the template was removed")
+	}
+	if _, ok := secret.Annotations[AnnotationLastAppliedMetadata]; ok {
+		t.Fatal("empty record left behind")
+	}
+	if secret.Labels["backup"] != "daily" || secret.Labels[LabelOwner] == "" {
+		t.Fatalf("labels = %v: removed more than the template's own", secret.Labels)
+	}
+}
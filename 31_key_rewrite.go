@@ -0,0 +1,307 @@
+// Pattern 31: Key Rewrite and Filtering
+//
+// Problem: GetSecretMap (Pattern 05) returns whatever the provider stores, and
+// the mutation function (Pattern 07) merges it into the Secret wholesale. But
+// provider keys are rarely what the application wants:
+//   - "/prod/db/password" isn't a valid Secret key ('/' isn't allowed)
+//   - "DB_PASSWORD" is expected where the provider has "db-password"
+//   - the provider path also holds admin credentials the app must NOT receive
+//
+// Solution: An ordered list of declarative rules (ExternalSecret.Rewrite)
+// that the mutation function applies to the provider map before templating.
+// Each rule does exactly one thing:
+//
+//   Regexp       rename by regular expression (source → target, $1 expansion)
+//   AddPrefix    prepend a string
+//   StripPrefix  remove a prefix from keys that have it
+//   Include      keep only keys matching at least one glob
+//   Exclude      drop keys matching any glob
+//   Sanitize     replace characters not allowed in Secret keys with '_'
+//
+// Rules are applied IN ORDER, so "strip /prod/db/, then exclude admin-*" and
+// "exclude admin-*, then strip" are different pipelines — as they should be.
+//
+// Renames can map two keys onto one ("db.password" and "db_password" both
+// sanitize to "db_password"). Silently picking one would be a security bug,
+// so every collision is reported, joined with errors.Join like
+// validateDuplicateKeys in advanced Pattern 11.
+//
+// REAL CODE REFERENCE:
+//   pkg/controllers/externalsecret/externalsecret_controller_secret.go (getProviderSecretData)
+//   pkg/utils/utils.go (RewriteMap, RewriteRegexp)
+//   apis/externalsecrets/v1/externalsecret_types.go (ExternalSecretRewrite, ConversionStrategy)
+
+package guide
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// =============================================================================
+// The Rules
+// =============================================================================
+
+// KeyRewrite is one step of the pipeline. Exactly one field must be set.
+type KeyRewrite struct {
+	Regexp      *RewriteRegexp
+	AddPrefix   string
+	StripPrefix string
+	Include     []string // path.Match globs
+	Exclude     []string // path.Match globs
+	Sanitize    bool
+}
+
+type RewriteRegexp struct {
+	Source string // e.g. `^/prod/db/(.*)$`
+	Target string // e.g. `DB_$1`
+}
+
+var (
+	ErrInvalidRewrite   = errors.New("invalid rewrite rule")
+	ErrRewriteCollision = errors.New("rewrite collision")
+	ErrInvalidSecretKey = errors.New("invalid secret key")
+)
+
+// maxSecretKeyLength is the longest data key Kubernetes accepts
+// (DNS1123SubdomainMaxLength).
+const maxSecretKeyLength = 253
+
+var secretKeyChars = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+var invalidSecretKeyChars = regexp.MustCompile(`[^-._a-zA-Z0-9]`)
+
+// validSecretKey applies the rules Kubernetes enforces on Secret data keys:
+// the character set, at most 253 characters, and not "." or "..", which a
+// volume mount would turn into the directory itself or its parent.
+//
+// Real code: k8s.io/apimachinery/pkg/util/validation/validation.go (IsConfigMapKey)
+func validSecretKey(key string) error {
+	switch {
+	case len(key) > maxSecretKeyLength:
+		return fmt.Errorf("%w %q: %d characters, at most %d allowed", ErrInvalidSecretKey, key, len(key), maxSecretKeyLength)
+	case !secretKeyChars.MatchString(key):
+		return fmt.Errorf("%w %q: only [-._a-zA-Z0-9] allowed (add a sanitize rule)", ErrInvalidSecretKey, key)
+	case key == "." || key == "..":
+		return fmt.Errorf("%w %q: must not be '.' or '..'", ErrInvalidSecretKey, key)
+	}
+	return nil
+}
+
+// ValidateKeyRewrites checks the rules themselves (webhook time). Every bad
+// rule is reported, not just the first.
+func ValidateKeyRewrites(rules []KeyRewrite) error {
+	_, err := compileKeyRewrites(rules)
+	return err
+}
+
+// compiledRewrite is a validated rule with its regexp compiled, so applying
+// it to N keys costs N matches, not N compilations.
+type compiledRewrite struct {
+	KeyRewrite
+	re *regexp.Regexp
+}
+
+func compileKeyRewrites(rules []KeyRewrite) ([]compiledRewrite, error) {
+	compiled := make([]compiledRewrite, len(rules))
+	var errs error
+	for i, rule := range rules {
+		compiled[i].KeyRewrite = rule
+		set := 0
+		if rule.Regexp != nil {
+			set++
+			re, err := regexp.Compile(rule.Regexp.Source)
+			if err != nil {
+				errs = errors.Join(errs, fmt.Errorf("%w: rewrite[%d].regexp: %v", ErrInvalidRewrite, i, err))
+			}
+			compiled[i].re = re
+		}
+		if rule.AddPrefix != "" {
+			set++
+		}
+		if rule.StripPrefix != "" {
+			set++
+		}
+		for _, globs := range [][]string{rule.Include, rule.Exclude} {
+			if len(globs) > 0 {
+				set++
+			}
+			for _, g := range globs {
+				if _, err := path.Match(g, ""); err != nil {
+					errs = errors.Join(errs, fmt.Errorf("%w: rewrite[%d]: glob %q: %v", ErrInvalidRewrite, i, g, err))
+				}
+			}
+		}
+		if rule.Sanitize {
+			set++
+		}
+		if set != 1 {
+			errs = errors.Join(errs, fmt.Errorf("%w: rewrite[%d]: exactly one of regexp, addPrefix, stripPrefix, include, exclude, sanitize must be set (got %d)", ErrInvalidRewrite, i, set))
+		}
+	}
+	if errs != nil {
+		return nil, errs
+	}
+	return compiled, nil
+}
+
+// =============================================================================
+// Applying the Pipeline
+// =============================================================================
+
+// ApplyKeyRewrites runs the rules in order and checks that every final key is
+// a valid Secret key. The input map is never modified.
+//
+// A collision doesn't stop the pipeline: the first key (in sorted order)
+// continues through the remaining rules, so one run reports every collision
+// in every rule and every invalid final key, not just the first rule's.
+//
+// Real code: pkg/utils/utils.go (RewriteMap)
+func ApplyKeyRewrites(data map[string][]byte, rules []KeyRewrite) (map[string][]byte, error) {
+	compiled, err := compileKeyRewrites(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[string][]byte, len(data))
+	for k, v := range data {
+		current[k] = v
+	}
+
+	var errs error
+	for i, rule := range compiled {
+		next := make(map[string][]byte, len(current))
+		from := make(map[string]string, len(current)) // new key → original key, for collision messages
+
+		// Sorted so the collision report is the same on every run.
+		for _, key := range sortedKeys(current) {
+			newKey, keep := rule.apply(key)
+			if !keep {
+				continue
+			}
+			if prev, clash := from[newKey]; clash {
+				errs = errors.Join(errs, fmt.Errorf("%w: rewrite[%d]: keys %q and %q both become %q",
+					ErrRewriteCollision, i, prev, key, newKey))
+				continue
+			}
+			from[newKey] = key
+			next[newKey] = current[key]
+		}
+		current = next
+	}
+
+	for _, key := range sortedKeys(current) {
+		if err := validSecretKey(key); err != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+	if errs != nil {
+		return nil, errs
+	}
+	return current, nil
+}
+
+// apply returns the rewritten key, or keep=false if the rule filters it out.
+func (r compiledRewrite) apply(key string) (newKey string, keep bool) {
+	switch {
+	case r.re != nil:
+		return r.re.ReplaceAllString(key, r.Regexp.Target), true
+	case r.AddPrefix != "":
+		return r.AddPrefix + key, true
+	case r.StripPrefix != "":
+		return strings.TrimPrefix(key, r.StripPrefix), true
+	case len(r.Include) > 0:
+		return key, matchesAnyGlob(key, r.Include)
+	case len(r.Exclude) > 0:
+		return key, !matchesAnyGlob(key, r.Exclude)
+	case r.Sanitize:
+		return invalidSecretKeyChars.ReplaceAllString(key, "_"), true
+	}
+	return key, true
+}
+
+func matchesAnyGlob(key string, globs []string) bool {
+	for _, g := range globs {
+		if ok, _ := path.Match(g, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// =============================================================================
+// Example: A Provider Path Shared by App and Admin Credentials
+// =============================================================================
+
+// mapSecretsClient serves fixed maps; enough to drive the pipeline.
+type mapSecretsClient map[string]map[string][]byte
+
+func (c mapSecretsClient) GetSecret(ctx context.Context, key string) ([]byte, error) {
+	return nil, fmt.Errorf("secret %q: %w", key, ErrNotFound)
+}
+func (c mapSecretsClient) GetSecretMap(ctx context.Context, key string) (map[string][]byte, error) {
+	if m, ok := c[key]; ok {
+		return m, nil
+	}
+	return nil, fmt.Errorf("secret %q: %w", key, ErrNotFound)
+}
+func (c mapSecretsClient) Close(ctx context.Context) error { return nil }
+
+func ExampleKeyRewrite() {
+	ctx := context.Background()
+	client := mapSecretsClient{"prod/db": {
+		"/prod/db/password":       []byte("app-pass"),
+		"/prod/db/username":       []byte("app"),
+		"/prod/db/admin-password": []byte("root-pass"),
+		"/prod/db/tls.crt":        []byte("-----BEGIN CERTIFICATE-----..."),
+	}}
+
+	// The rules live on the ExternalSecret. buildMutationFunc applies them to
+	// the provider map before the template runs, so the template is written
+	// against the application's key names, not the provider's paths.
+	es := &ExternalSecret{Name: "db", Namespace: "default", UID: "uid-db", Rewrite: []KeyRewrite{
+		{StripPrefix: "/prod/db/"},
+		{Exclude: []string{"admin-*"}}, // the app never sees admin credentials
+		{Regexp: &RewriteRegexp{Source: `^(password|username)$`, Target: "DB_$1"}},
+	}, Template: &SecretTemplate{
+		MergePolicy: TemplateMergePolicyMerge,
+		Data:        map[string]string{"DSN": `{{ .DB_username }}:{{ .DB_password }}@db`},
+	}}
+	providerData, _ := client.GetSecretMap(ctx, "prod/db")
+	secret := &Secret{ObjectMeta: ObjectMeta{Name: "db", Namespace: "default"}}
+	err := buildMutationFunc(es, providerData)(secret)
+	fmt.Println(dataKeys(secret), err)      // [DB_password DB_username DSN tls.crt] <nil>
+	fmt.Println(string(secret.Data["DSN"])) // app:app-pass@db
+
+	// Forgetting to strip the path leaves '/' in every key: all reported. Note
+	// admin-password too — like shell globs, '*' never matches across '/'.
+	_, err = ApplyKeyRewrites(providerData, []KeyRewrite{{Exclude: []string{"*admin*"}}})
+	fmt.Println(err)
+	// invalid secret key "/prod/db/admin-password": only [-._a-zA-Z0-9] allowed (add a sanitize rule)
+	// invalid secret key "/prod/db/password": ...
+	// invalid secret key "/prod/db/tls.crt": ...
+	// invalid secret key "/prod/db/username": ...
+
+	// Renames that make provider keys indistinguishable are refused, not
+	// guessed — and every collision is reported, in every rule.
+	_, err = ApplyKeyRewrites(map[string][]byte{"db.password": nil, "db/password": nil, "db-password": nil, "db:user": nil},
+		[]KeyRewrite{
+			{Regexp: &RewriteRegexp{Source: `\.`, Target: "/"}},
+			{Sanitize: true},
+			{Regexp: &RewriteRegexp{Source: `-`, Target: "_"}},
+		})
+	fmt.Println(err)
+	// rewrite collision: rewrite[0]: keys "db.password" and "db/password" both become "db/password"
+	// rewrite collision: rewrite[2]: keys "db-password" and "db_password" both become "db_password"
+
+	fmt.Println(errors.Is(err, ErrRewriteCollision)) // true
+}
+
+// KEY INSIGHT:
+// Key rewriting is where a sync controller decides what an application is
+// allowed to see. Making it an ordered, declarative pipeline keeps that
+// decision reviewable in the ExternalSecret, and refusing collisions keeps
+// "which value won?" from ever being an answer that depends on map order.
@@ -0,0 +1,126 @@
+package guide
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestKeyRewritesApplyInOrder(t *testing.T) {
+	data := map[string][]byte{"/prod/db/password": []byte("p"), "/prod/db/admin-password": []byte("root")}
+
+	got, err := ApplyKeyRewrites(data, []KeyRewrite{
+		{StripPrefix: "/prod/db/"},
+		{Exclude: []string{"admin-*"}},
+		{AddPrefix: "DB_"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := sortedKeys(got); !slices.Equal(keys, []string{"DB_password"}) {
+		t.Fatalf("keys = %v, want [DB_password]", keys)
+	}
+	if _, ok := data["DB_password"]; ok || len(data) != 2 {
+		t.Fatal("input map was modified")
+	}
+}
+
+func TestKeyRewritesReportEveryCollisionInEveryRule(t *testing.T) {
+	data := map[string][]byte{"a.b": nil, "a/b": nil, "c-d": nil, "c_d": nil, "e:f": nil}
+	_, err := ApplyKeyRewrites(data, []KeyRewrite{
+		{Regexp: &RewriteRegexp{Source: `\.`, Target: "/"}}, // a.b → a/b collides
+		{Sanitize: true},
+		{Regexp: &RewriteRegexp{Source: `-`, Target: "_"}}, // c-d → c_d collides
+	})
+	if !errors.Is(err, ErrRewriteCollision) {
+		t.Fatalf("err = %v, want ErrRewriteCollision", err)
+	}
+	for _, want := range []string{`rewrite[0]: keys "a.b" and "a/b"`, `rewrite[2]: keys "c-d" and "c_d"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("missing %q in:\n%v", want, err)
+		}
+	}
+}
+
+func TestKeyRewritesReportCollisionsAndInvalidKeysTogether(t *testing.T) {
+	_, err := ApplyKeyRewrites(map[string][]byte{"x/1": nil, "x/2": nil, "y": nil},
+		[]KeyRewrite{{Regexp: &RewriteRegexp{Source: `[0-9]`, Target: ""}}})
+	if !errors.Is(err, ErrRewriteCollision) || !errors.Is(err, ErrInvalidSecretKey) {
+		t.Fatalf("err = %v, want both a collision and an invalid key", err)
+	}
+}
+
+func TestKeyRewritesRejectDotKeysAndOverlongKeys(t *testing.T) {
+	atLimit := strings.Repeat("k", maxSecretKeyLength)
+	if _, err := ApplyKeyRewrites(map[string][]byte{atLimit: nil, "..a": nil, ".env": nil}, nil); err != nil {
+		t.Fatalf("valid keys rejected: %v", err)
+	}
+
+	_, err := ApplyKeyRewrites(map[string][]byte{"/.": nil, "/..": nil, "long": nil},
+		[]KeyRewrite{{StripPrefix: "/"}, {Regexp: &RewriteRegexp{Source: `^long$`, Target: atLimit + "x"}}})
+	if !errors.Is(err, ErrInvalidSecretKey) {
+		t.Fatalf("err = %v, want ErrInvalidSecretKey", err)
+	}
+	if n := strings.Count(err.Error(), ErrInvalidSecretKey.Error()); n != 3 {
+		t.Fatalf("%d keys reported, want all three:\n%v", n, err)
+	}
+	for _, want := range []string{`".": must not be`, `"..": must not be`, "254 characters, at most 253"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("missing %q in:\n%v", want, err)
+		}
+	}
+}
+
+func TestValidateKeyRewritesReportsEveryBadRule(t *testing.T) {
+	err := ValidateKeyRewrites([]KeyRewrite{
+		{Regexp: &RewriteRegexp{Source: `(`}},
+		{},
+		{AddPrefix: "a", StripPrefix: "b"},
+		{Include: []string{"["}},
+	})
+	if !errors.Is(err, ErrInvalidRewrite) {
+		t.Fatalf("err = %v, want ErrInvalidRewrite", err)
+	}
+	for _, rule := range []string{"rewrite[0]", "rewrite[1]", "rewrite[2]", "rewrite[3]"} {
+		if !strings.Contains(err.Error(), rule) {
+			t.Errorf("%s not reported:\n%v", rule, err)
+		}
+	}
+}
+
+func TestCompileKeyRewritesCompilesEachRegexpOnce(t *testing.T) {
+	compiled, err := compileKeyRewrites([]KeyRewrite{{Regexp: &RewriteRegexp{Source: `^x(.*)$`, Target: "y$1"}}, {Sanitize: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compiled[0].re == nil || compiled[1].re != nil {
+		t.Fatal("regexp rule not precompiled, or a non-regexp rule got one")
+	}
+	if got, _ := compiled[0].apply("xabc"); got != "yabc" {
+		t.Fatalf("apply = %q, want yabc", got)
+	}
+}
+
+// Rewrite runs in the mutation function, before the template.
+func TestMutationFuncRewritesBeforeTemplating(t *testing.T) {
+	es := &ExternalSecret{Name: "db", Namespace: "default", UID: "uid-db",
+		Rewrite:  []KeyRewrite{{StripPrefix: "/prod/"}},
+		Template: &SecretTemplate{Data: map[string]string{"url": `{{ .host }}`}},
+	}
+	secret := &Secret{}
+	if err := buildMutationFunc(es, map[string][]byte{"/prod/host": []byte("db.internal")})(secret); err != nil {
+		t.Fatal(err)
+	}
+	if string(secret.Data["url"]) != "db.internal" {
+		t.Fatalf("url = %q", secret.Data["url"])
+	}
+
+	// A rewrite error fails the sync before anything is written.
+	es.Rewrite = []KeyRewrite{{Sanitize: true}}
+	fresh := &Secret{}
+	err := buildMutationFunc(es, map[string][]byte{"a/b": nil, "a_b": nil})(fresh)
+	if !errors.Is(err, ErrRewriteCollision) || len(fresh.Data) != 0 {
+		t.Fatalf("err = %v, data = %v", err, fresh.Data)
+	}
+}
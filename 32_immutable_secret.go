@@ -0,0 +1,290 @@
+// Pattern 32: Immutable Target Secrets
+//
+// Problem: spec.target.immutable asks for a Secret the API server itself
+// refuses to change. That protects against accidental edits and lets the
+// kubelet stop watching it — but the update path (Pattern 07) assumes it can
+// always write over the target. Once the provider rotates a value, every
+// reconcile fails with "field is immutable", forever, and the status says
+// nothing more useful than "could not update secret".
+//
+// Solution: Treat an immutable target as a sequence of GENERATIONS, each one
+// a Secret that is written exactly once. When provider data changes, a
+// configurable strategy decides what happens:
+//
+//   Fail     (default) keep the current Secret; Ready=False with reason
+//            SecretImmutable, so a human decides when to roll
+//   Replace  create generation N+1, swap status.binding to it, then delete
+//            generation N
+//
+//   generation 1   my-secret        ← binding
+//   generation 2   my-secret-2      ← binding after the first replace
+//   generation 3   my-secret-3      ← ...and so on
+//
+// Replace creates before it deletes, so there's never a moment with no
+// Secret. Workloads follow status.binding (or are rolled by whatever reads
+// it) — the same trick kustomize's hash-suffixed ConfigMaps use. A Secret
+// already sitting at the next generation's name is adopted or deleted only
+// if it carries this ExternalSecret's owner label and controller reference.
+//
+// REAL CODE REFERENCE:
+//   apis/externalsecrets/v1/externalsecret_types.go (ExternalSecretTarget.Immutable)
+//   externalsecret_controller.go (mutationFunc sets secret.Immutable)
+//   k8s.io/kubernetes/pkg/apis/core/validation (ValidateSecretUpdate: "field is immutable")
+
+package guide
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// Strategies and Errors
+// =============================================================================
+
+const (
+	ImmutableStrategyFail    = "Fail"    // report and wait for a human (default)
+	ImmutableStrategyReplace = "Replace" // create a new generation and rebind
+)
+
+// ReasonSecretImmutable is the Ready=False reason when Fail blocks a change.
+const ReasonSecretImmutable = "SecretImmutable"
+
+var (
+	ErrImmutableDataChanged      = errors.New("provider data changed but the target secret is immutable")
+	ErrUnknownImmutableStrategy  = errors.New("unknown immutable strategy")
+	ErrImmutableRequiresCreation = errors.New("immutable targets must be created by the controller (creationPolicy Owner or Orphan)")
+	ErrImmutableNameTooLong      = errors.New("immutable generation name exceeds 253 characters")
+)
+
+// maxSecretNameLength is the DNS-1123 subdomain limit on metadata.name.
+//
+// Real code: k8s.io/apimachinery/pkg/util/validation/validation.go (DNS1123SubdomainMaxLength)
+const maxSecretNameLength = 253
+
+func immutableStrategy(es *ExternalSecret) string {
+	if es.ImmutableStrategy == "" {
+		return ImmutableStrategyFail
+	}
+	return es.ImmutableStrategy
+}
+
+// immutableSecretName names a generation. Generation 1 keeps the plain target
+// name, so an ExternalSecret that never rotates looks like any other.
+func immutableSecretName(targetName string, generation int64) string {
+	if generation <= 1 {
+		return targetName
+	}
+	return fmt.Sprintf("%s-%d", targetName, generation)
+}
+
+// isImmutableGeneration reports whether name is one of targetName's
+// generations: the target itself or "<target>-N" with N ≥ 2. Orphan
+// detection (Pattern 06) skips them all — the live one is status.binding,
+// which it can't see.
+func isImmutableGeneration(targetName, name string) bool {
+	if name == targetName {
+		return true
+	}
+	suffix, ok := strings.CutPrefix(name, targetName+"-")
+	if !ok {
+		return false
+	}
+	n, err := strconv.ParseInt(suffix, 10, 64)
+	return err == nil && n >= 2 && immutableSecretName(targetName, n) == name
+}
+
+// =============================================================================
+// Syncing an Immutable Target
+// =============================================================================
+
+// SyncImmutableSecret is the immutable branch of applyCreationPolicy. It
+// records the outcome in status: Binding and ImmutableGeneration name the
+// Secret in use, and Ready explains a blocked change.
+func SyncImmutableSecret(ctx context.Context, clock Clock, client KubeSecretClient, es *ExternalSecret, targetName string, status *ESStatus, mutationFunc func(*Secret) error) error {
+	policy := creationPolicy(es)
+	wrap := func(err error) error {
+		return &CreationPolicyError{Policy: policy, Namespace: es.Namespace, Name: targetName, Err: err}
+	}
+	if policy != CreatePolicyOwner && policy != CreatePolicyOrphan {
+		return wrap(ErrImmutableRequiresCreation)
+	}
+	strategy := immutableStrategy(es)
+	if strategy != ImmutableStrategyFail && strategy != ImmutableStrategyReplace {
+		return wrap(fmt.Errorf("%w %q", ErrUnknownImmutableStrategy, es.ImmutableStrategy))
+	}
+
+	generation := status.ImmutableGeneration
+	if generation == 0 {
+		generation = 1
+	}
+	name := immutableSecretName(targetName, generation)
+
+	// What this generation WOULD contain if written from scratch. Comparing
+	// data hashes (Pattern 29) tells us whether the provider moved on.
+	desired := &Secret{ObjectMeta: ObjectMeta{Name: name, Namespace: es.Namespace}}
+	if err := mutationFunc(desired); err != nil {
+		return wrap(err)
+	}
+
+	existing, err := client.GetSecret(ctx, es.Namespace, name)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		if err := client.CreateSecret(ctx, desired); err != nil {
+			return wrap(err)
+		}
+		bindImmutable(clock, status, name, generation)
+		return nil
+	case err != nil:
+		return wrap(err)
+	}
+	// Binding to it, or flipping it immutable in place, both need the Secret
+	// to be ours. Orphan sets no controller reference, so a Secret another
+	// ExternalSecret controls would otherwise be taken over.
+	if err := checkControllerOf(es, &existing.ObjectMeta); err != nil {
+		return wrap(err)
+	}
+
+	switch {
+	case existing.Annotations[AnnotationDataHash] == desired.Annotations[AnnotationDataHash] && existing.Immutable:
+		bindImmutable(clock, status, name, generation) // up to date
+		return nil
+	case !existing.Immutable:
+		// A mutable Secret from before spec.target.immutable was set: one
+		// last in-place write flips it (mutable → immutable is allowed).
+		if err := updateSecret(ctx, client, existing, mutationFunc); err != nil {
+			return wrap(err)
+		}
+		bindImmutable(clock, status, name, generation)
+		return nil
+	case strategy == ImmutableStrategyFail:
+		err := fmt.Errorf("secret %s/%s (generation %d): %w; set immutableStrategy=Replace or delete the secret to roll",
+			es.Namespace, name, generation, ErrImmutableDataChanged)
+		status.Conditions = []Condition{{
+			Type:               "Ready",
+			Status:             "False",
+			Reason:             ReasonSecretImmutable,
+			Message:            err.Error(),
+			LastTransitionTime: clock.Now(),
+		}}
+		return wrap(err)
+	}
+
+	// Replace: create N+1, swap the binding, then delete N.
+	next := generation + 1
+	nextName := immutableSecretName(targetName, next)
+	if len(nextName) > maxSecretNameLength {
+		return wrap(fmt.Errorf("%w: generation %d of %q is %d characters", ErrImmutableNameTooLong, next, targetName, len(nextName)))
+	}
+	if err := createSecret(ctx, client, mutationFunc, nextName, es.Namespace); err != nil {
+		if !errors.Is(err, ErrAlreadyExists) {
+			return wrap(err)
+		}
+		// Left over from a replace that crashed before rebinding — or a
+		// Secret someone else happened to name "<target>-N+1". Only a Secret
+		// that provably belongs to this ExternalSecret is adopted or deleted.
+		leftover, getErr := client.GetSecret(ctx, es.Namespace, nextName)
+		if getErr != nil {
+			return wrap(getErr)
+		}
+		if err := checkOwnedGeneration(es, leftover); err != nil {
+			return wrap(err)
+		}
+		// Ours: adopt it if it holds the data we want; otherwise it's stale —
+		// remove it and let the next reconcile create it afresh.
+		if leftover.Annotations[AnnotationDataHash] != desired.Annotations[AnnotationDataHash] {
+			if delErr := client.DeleteSecret(ctx, leftover); delErr != nil && !errors.Is(delErr, ErrNotFound) {
+				return wrap(delErr)
+			}
+			return wrap(fmt.Errorf("removed stale generation %d, retrying: %w", next, ErrConflict))
+		}
+	}
+	bindImmutable(clock, status, nextName, next)
+
+	// The old generation goes last. If this fails, the binding already points
+	// at the new Secret and the leftover is harmless. Orphan cleanup (Pattern
+	// 06) never collects it — it skips every generation of the target — so
+	// it stays until the ExternalSecret is deleted and Kubernetes GC follows
+	// its ownerReference.
+	if err := client.DeleteSecret(ctx, existing); err != nil && !errors.Is(err, ErrNotFound) {
+		return wrap(err)
+	}
+	return nil
+}
+
+// checkOwnedGeneration proves a Secret is one of es's generations: it carries
+// es's owner label AND es is its controller. A label alone can be copied, and
+// an Orphan generation carries neither, so an Orphan leftover is never
+// touched — a human removes it. Anything else is ErrSecretIsOwned.
+func checkOwnedGeneration(es *ExternalSecret, secret *Secret) error {
+	ctrl := GetControllerOf(&secret.ObjectMeta)
+	if ctrl != nil && ctrl.UID != es.UID {
+		return ownedBy(ctrl)
+	}
+	if ctrl == nil || secret.Labels[LabelOwner] != OwnerLabelValue(es.Namespace, es.Name) {
+		return fmt.Errorf("%w: secret %s/%s is not a generation of ExternalSecret %s (uid %s)",
+			ErrSecretIsOwned, secret.Namespace, secret.Name, es.Name, es.UID)
+	}
+	return nil
+}
+
+func bindImmutable(clock Clock, status *ESStatus, name string, generation int64) {
+	markDone(clock, status, name)
+	status.ImmutableGeneration = generation
+}
+
+// =============================================================================
+// Example: A Rotation Under Each Strategy
+// =============================================================================
+
+func ExampleImmutableSecret() {
+	ctx := context.Background()
+	clock := NewFakeClock(time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC))
+	client := NewAPIServerSecretClient(NewFakeAPIServerWithClock(clock))
+
+	es := &ExternalSecret{Name: "db", Namespace: "default", UID: "uid-db", Immutable: true}
+	status := &ESStatus{}
+	v1 := map[string][]byte{"password": []byte("s3cret")}
+	v2 := map[string][]byte{"password": []byte("rotated")}
+
+	SyncImmutableSecret(ctx, clock, client, es, "db-creds", status, buildMutationFunc(es, v1))
+	secret, _ := client.GetSecret(ctx, "default", "db-creds")
+	fmt.Println("created:", status.Binding, "generation", status.ImmutableGeneration, "immutable", secret.Immutable)
+	// created: db-creds generation 1 immutable true
+
+	// The API server itself refuses an in-place change.
+	secret.Data["password"] = []byte("hunter2")
+	fmt.Println(client.UpdateSecret(ctx, secret))
+	// Secret default/db-creds: data: field is immutable when immutable is true
+
+	// Fail (default): the provider rotated, the Secret stays, Ready says why.
+	err := SyncImmutableSecret(ctx, clock, client, es, "db-creds", status, buildMutationFunc(es, v2))
+	fmt.Println(errors.Is(err, ErrImmutableDataChanged), status.Conditions[0].Reason, status.Binding)
+	// true SecretImmutable db-creds
+
+	// Replace: generation 2 is created, bound, and generation 1 deleted.
+	es.ImmutableStrategy = ImmutableStrategyReplace
+	err = SyncImmutableSecret(ctx, clock, client, es, "db-creds", status, buildMutationFunc(es, v2))
+	fmt.Println(err, status.Conditions[0].Reason, status.Binding, "generation", status.ImmutableGeneration)
+	// <nil> SecretSynced db-creds-2 generation 2
+	_, err = client.GetSecret(ctx, "default", "db-creds")
+	fmt.Println("old generation gone:", errors.Is(err, ErrNotFound)) // true
+	current, _ := client.GetSecret(ctx, "default", status.Binding)
+	fmt.Println(string(current.Data["password"])) // rotated
+
+	// Unchanged data on the next resync: nothing is written.
+	rv := current.ResourceVersion
+	SyncImmutableSecret(ctx, clock, client, es, "db-creds", status, buildMutationFunc(es, v2))
+	current, _ = client.GetSecret(ctx, "default", status.Binding)
+	fmt.Println("untouched:", current.ResourceVersion == rv) // true
+}
+
+// KEY INSIGHT:
+// "Immutable" doesn't mean the secret never changes — it means a given
+// Secret OBJECT never changes. Modelling rotation as a new object plus a
+// binding swap keeps both promises: nothing ever edits a Secret in place,
+// and consumers still get the new value, on a schedule someone chose.
@@ -0,0 +1,220 @@
+package guide
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type immutableFixture struct {
+	ctx    context.Context
+	clock  *FakeClock
+	client KubeSecretClient
+	es     *ExternalSecret
+	status *ESStatus
+}
+
+// newImmutableFixture syncs generation 1 of "db-creds" with password=v1 and
+// switches the ExternalSecret to the Replace strategy.
+func newImmutableFixture(t *testing.T) *immutableFixture {
+	t.Helper()
+	clock := NewFakeClock(testEpoch)
+	f := &immutableFixture{
+		ctx:    context.Background(),
+		clock:  clock,
+		client: NewAPIServerSecretClient(NewFakeAPIServerWithClock(clock)),
+		es:     &ExternalSecret{Name: "db", Namespace: "default", UID: "uid-db", Immutable: true},
+		status: &ESStatus{},
+	}
+	if err := f.sync("v1"); err != nil {
+		t.Fatal(err)
+	}
+	f.es.ImmutableStrategy = ImmutableStrategyReplace
+	return f
+}
+
+func (f *immutableFixture) sync(password string) error {
+	return SyncImmutableSecret(f.ctx, f.clock, f.client, f.es, "db-creds", f.status,
+		buildMutationFunc(f.es, map[string][]byte{"password": []byte(password)}))
+}
+
+// plant creates a Secret at generation 2's name, as es would have written it
+// with the given password, then lets edit change its ownership.
+func (f *immutableFixture) plant(t *testing.T, password string, edit func(*Secret)) {
+	t.Helper()
+	secret := &Secret{ObjectMeta: ObjectMeta{Name: "db-creds-2", Namespace: "default"}}
+	if err := buildMutationFunc(f.es, map[string][]byte{"password": []byte(password)})(secret); err != nil {
+		t.Fatal(err)
+	}
+	if edit != nil {
+		edit(secret)
+	}
+	if err := f.client.CreateSecret(f.ctx, secret); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImmutableReplaceRollsToNextGeneration(t *testing.T) {
+	f := newImmutableFixture(t)
+	if err := f.sync("v2"); err != nil {
+		t.Fatal(err)
+	}
+	if f.status.Binding != "db-creds-2" || f.status.ImmutableGeneration != 2 {
+		t.Fatalf("binding = %s gen %d", f.status.Binding, f.status.ImmutableGeneration)
+	}
+	if _, err := f.client.GetSecret(f.ctx, "default", "db-creds"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("generation 1 still there: %v", err)
+	}
+}
+
+func TestImmutableReplaceAdoptsOwnLeftoverWithSameData(t *testing.T) {
+	f := newImmutableFixture(t)
+	f.plant(t, "v2", nil)
+	if err := f.sync("v2"); err != nil {
+		t.Fatalf("sync = %v, want the leftover adopted", err)
+	}
+	if f.status.Binding != "db-creds-2" {
+		t.Fatalf("binding = %s", f.status.Binding)
+	}
+}
+
+func TestImmutableReplaceDeletesOwnStaleLeftover(t *testing.T) {
+	f := newImmutableFixture(t)
+	f.plant(t, "stale", nil)
+	if err := f.sync("v2"); !errors.Is(err, ErrConflict) {
+		t.Fatalf("sync = %v, want ErrConflict after removing the stale generation", err)
+	}
+	if err := f.sync("v2"); err != nil {
+		t.Fatalf("retry = %v", err)
+	}
+	secret, _ := f.client.GetSecret(f.ctx, "default", "db-creds-2")
+	if string(secret.Data["password"]) != "v2" {
+		t.Fatalf("generation 2 password = %q", secret.Data["password"])
+	}
+}
+
+func TestImmutableReplaceNeverTouchesForeignLeftover(t *testing.T) {
+	for name, edit := range map[string]func(*Secret){
+		"unowned": func(s *Secret) {
+			s.OwnerReferences = nil
+			delete(s.Labels, LabelOwner)
+		},
+		"copied label, no controller": func(s *Secret) { s.OwnerReferences = nil },
+		"controller ref, no label":    func(s *Secret) { delete(s.Labels, LabelOwner) },
+		"another controller": func(s *Secret) {
+			s.OwnerReferences = []OwnerReference{{Kind: "SealedSecret", Name: "db-creds-2", UID: "uid-sealed", Controller: true}}
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			f := newImmutableFixture(t)
+			f.plant(t, "foreign", edit)
+			before, _ := f.client.GetSecret(f.ctx, "default", "db-creds-2")
+
+			for i := 0; i < 2; i++ { // neither adopted nor deleted, on any retry
+				if err := f.sync("v2"); !errors.Is(err, ErrSecretIsOwned) {
+					t.Fatalf("sync = %v, want ErrSecretIsOwned", err)
+				}
+			}
+			after, err := f.client.GetSecret(f.ctx, "default", "db-creds-2")
+			if err != nil || after.ResourceVersion != before.ResourceVersion {
+				t.Fatalf("foreign Secret changed: %v", err)
+			}
+			if f.status.Binding != "db-creds" {
+				t.Fatalf("binding moved to %s", f.status.Binding)
+			}
+		})
+	}
+}
+
+func TestImmutableReplaceNamesTheForeignController(t *testing.T) {
+	f := newImmutableFixture(t)
+	f.plant(t, "v2", func(s *Secret) {
+		s.OwnerReferences = []OwnerReference{{Kind: "SealedSecret", Name: "db-creds-2", UID: "uid-sealed", Controller: true}}
+	})
+	if err := f.sync("v2"); err == nil || !strings.Contains(err.Error(), "SealedSecret db-creds-2") {
+		t.Fatalf("sync = %v, want the SealedSecret named", err)
+	}
+}
+
+func TestImmutableReplaceRejectsOverlongGenerationName(t *testing.T) {
+	clock := NewFakeClock(testEpoch)
+	client := NewAPIServerSecretClient(NewFakeAPIServerWithClock(clock))
+	es := &ExternalSecret{Name: "db", Namespace: "default", UID: "uid-db", Immutable: true, ImmutableStrategy: ImmutableStrategyReplace}
+	target := strings.Repeat("a", maxSecretNameLength-1)
+	status := &ESStatus{}
+	sync := func(password string) error {
+		return SyncImmutableSecret(context.Background(), clock, client, es, target, status,
+			buildMutationFunc(es, map[string][]byte{"password": []byte(password)}))
+	}
+
+	if err := sync("v1"); err != nil {
+		t.Fatalf("generation 1 = %v", err)
+	}
+	if err := sync("v2"); !errors.Is(err, ErrImmutableNameTooLong) {
+		t.Fatalf("generation 2 = %v, want ErrImmutableNameTooLong", err)
+	}
+	if status.Binding != target {
+		t.Fatalf("binding moved to %q", status.Binding)
+	}
+}
+
+// Every generation carries the owner label, so orphan detection used to
+// delete the live binding right after a Replace.
+func TestOrphanSweepAfterReplaceKeepsTheBinding(t *testing.T) {
+	f := newImmutableFixture(t)
+	if err := f.sync("v2"); err != nil {
+		t.Fatal(err)
+	}
+	mgr := NewOwnershipManager(f.client)
+	if deleted, err := mgr.DeleteOrphans(f.ctx, f.es, "db-creds"); err != nil || len(deleted) != 0 {
+		t.Fatalf("DeleteOrphans = %v, %v; want nothing deleted", deleted, err)
+	}
+	if _, err := f.client.GetSecret(f.ctx, "default", f.status.Binding); err != nil {
+		t.Fatalf("binding %s gone: %v", f.status.Binding, err)
+	}
+
+	// A rename still collects every generation of the old target.
+	if deleted, _ := mgr.DeleteOrphans(f.ctx, f.es, "app-creds"); len(deleted) != 1 || deleted[0] != "db-creds-2" {
+		t.Fatalf("DeleteOrphans after rename = %v, want [db-creds-2]", deleted)
+	}
+}
+
+func TestIsImmutableGeneration(t *testing.T) {
+	for name, want := range map[string]bool{
+		"db": true, "db-2": true, "db-10": true,
+		"db-1": false, "db-02": false, "db-x": false, "db-2-3": false, "db2": false, "dbx-2": false,
+	} {
+		if got := isImmutableGeneration("db", name); got != want {
+			t.Errorf("isImmutableGeneration(db, %q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+// Flipping a mutable Secret immutable in place is a write: under Orphan,
+// which sets no controller reference, it must still refuse a Secret another
+// ExternalSecret controls.
+func TestImmutableInPlaceFlipRefusesAnotherControllersSecret(t *testing.T) {
+	ctx := context.Background()
+	clock := NewFakeClock(testEpoch)
+	client := NewAPIServerSecretClient(NewFakeAPIServerWithClock(clock))
+	owner := &ExternalSecret{Name: "other", Namespace: "default", UID: "uid-other"}
+	if err := applyCreationPolicy(ctx, client, owner, "db-creds", buildMutationFunc(owner, map[string][]byte{"password": []byte("theirs")})); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := client.GetSecret(ctx, "default", "db-creds")
+
+	es := &ExternalSecret{Name: "db", Namespace: "default", UID: "uid-db", CreationPolicy: CreatePolicyOrphan, Immutable: true}
+	status := &ESStatus{}
+	err := SyncImmutableSecret(ctx, clock, client, es, "db-creds", status, buildMutationFunc(es, map[string][]byte{"password": []byte("mine")}))
+	if !errors.Is(err, ErrSecretIsOwned) || !strings.Contains(err.Error(), "ExternalSecret other") {
+		t.Fatalf("sync = %v, want ErrSecretIsOwned naming the other ExternalSecret", err)
+	}
+	after, _ := client.GetSecret(ctx, "default", "db-creds")
+	if after.ResourceVersion != before.ResourceVersion || after.Immutable {
+		t.Fatal("the other ExternalSecret's Secret was written")
+	}
+	if status.Binding != "" {
+		t.Fatalf("bound to %s", status.Binding)
+	}
+}
@@ -0,0 +1,208 @@
+// Pattern 33: Three-Way Merge for creationPolicy=Merge
+//
+// Problem: A Merge target (Pattern 07) belongs to someone else: another
+// tool, a Helm chart, a human with kubectl. The mutation function writes our
+// keys over it and leaves every other key alone. That's right for adding and
+// changing keys, but wrong for REMOVING them. When the provider drops
+// "old-token", the Secret keeps it forever, because from the Secret alone
+// there's no telling "a key we wrote last time" from "a key another tool owns".
+//
+// Solution: The same answer kubectl apply gives: remember what we applied last
+// time. Each ExternalSecret records the keys it wrote in an annotation on the
+// target, and every sync is a three-way merge:
+//
+//   last applied   what we wrote last time     (annotation)
+//   desired        what the provider has now   (provider data / template)
+//   live           what the Secret holds now   (everyone's keys)
+//
+//   key in last applied, not in desired  → delete (we put it there; it's gone)
+//   key in desired                       → write (and record as ours)
+//   key in neither                       → untouched (someone else's)
+//
+// Recording is what licenses a later delete, so only keys we brought into
+// the Secret are recorded. A desired key that was already there — the
+// placeholder a Helm chart ships, say — is written but never recorded, and
+// so never deleted. A desired key another ExternalSecret's record claims is a
+// conflict: the two would overwrite each other on every sync, and whichever
+// dropped it would delete the other's value, so the sync is refused.
+//
+// The annotation is per ExternalSecret (keyed by the owner hash from Pattern
+// 06), so two ExternalSecrets merging into one Secret never delete each
+// other's keys.
+//
+// REAL CODE REFERENCE:
+//   k8s.io/kubectl/pkg/cmd/apply (kubectl.kubernetes.io/last-applied-configuration)
+//   k8s.io/apimachinery/pkg/util/strategicpatch (CreateThreeWayMergePatch)
+//   externalsecret_controller.go (mutationFunc, CreatePolicyMerge branch)
+
+package guide
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// =============================================================================
+// The Last-Applied Record
+// =============================================================================
+
+// AnnotationLastAppliedKeysPrefix + OwnerLabelValue(namespace, name) names the annotation
+// holding a JSON array of the data keys that ExternalSecret last wrote.
+const AnnotationLastAppliedKeysPrefix = ControllerMetadataPrefix + "last-applied-keys."
+
+func lastAppliedKeysAnnotation(es *ExternalSecret) string {
+	return AnnotationLastAppliedKeysPrefix + OwnerLabelValue(es.Namespace, es.Name)
+}
+
+// lastAppliedKeys reads the record. A missing or unreadable annotation means
+// "we own nothing yet": the safe failure is deleting too little, never a
+// foreign key.
+func lastAppliedKeys(secret *Secret, es *ExternalSecret) []string {
+	raw, ok := secret.Annotations[lastAppliedKeysAnnotation(es)]
+	if !ok {
+		return nil
+	}
+	var keys []string
+	if err := json.Unmarshal([]byte(raw), &keys); err != nil {
+		return nil
+	}
+	return keys
+}
+
+// =============================================================================
+// The Merge
+// =============================================================================
+
+// ErrMergeKeyConflict means a desired key is recorded by another
+// ExternalSecret merging into the same Secret. Like ErrSecretIsOwned, it's
+// permanent until a human changes one of the two.
+var ErrMergeKeyConflict = errors.New("key is managed by another ExternalSecret")
+
+// claimedKeys maps every key in another ExternalSecret's last-applied record
+// to that record's annotation.
+func claimedKeys(secret *Secret, es *ExternalSecret) map[string]string {
+	claimed := make(map[string]string)
+	for annotation, raw := range secret.Annotations {
+		if !strings.HasPrefix(annotation, AnnotationLastAppliedKeysPrefix) || annotation == lastAppliedKeysAnnotation(es) {
+			continue
+		}
+		var keys []string
+		if json.Unmarshal([]byte(raw), &keys) != nil {
+			continue
+		}
+		for _, k := range keys {
+			claimed[k] = annotation
+		}
+	}
+	return claimed
+}
+
+// threeWayMergeData applies desired over secret.Data, deletes keys this
+// ExternalSecret applied last time but no longer wants, and updates the
+// record. It returns the deleted keys, sorted. If another ExternalSecret
+// claims a desired key, it returns ErrMergeKeyConflict for every such key
+// and changes nothing.
+func threeWayMergeData(secret *Secret, es *ExternalSecret, desired map[string][]byte) ([]string, error) {
+	ours := make(map[string]bool)
+	for _, k := range lastAppliedKeys(secret, es) {
+		ours[k] = true
+	}
+	claimed := claimedKeys(secret, es)
+
+	var errs error
+	for _, k := range sortedKeys(desired) {
+		if by, ok := claimed[k]; ok && !ours[k] {
+			errs = errors.Join(errs, fmt.Errorf("%w: %q is recorded in %s", ErrMergeKeyConflict, k, by))
+		}
+	}
+	if errs != nil {
+		return nil, errs
+	}
+
+	var removed []string
+	for k := range ours {
+		if _, still := desired[k]; still {
+			continue
+		}
+		// A record written before conflicts were refused can overlap
+		// another's; a key both claim is left for the other to remove.
+		if _, other := claimed[k]; other {
+			continue
+		}
+		if _, live := secret.Data[k]; live {
+			delete(secret.Data, k)
+			removed = append(removed, k)
+		}
+	}
+	sort.Strings(removed)
+
+	record := make([]string, 0, len(desired))
+	for _, k := range sortedKeys(desired) {
+		if _, existed := secret.Data[k]; ours[k] || !existed {
+			record = append(record, k)
+		}
+		secret.Data[k] = desired[k]
+	}
+	raw, _ := json.Marshal(record) // []string always marshals
+	secret.Annotations[lastAppliedKeysAnnotation(es)] = string(raw)
+	return removed, nil
+}
+
+// =============================================================================
+// Example: A Secret Shared With Manual Tooling
+// =============================================================================
+
+func ExampleThreeWayMerge() {
+	ctx := context.Background()
+	client := NewAPIServerSecretClient(NewFakeAPIServer())
+
+	// The platform team's Secret: one key they maintain by hand.
+	client.CreateSecret(ctx, &Secret{
+		ObjectMeta: ObjectMeta{Name: "app-config", Namespace: "default"},
+		Data:       map[string][]byte{"api-url": []byte("https://example.com")},
+	})
+
+	es := &ExternalSecret{Name: "tokens", Namespace: "default", UID: "uid-tokens", CreationPolicy: CreatePolicyMerge}
+	sync := func(providerData map[string][]byte) {
+		if err := applyCreationPolicy(ctx, client, es, "app-config", buildMutationFunc(es, providerData)); err != nil {
+			fmt.Println("error:", err)
+		}
+		secret, _ := client.GetSecret(ctx, "default", "app-config")
+		fmt.Printf("keys=%v ours=%v\n", dataKeys(secret), lastAppliedKeys(secret, es))
+	}
+
+	sync(map[string][]byte{"token": []byte("t1"), "old-token": []byte("t0")})
+	// keys=[api-url old-token token] ours=[old-token token]
+
+	// The team adds a key by hand; it isn't in our record.
+	secret, _ := client.GetSecret(ctx, "default", "app-config")
+	secret.Data["feature-flags"] = []byte("beta=on")
+	client.UpdateSecret(ctx, secret)
+
+	// The provider drops old-token. Only old-token goes; both manual keys stay.
+	sync(map[string][]byte{"token": []byte("t2")})
+	// keys=[api-url feature-flags token] ours=[token]
+
+	// A second ExternalSecret merging into the same Secret has its own record.
+	other := &ExternalSecret{Name: "certs", Namespace: "default", UID: "uid-certs", CreationPolicy: CreatePolicyMerge}
+	applyCreationPolicy(ctx, client, other, "app-config", buildMutationFunc(other, map[string][]byte{"tls.crt": []byte("...")}))
+	sync(map[string][]byte{"token": []byte("t3")})
+	// keys=[api-url feature-flags tls.crt token] ours=[token]
+
+	// If the second one also wants "token", the first one's record claims
+	// it: the sync is refused rather than the two overwriting each other.
+	err := applyCreationPolicy(ctx, client, other, "app-config",
+		buildMutationFunc(other, map[string][]byte{"tls.crt": []byte("..."), "token": []byte("other")}))
+	fmt.Println("conflict:", errors.Is(err, ErrMergeKeyConflict)) // conflict: true
+}
+
+// KEY INSIGHT:
+// Two-way merge (desired vs live) can add and overwrite but can never safely
+// delete: every unknown key might be someone else's. The third input — what
+// WE wrote last time — is what turns "unknown" into "ours, now removed" or
+// "theirs, leave it". Storing it on the object itself keeps the controller
+// stateless across restarts.
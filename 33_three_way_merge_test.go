@@ -0,0 +1,143 @@
+package guide
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type mergeFixture struct {
+	t      *testing.T
+	ctx    context.Context
+	client KubeSecretClient
+}
+
+// newMergeFixture creates "shared" holding the keys in preexisting.
+func newMergeFixture(t *testing.T, preexisting map[string][]byte) *mergeFixture {
+	f := &mergeFixture{t: t, ctx: context.Background(), client: NewAPIServerSecretClient(NewFakeAPIServer())}
+	if err := f.client.CreateSecret(f.ctx, &Secret{ObjectMeta: ObjectMeta{Name: "shared", Namespace: "default"}, Data: preexisting}); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func mergeES(name string) *ExternalSecret {
+	return &ExternalSecret{Name: name, Namespace: "default", UID: "uid-" + name, CreationPolicy: CreatePolicyMerge}
+}
+
+func (f *mergeFixture) sync(es *ExternalSecret, data map[string]string) error {
+	desired := make(map[string][]byte, len(data))
+	for k, v := range data {
+		desired[k] = []byte(v)
+	}
+	return applyCreationPolicy(f.ctx, f.client, es, "shared", buildMutationFunc(es, desired))
+}
+
+func (f *mergeFixture) mustSync(es *ExternalSecret, data map[string]string) {
+	f.t.Helper()
+	if err := f.sync(es, data); err != nil {
+		f.t.Fatal(err)
+	}
+}
+
+func (f *mergeFixture) secret() *Secret {
+	f.t.Helper()
+	secret, err := f.client.GetSecret(f.ctx, "default", "shared")
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return secret
+}
+
+func TestMergeRemovesOnlyKeysItRecorded(t *testing.T) {
+	f := newMergeFixture(t, map[string][]byte{"api-url": []byte("https://example.com")})
+	es := mergeES("tokens")
+
+	f.mustSync(es, map[string]string{"token": "t1", "old-token": "t0"})
+	if got := lastAppliedKeys(f.secret(), es); !slices.Equal(got, []string{"old-token", "token"}) {
+		t.Fatalf("record = %v", got)
+	}
+	f.mustSync(es, map[string]string{"token": "t2"})
+	secret := f.secret()
+	if got := dataKeys(secret); !slices.Equal(got, []string{"api-url", "token"}) {
+		t.Fatalf("keys = %v, want [api-url token]", got)
+	}
+	if !isTargetSecretValid(secret, es) {
+		t.Fatal("freshly merged Secret fails its own hash")
+	}
+}
+
+// A key that was in the Secret before we first wrote it is overwritten but
+// never recorded, so dropping it from the provider doesn't delete it.
+func TestMergeNeverRecordsKeysThatWereAlreadyThere(t *testing.T) {
+	f := newMergeFixture(t, map[string][]byte{"password": []byte("placeholder")})
+	es := mergeES("db")
+
+	f.mustSync(es, map[string]string{"password": "s3cret", "user": "app"})
+	secret := f.secret()
+	if string(secret.Data["password"]) != "s3cret" {
+		t.Fatalf("password = %q, want it overwritten", secret.Data["password"])
+	}
+	if got := lastAppliedKeys(secret, es); !slices.Equal(got, []string{"user"}) {
+		t.Fatalf("record = %v, want only the key we added", got)
+	}
+
+	f.mustSync(es, map[string]string{"user": "app"}) // provider drops password
+	if _, ok := f.secret().Data["password"]; !ok {
+		t.Fatal("a key that predates us was deleted")
+	}
+	if got := lastAppliedKeys(f.secret(), es); !slices.Equal(got, []string{"user"}) {
+		t.Fatalf("record = %v", got)
+	}
+}
+
+func TestMergeRefusesKeysAnotherExternalSecretRecorded(t *testing.T) {
+	f := newMergeFixture(t, nil)
+	first, second := mergeES("first"), mergeES("second")
+	f.mustSync(first, map[string]string{"token": "a", "url": "u"})
+	before := f.secret()
+
+	err := f.sync(second, map[string]string{"token": "b", "url": "v", "extra": "x"})
+	if !errors.Is(err, ErrMergeKeyConflict) {
+		t.Fatalf("err = %v, want ErrMergeKeyConflict", err)
+	}
+	for _, key := range []string{`"token"`, `"url"`} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("conflict on %s not reported:\n%v", key, err)
+		}
+	}
+	after := f.secret()
+	if after.ResourceVersion != before.ResourceVersion {
+		t.Fatal("the refused sync wrote the Secret")
+	}
+	if string(after.Data["token"]) != "a" {
+		t.Fatalf("token = %q, want the first ExternalSecret's value", after.Data["token"])
+	}
+
+	// Disjoint keys are fine, and each one's drop removes only its own.
+	f.mustSync(second, map[string]string{"extra": "x"})
+	f.mustSync(first, map[string]string{"url": "u"})
+	if got := dataKeys(f.secret()); !slices.Equal(got, []string{"extra", "url"}) {
+		t.Fatalf("keys = %v, want [extra url]", got)
+	}
+}
+
+// Records written before conflicts were refused can overlap. A key both
+// claim survives either one dropping it.
+func TestMergeLeavesKeysBothRecordsClaim(t *testing.T) {
+	f := newMergeFixture(t, nil)
+	first, second := mergeES("first"), mergeES("second")
+	f.mustSync(first, map[string]string{"token": "a"})
+	secret := f.secret()
+	secret.Annotations[lastAppliedKeysAnnotation(second)] = `["token"]`
+	if err := f.client.UpdateSecret(f.ctx, secret); err != nil {
+		t.Fatal(err)
+	}
+
+	f.mustSync(first, map[string]string{"other": "o"})
+	if _, ok := f.secret().Data["token"]; !ok {
+		t.Fatal("a key another ExternalSecret also records was deleted")
+	}
+}
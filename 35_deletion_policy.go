@@ -0,0 +1,251 @@
+// Pattern 35: Deletion Policy Engine
+//
+// Problem: "What happens to the target Secret?" has to be answered in two
+// places — when the ExternalSecret is deleted (Pattern 03's finalizer) and
+// when the provider says the source secret is gone (NoSecretErr, advanced
+// Pattern 17). Each call site grew its own answer: the finalizer knew only
+// "Delete" vs anything else, and the NoSecretErr branch just printed. So
+// deletionPolicy "Merge", which the webhook (advanced Pattern 11) accepts,
+// did nothing anywhere.
+//
+// Solution: One executor, one matrix, used by both triggers:
+//
+//   policy   Secret            data                       ownership
+//   Delete   deleted           —                          —
+//   Retain   kept              untouched                  ownerRef + owner labels stripped
+//   Merge    kept              our keys removed           ownerRef + owner labels stripped
+//
+// "Our keys" are the last-applied record of a Merge target (Pattern 33), or
+// every key of a Secret this ExternalSecret manages. Stripping ownership is
+// what makes Retain real: a Secret that keeps its ownerReference is deleted
+// by the garbage collector (Pattern 28) the moment the ExternalSecret goes.
+//
+// REAL CODE REFERENCE:
+//   apis/externalsecrets/v1/externalsecret_types.go (ExternalSecretDeletionPolicy)
+//   externalsecret_controller.go (deleteOrphanedSecrets, the NoSecretErr branch)
+
+package guide
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// =============================================================================
+// Policies and Triggers
+// =============================================================================
+
+const (
+	DeletionPolicyDelete = "Delete"
+	DeletionPolicyRetain = "Retain" // default
+	DeletionPolicyMerge  = "Merge"
+)
+
+type DeletionTrigger string
+
+const (
+	TriggerFinalize      DeletionTrigger = "Finalize"      // the ExternalSecret is being deleted
+	TriggerSourceDeleted DeletionTrigger = "SourceDeleted" // the provider returned NoSecretErr
+)
+
+var (
+	ErrUnknownDeletionPolicy = errors.New("unknown deletionPolicy")
+	ErrDeleteMergeTarget     = errors.New("deletionPolicy=Delete never deletes a creationPolicy=Merge target: it isn't ours")
+)
+
+// DeletionOutcome says what the executor did, for status and logs.
+type DeletionOutcome struct {
+	Trigger          DeletionTrigger
+	Policy           string
+	Deleted          bool
+	RemovedKeys      []string
+	OwnershipRemoved bool
+}
+
+func (o DeletionOutcome) String() string {
+	switch {
+	case o.Deleted:
+		return fmt.Sprintf("%s/%s: secret deleted", o.Trigger, o.Policy)
+	case o.Policy == DeletionPolicyMerge:
+		return fmt.Sprintf("%s/%s: removed keys %v, ownership removed=%v", o.Trigger, o.Policy, o.RemovedKeys, o.OwnershipRemoved)
+	}
+	return fmt.Sprintf("%s/%s: secret kept, ownership removed=%v", o.Trigger, o.Policy, o.OwnershipRemoved)
+}
+
+func deletionPolicy(policy string) string {
+	if policy == "" {
+		return DeletionPolicyRetain
+	}
+	return policy
+}
+
+// =============================================================================
+// The Executor
+// =============================================================================
+
+type DeletionPolicyExecutor struct {
+	Client KubeSecretClient
+}
+
+func NewDeletionPolicyExecutor(client KubeSecretClient) *DeletionPolicyExecutor {
+	return &DeletionPolicyExecutor{Client: client}
+}
+
+// Apply runs policy against es's target Secret. A missing Secret is
+// success: both triggers retry, and the second attempt must be a no-op.
+// A Secret controlled by a different object is never touched.
+func (e *DeletionPolicyExecutor) Apply(ctx context.Context, trigger DeletionTrigger, es *ExternalSecret, targetName, policy string) (DeletionOutcome, error) {
+	policy = deletionPolicy(policy)
+	out := DeletionOutcome{Trigger: trigger, Policy: policy}
+	switch policy {
+	case DeletionPolicyDelete, DeletionPolicyRetain, DeletionPolicyMerge:
+	default:
+		return out, fmt.Errorf("%w %q", ErrUnknownDeletionPolicy, policy)
+	}
+
+	secret, err := e.Client.GetSecret(ctx, es.Namespace, targetName)
+	if errors.Is(err, ErrNotFound) {
+		return out, nil
+	}
+	if err != nil {
+		return out, err
+	}
+	if ctrl := GetControllerOf(&secret.ObjectMeta); ctrl != nil && ctrl.UID != es.UID {
+		return out, fmt.Errorf("secret %s/%s: %w", es.Namespace, targetName, ownedBy(ctrl))
+	}
+
+	if policy == DeletionPolicyDelete {
+		if creationPolicy(es) == CreatePolicyMerge {
+			return out, ErrDeleteMergeTarget
+		}
+		if err := e.Client.DeleteSecret(ctx, secret); err != nil && !errors.Is(err, ErrNotFound) {
+			return out, err
+		}
+		out.Deleted = true
+		return out, nil
+	}
+
+	if policy == DeletionPolicyMerge {
+		out.RemovedKeys = contributedKeys(secret, es)
+		for _, k := range out.RemovedKeys {
+			delete(secret.Data, k)
+		}
+	}
+	out.OwnershipRemoved = stripOwnership(secret, es)
+
+	if len(out.RemovedKeys) == 0 && !out.OwnershipRemoved {
+		return out, nil // nothing to write
+	}
+	if len(out.RemovedKeys) > 0 && creationPolicy(es) != CreatePolicyMerge {
+		// Keep the data hash honest, or refresh gating (Pattern 08) would see
+		// a "tampered" Secret that nobody reconciles any more. A Merge
+		// target's per-ExternalSecret hash went with stripOwnership.
+		secret.Annotations[AnnotationDataHash] = DataHash(hashedFieldsOf(secret, es))
+	}
+	return out, e.Client.UpdateSecret(ctx, secret)
+}
+
+// contributedKeys is what es wrote into secret, sorted: the last-applied
+// record when there is one (Merge targets), otherwise every key of a Secret
+// es manages, otherwise nothing.
+func contributedKeys(secret *Secret, es *ExternalSecret) []string {
+	var keys []string
+	if recorded := lastAppliedKeys(secret, es); recorded != nil {
+		for _, k := range recorded {
+			if _, ok := secret.Data[k]; ok {
+				keys = append(keys, k)
+			}
+		}
+		slices.Sort(keys)
+		return keys
+	}
+	if secret.Labels[LabelOwner] == OwnerLabelValue(es.Namespace, es.Name) {
+		return dataKeys(secret)
+	}
+	return nil
+}
+
+// stripOwnership removes es's ownerReference, owner labels, last-applied
+// record and Merge data hash. Reports whether anything changed.
+func stripOwnership(secret *Secret, es *ExternalSecret) bool {
+	changed := false
+	refs := secret.OwnerReferences[:0]
+	for _, ref := range secret.OwnerReferences {
+		if ref.UID == es.UID {
+			changed = true
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	secret.OwnerReferences = refs
+
+	if secret.Labels[LabelOwner] == OwnerLabelValue(es.Namespace, es.Name) {
+		delete(secret.Labels, LabelOwner)
+		delete(secret.Labels, LabelManaged)
+		changed = true
+	}
+	if secret.Annotations == nil {
+		secret.Annotations = make(map[string]string)
+	}
+	for _, key := range []string{lastAppliedKeysAnnotation(es), AnnotationMergeDataHashPrefix + OwnerLabelValue(es.Namespace, es.Name)} {
+		if _, ok := secret.Annotations[key]; ok {
+			delete(secret.Annotations, key)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// =============================================================================
+// Example: The Matrix, From Both Triggers
+// =============================================================================
+
+func ExampleDeletionPolicies() {
+	ctx := context.Background()
+	providerData := map[string][]byte{"password": []byte("s3cret")}
+
+	for _, policy := range []string{DeletionPolicyDelete, DeletionPolicyRetain, DeletionPolicyMerge} {
+		for _, trigger := range []DeletionTrigger{TriggerFinalize, TriggerSourceDeleted} {
+			client := NewAPIServerSecretClient(NewFakeAPIServer())
+			es := &ExternalSecret{Name: "db", Namespace: "default", UID: "uid-db"}
+			applyCreationPolicy(ctx, client, es, "db-creds", buildMutationFunc(es, providerData))
+
+			out, err := NewDeletionPolicyExecutor(client).Apply(ctx, trigger, es, "db-creds", policy)
+			after, getErr := client.GetSecret(ctx, "default", "db-creds")
+			if getErr != nil {
+				fmt.Printf("%-40s err=%v secret gone\n", out, err)
+				continue
+			}
+			fmt.Printf("%-40s err=%v keys=%v ownerRefs=%d\n", out, err, dataKeys(after), len(after.OwnerReferences))
+		}
+	}
+	// Finalize/Delete: secret deleted          err=<nil> secret gone
+	// SourceDeleted/Delete: secret deleted     err=<nil> secret gone
+	// Finalize/Retain: secret kept, ownership removed=true err=<nil> keys=[password] ownerRefs=0
+	// SourceDeleted/Retain: secret kept, ownership removed=true err=<nil> keys=[password] ownerRefs=0
+	// Finalize/Merge: removed keys [password], ownership removed=true err=<nil> keys=[] ownerRefs=0
+	// SourceDeleted/Merge: removed keys [password], ownership removed=true err=<nil> keys=[] ownerRefs=0
+
+	// On a shared Secret, Merge removes exactly the keys Pattern 33 recorded.
+	client := NewAPIServerSecretClient(NewFakeAPIServer())
+	client.CreateSecret(ctx, &Secret{
+		ObjectMeta: ObjectMeta{Name: "app-config", Namespace: "default"},
+		Data:       map[string][]byte{"api-url": []byte("https://example.com")},
+	})
+	es := &ExternalSecret{Name: "tokens", Namespace: "default", UID: "uid-tokens", CreationPolicy: CreatePolicyMerge}
+	applyCreationPolicy(ctx, client, es, "app-config", buildMutationFunc(es, providerData))
+	out, _ := NewDeletionPolicyExecutor(client).Apply(ctx, TriggerFinalize, es, "app-config", DeletionPolicyMerge)
+	shared, _ := client.GetSecret(ctx, "default", "app-config")
+	fmt.Println(out.RemovedKeys, dataKeys(shared)) // [password] [api-url]
+
+	// Delete on someone else's Secret is refused, not attempted.
+	_, err := NewDeletionPolicyExecutor(client).Apply(ctx, TriggerFinalize, es, "app-config", DeletionPolicyDelete)
+	fmt.Println(errors.Is(err, ErrDeleteMergeTarget)) // true
+}
+
+// KEY INSIGHT:
+// A policy applied from two places must be implemented in one. Routing both
+// the finalizer and the NoSecretErr branch through the same executor means
+// "Retain" can't mean "keep" in one and "let the GC have it" in the other.
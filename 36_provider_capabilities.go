@@ -0,0 +1,153 @@
+// Pattern 36: Provider Capabilities
+//
+// Problem: The provider interfaces (Patterns 02 and 05) say nothing about
+// what a provider can actually do. Every provider has GetSecretMap in its
+// method set — some just return "not implemented". So an ExternalSecret using
+// dataFrom.find against a provider that can't list secrets is accepted by the
+// webhook, and fails on every reconcile, after authenticating, with an error
+// that depends on which provider it was.
+//
+// Solution: Each provider declares a typed capability set. Validation maps
+// every field of an ExternalSecret to the capability it needs and checks them
+// all up front — no client, no network — reporting every unsupported field
+// at once (errors.Join, as in advanced Pattern 11):
+//
+//   spec.data[i]                   → GetSecret
+//   spec.dataFrom[i].extract       → GetSecretMap
+//   spec.dataFrom[i].find.name     → FindByName
+//   spec.dataFrom[i].find.tags     → FindByTag
+//   PushSecret                     → PushSecret
+//
+// REAL CODE REFERENCE:
+//   apis/externalsecrets/v1/provider.go (Provider.Capabilities)
+//   apis/externalsecrets/v1/secretstore_types.go (SecretStoreCapabilities: ReadOnly, WriteOnly, ReadWrite)
+
+package guide
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// =============================================================================
+// The Capability Set
+// =============================================================================
+
+type Capabilities uint8
+
+const (
+	CapGetSecret    Capabilities = 1 << iota // single value by key
+	CapGetSecretMap                          // key → map (JSON object, KV path)
+	CapFindByName                            // list secrets, filter by name regexp
+	CapFindByTag                             // list secrets, filter by tags/labels
+	CapPushSecret                            // write secrets to the provider
+
+	CapReadOnly = CapGetSecret | CapGetSecretMap | CapFindByName | CapFindByTag
+	CapAll      = CapReadOnly | CapPushSecret
+)
+
+var capabilityNames = []struct {
+	c    Capabilities
+	name string
+}{
+	{CapGetSecret, "GetSecret"},
+	{CapGetSecretMap, "GetSecretMap"},
+	{CapFindByName, "FindByName"},
+	{CapFindByTag, "FindByTag"},
+	{CapPushSecret, "PushSecret"},
+}
+
+// Has reports whether every capability in want is present.
+func (c Capabilities) Has(want Capabilities) bool { return c&want == want }
+
+// ReadOnly is ESO's SecretStoreCapabilities "ReadOnly": nothing can be pushed.
+func (c Capabilities) ReadOnly() bool { return !c.Has(CapPushSecret) }
+
+func (c Capabilities) String() string {
+	var names []string
+	for _, n := range capabilityNames {
+		if c.Has(n.c) {
+			names = append(names, n.name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, "|")
+}
+
+// =============================================================================
+// Validating Usage Against Capabilities
+// =============================================================================
+
+var ErrUnsupportedOperation = errors.New("operation not supported by provider")
+
+// CapabilityRequirement is one field of an ExternalSecret (or PushSecret)
+// and the capability it needs.
+type CapabilityRequirement struct {
+	Field string // e.g. "spec.dataFrom[0].find.tags"
+	Needs Capabilities
+}
+
+// CapabilityProvider is anything that declares capabilities: both provider
+// interfaces embed it.
+type CapabilityProvider interface {
+	Capabilities() Capabilities
+}
+
+// ValidateCapabilities checks every requirement against the provider's
+// declared set. It never creates a client, so it's safe in a webhook.
+func ValidateCapabilities(providerName string, provider CapabilityProvider, reqs []CapabilityRequirement) error {
+	caps := provider.Capabilities()
+	var errs error
+	for _, r := range reqs {
+		if !caps.Has(r.Needs) {
+			errs = errors.Join(errs, fmt.Errorf("%s: %w: %q needs %s (supports %s)",
+				r.Field, ErrUnsupportedOperation, providerName, r.Needs&^caps, caps))
+		}
+	}
+	return errs
+}
+
+// ValidateCapabilitiesByName looks the provider up in the registry
+// (Pattern 02) first.
+func ValidateCapabilitiesByName(providerName string, reqs []CapabilityRequirement) error {
+	provider, ok := GetProviderByName(providerName)
+	if !ok {
+		return fmt.Errorf("unknown provider: %s", providerName)
+	}
+	return ValidateCapabilities(providerName, provider, reqs)
+}
+
+// =============================================================================
+// Example: dataFrom.find Against a Provider That Can't List by Tag
+// =============================================================================
+
+func ExampleProviderCapabilities() {
+	for _, name := range []string{"aws", "vault"} {
+		p, _ := GetProviderByName(name)
+		fmt.Printf("%-5s %s read-only=%v\n", name, p.Capabilities(), p.Capabilities().ReadOnly())
+	}
+	// aws   GetSecret|GetSecretMap|FindByName|FindByTag|PushSecret read-only=false
+	// vault GetSecret|GetSecretMap|FindByName read-only=true
+
+	// What an ExternalSecret with one data entry and two dataFrom entries needs.
+	reqs := []CapabilityRequirement{
+		{Field: "spec.data[0]", Needs: CapGetSecret},
+		{Field: "spec.dataFrom[0].find.tags", Needs: CapFindByTag},
+		{Field: "spec.pushSecret", Needs: CapPushSecret},
+	}
+	fmt.Println(ValidateCapabilitiesByName("aws", reqs)) // <nil>
+
+	err := ValidateCapabilitiesByName("vault", reqs)
+	fmt.Println(err)
+	// spec.dataFrom[0].find.tags: operation not supported by provider: "vault" needs FindByTag (supports GetSecret|GetSecretMap|FindByName)
+	// spec.pushSecret: operation not supported by provider: "vault" needs PushSecret (supports GetSecret|GetSecretMap|FindByName)
+	fmt.Println(errors.Is(err, ErrUnsupportedOperation)) // true
+}
+
+// KEY INSIGHT:
+// A method set says what a type CAN be called with; a capability set says
+// what it will actually do. Declaring capabilities turns "fails at runtime,
+// differently per provider" into "rejected at admission, the same way for all".
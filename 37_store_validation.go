@@ -0,0 +1,245 @@
+// Pattern 37: Store Validation (ValidateStore)
+//
+// Problem: Resolving a store's provider (Pattern 02) checks only that exactly one
+// provider key is present and that it's registered. A SecretStore with no
+// AWS region, or a Vault server of "vault.example.com" (no scheme), is
+// accepted, reports nothing, and fails inside NewClient on the first
+// reconcile of every ExternalSecret that uses it — one confusing error per
+// ExternalSecret instead of one clear error on the store.
+//
+// Solution: Each provider validates its own config statically — no client,
+// no network — through ValidateStore on the provider interface. One
+// validation path runs it at both points a store passes through:
+//
+//   admission   ValidateSecretStore    rejects the create/update outright
+//   reconcile   SecretStoreReconciler  sets status.conditions[Ready]
+//
+// Every problem is collected with errors.Join (as in advanced Pattern 11),
+// so a store with three mistakes is fixed in one edit, not three.
+//
+// REAL CODE REFERENCE:
+//   apis/externalsecrets/v1/provider.go (Provider.ValidateStore)
+//   pkg/controllers/secretstore/common.go (validateStore, ReasonInvalidProviderConfig)
+//   apis/externalsecrets/v1/secretstore_validator.go (the admission webhook)
+
+package guide
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// =============================================================================
+// The SecretStore
+// =============================================================================
+
+type SecretStore struct {
+	ObjectMeta
+	Spec   SecretStoreSpec
+	Status SecretStoreStatus
+}
+
+type SecretStoreSpec struct {
+	// Provider is spec.provider: exactly one key, e.g. {"aws": {"region": "us-east-1"}}.
+	Provider map[string]interface{}
+}
+
+type SecretStoreStatus struct {
+	Conditions []Condition
+}
+
+const (
+	ReasonStoreValid            = "Valid"
+	ReasonInvalidProviderConfig = "InvalidProviderConfig"
+)
+
+var ErrInvalidStore = errors.New("invalid store")
+
+// =============================================================================
+// Per-Provider Rules
+// =============================================================================
+//
+// Called from each provider's ValidateStore (Pattern 05). Each returns
+// every problem it finds, not the first.
+
+var awsRegionRe = regexp.MustCompile(`^[a-z]{2}(-gov)?-[a-z]+-[0-9]$`)
+
+func validateAWSStore(store StoreConfig) error {
+	switch {
+	case store.Region == "":
+		return fmt.Errorf("%w: region is required", ErrInvalidStore)
+	case !awsRegionRe.MatchString(store.Region):
+		return fmt.Errorf("%w: region %q is not an AWS region (e.g. us-east-1)", ErrInvalidStore, store.Region)
+	}
+	return nil
+}
+
+func validateVaultStore(store StoreConfig) error {
+	var errs error
+	if store.Server == "" {
+		errs = errors.Join(errs, fmt.Errorf("%w: server is required", ErrInvalidStore))
+	} else if u, err := url.Parse(store.Server); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = errors.Join(errs, fmt.Errorf("%w: server %q must be an http(s) URL with a host", ErrInvalidStore, store.Server))
+	}
+	if strings.HasPrefix(store.Path, "/") {
+		errs = errors.Join(errs, fmt.Errorf("%w: path %q must be relative to the mount (no leading '/')", ErrInvalidStore, store.Path))
+	}
+	return errs
+}
+
+// =============================================================================
+// The Validation Path
+// =============================================================================
+
+// ValidateStoreSpec resolves the provider and runs its ValidateStore. An
+// unknown key in the provider's config is reported alongside the provider's
+// own findings, not instead of them.
+func ValidateStoreSpec(spec SecretStoreSpec) (StoreConfig, error) {
+	_, cfg, err := validateStoreSpec(spec)
+	return cfg, err
+}
+
+// validateStoreSpec also returns the provider, for GetProviderFromSpec.
+func validateStoreSpec(spec SecretStoreSpec) (SecretsProvider, StoreConfig, error) {
+	name, provider, err := providerFromSpec(spec.Provider)
+	if err != nil {
+		return nil, StoreConfig{}, fmt.Errorf("%w: %w", ErrInvalidStore, err)
+	}
+
+	var errs error
+	raw, _ := json.Marshal(spec.Provider[name])
+	cfg := StoreConfig{Provider: name}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		errs = errors.Join(errs, fmt.Errorf("%w: %v", ErrInvalidStore, err))
+		cfg = StoreConfig{Provider: name}
+		json.Unmarshal(raw, &cfg) // validate what we can read
+	}
+	if err := provider.ValidateStore(cfg); err != nil {
+		errs = errors.Join(errs, err)
+	}
+	if errs != nil {
+		return provider, cfg, fmt.Errorf("spec.provider.%s: %w", name, errs)
+	}
+	return provider, cfg, nil
+}
+
+// ValidateSecretStore is the admission path: a store that fails here is
+// never stored.
+func ValidateSecretStore(store *SecretStore) error {
+	_, err := ValidateStoreSpec(store.Spec)
+	return err
+}
+
+// =============================================================================
+// The Reconcile Path
+// =============================================================================
+//
+// Admission only sees new writes. Stores created before a rule existed, or
+// with the webhook down, are caught here, and the verdict is visible to
+// anyone reading the store — before an ExternalSecret ever references it.
+
+type SecretStoreReconciler struct {
+	API   *FakeAPIServer
+	Clock Clock // nil = RealClock
+}
+
+// Reconcile writes the Ready condition. An invalid store is not an error:
+// retrying can't fix it, and the spec edit that does will trigger a new
+// reconcile anyway.
+func (r *SecretStoreReconciler) Reconcile(ctx context.Context, namespace, name string) error {
+	store := &SecretStore{}
+	if err := r.API.Get(ctx, namespace, name, store); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	ready := Condition{Type: "Ready", Status: "True", Reason: ReasonStoreValid, Message: "store validated"}
+	if _, err := ValidateStoreSpec(store.Spec); err != nil {
+		ready.Status = "False"
+		ready.Reason = ReasonInvalidProviderConfig
+		ready.Message = strings.ReplaceAll(err.Error(), "\n", "; ")
+	}
+
+	ready.LastTransitionTime = orRealClock(r.Clock).Now()
+	if prev := storeReadyCondition(store); prev != nil && prev.Status == ready.Status {
+		if prev.Reason == ready.Reason && prev.Message == ready.Message {
+			return nil // unchanged: skip the write
+		}
+		ready.LastTransitionTime = prev.LastTransitionTime // status didn't transition
+	}
+	store.Status.Conditions = []Condition{ready}
+	return r.API.UpdateStatus(ctx, store)
+}
+
+// storeReadyCondition returns the store's Ready condition, or nil.
+func storeReadyCondition(store *SecretStore) *Condition {
+	for i := range store.Status.Conditions {
+		if store.Status.Conditions[i].Type == "Ready" {
+			return &store.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// =============================================================================
+// Example: Three Stores, Validated Before Anything Uses Them
+// =============================================================================
+
+func ExampleStoreValidation() {
+	ctx := context.Background()
+	api := NewFakeAPIServer()
+
+	stores := []*SecretStore{
+		{ObjectMeta: ObjectMeta{Name: "aws-prod", Namespace: "default"},
+			Spec: SecretStoreSpec{Provider: map[string]interface{}{"aws": map[string]string{"region": "us-east-1"}}}},
+		{ObjectMeta: ObjectMeta{Name: "aws-typo", Namespace: "default"},
+			Spec: SecretStoreSpec{Provider: map[string]interface{}{"aws": map[string]string{"regoin": "us-east-1"}}}},
+		{ObjectMeta: ObjectMeta{Name: "vault", Namespace: "default"},
+			Spec: SecretStoreSpec{Provider: map[string]interface{}{"vault": map[string]string{"server": "vault.example.com", "path": "/secret"}}}},
+	}
+
+	// Admission: report everything wrong with each store at once.
+	for _, s := range stores {
+		fmt.Printf("%s: %v\n", s.Name, ValidateSecretStore(s))
+	}
+	// aws-prod: <nil>
+	// aws-typo: spec.provider.aws: invalid store: json: unknown field "regoin"
+	// invalid store: region is required
+	// vault: spec.provider.vault: invalid store: server "vault.example.com" must be an http(s) URL with a host
+	// invalid store: path "/secret" must be relative to the mount (no leading '/')
+
+	// Reconcile: stores that got past admission (say, the webhook was down)
+	// still end up with a Ready condition saying what's wrong.
+	reconciler := &SecretStoreReconciler{API: api}
+	for _, s := range stores {
+		api.Create(ctx, s)
+		reconciler.Reconcile(ctx, "default", s.Name)
+
+		got := &SecretStore{}
+		api.Get(ctx, "default", s.Name, got)
+		ready := storeReadyCondition(got)
+		fmt.Printf("%-8s Ready=%s %s\n", s.Name, ready.Status, ready.Reason)
+	}
+	// aws-prod Ready=True Valid
+	// aws-typo Ready=False InvalidProviderConfig
+	// vault    Ready=False InvalidProviderConfig
+
+	_, err := ValidateStoreSpec(SecretStoreSpec{Provider: map[string]interface{}{"azure": map[string]string{}}})
+	fmt.Println(errors.Is(err, ErrInvalidStore), err) // true invalid store: unknown provider: azure
+}
+
+// KEY INSIGHT:
+// A store is configuration shared by many ExternalSecrets, so its errors
+// belong on the store, found once. Putting static checks behind the
+// provider interface keeps the controller provider-agnostic (Pattern 05)
+// while letting each provider say exactly what "well-formed" means for it.
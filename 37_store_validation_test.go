@@ -0,0 +1,127 @@
+package guide
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateStoreSpecReportsUnknownFieldAndProviderErrorsTogether(t *testing.T) {
+	_, err := ValidateStoreSpec(SecretStoreSpec{Provider: map[string]interface{}{
+		"vault": map[string]string{"sever": "https://vault.example.com", "path": "/secret"},
+	}})
+	if !errors.Is(err, ErrInvalidStore) {
+		t.Fatalf("err = %v, want ErrInvalidStore", err)
+	}
+	for _, want := range []string{`unknown field "sever"`, "server is required", `path "/secret" must be relative`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("missing %q in:\n%v", want, err)
+		}
+	}
+	if !strings.HasPrefix(err.Error(), "spec.provider.vault: ") {
+		t.Errorf("err = %q, want it prefixed with the provider's field path", err)
+	}
+}
+
+type storeFixture struct {
+	t          *testing.T
+	ctx        context.Context
+	clock      *FakeClock
+	api        *FakeAPIServer
+	reconciler *SecretStoreReconciler
+}
+
+func newStoreFixture(t *testing.T, provider map[string]interface{}) *storeFixture {
+	clock := NewFakeClock(testEpoch)
+	f := &storeFixture{t: t, ctx: context.Background(), clock: clock, api: NewFakeAPIServerWithClock(clock)}
+	f.reconciler = &SecretStoreReconciler{API: f.api, Clock: clock}
+	store := &SecretStore{ObjectMeta: ObjectMeta{Name: "store", Namespace: "default"}, Spec: SecretStoreSpec{Provider: provider}}
+	if err := f.api.Create(f.ctx, store); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+// reconcile runs one reconcile and returns the stored object.
+func (f *storeFixture) reconcile() *SecretStore {
+	f.t.Helper()
+	if err := f.reconciler.Reconcile(f.ctx, "default", "store"); err != nil {
+		f.t.Fatalf("Reconcile() = %v", err)
+	}
+	store := &SecretStore{}
+	if err := f.api.Get(f.ctx, "default", "store", store); err != nil {
+		f.t.Fatal(err)
+	}
+	return store
+}
+
+func (f *storeFixture) setProvider(provider map[string]interface{}) {
+	f.t.Helper()
+	store := &SecretStore{}
+	f.api.Get(f.ctx, "default", "store", store)
+	store.Spec.Provider = provider
+	if err := f.api.Update(f.ctx, store); err != nil {
+		f.t.Fatal(err)
+	}
+}
+
+func TestStoreReadyConditionTransitions(t *testing.T) {
+	f := newStoreFixture(t, map[string]interface{}{"aws": map[string]string{"region": "us-east-1"}})
+
+	store := f.reconcile()
+	ready := storeReadyCondition(store)
+	if ready == nil || ready.Status != "True" || ready.Reason != ReasonStoreValid || !ready.LastTransitionTime.Equal(testEpoch) {
+		t.Fatalf("Ready = %+v, want True/Valid at the epoch", ready)
+	}
+
+	// Valid → invalid: a transition, stamped now.
+	f.clock.Step(time.Minute)
+	f.setProvider(map[string]interface{}{"aws": map[string]string{"region": "US East"}})
+	store = f.reconcile()
+	ready = storeReadyCondition(store)
+	if ready.Status != "False" || ready.Reason != ReasonInvalidProviderConfig || !strings.Contains(ready.Message, `region "US East"`) {
+		t.Fatalf("Ready = %+v, want False/InvalidProviderConfig naming the region", ready)
+	}
+	failedAt := testEpoch.Add(time.Minute)
+	if !ready.LastTransitionTime.Equal(failedAt) {
+		t.Fatalf("LastTransitionTime = %v, want %v", ready.LastTransitionTime, failedAt)
+	}
+
+	// Still invalid, for a different reason: the message changes, the
+	// transition time doesn't.
+	f.clock.Step(time.Minute)
+	f.setProvider(map[string]interface{}{"aws": map[string]string{}})
+	store = f.reconcile()
+	ready = storeReadyCondition(store)
+	if !strings.Contains(ready.Message, "region is required") {
+		t.Fatalf("Message = %q, want the new problem", ready.Message)
+	}
+	if !ready.LastTransitionTime.Equal(failedAt) {
+		t.Fatalf("LastTransitionTime = %v, want %v kept: the status didn't change", ready.LastTransitionTime, failedAt)
+	}
+}
+
+func TestStoreReconcileSkipsTheWriteWhenNothingChanged(t *testing.T) {
+	f := newStoreFixture(t, map[string]interface{}{"vault": map[string]string{"server": "vault.example.com"}})
+	first := f.reconcile()
+
+	for i := 0; i < 3; i++ {
+		f.clock.Step(time.Hour)
+		again := f.reconcile()
+		if again.ResourceVersion != first.ResourceVersion {
+			t.Fatalf("pass %d wrote an unchanged status: rv %s → %s", i, first.ResourceVersion, again.ResourceVersion)
+		}
+	}
+	if ready := storeReadyCondition(first); ready.Status != "False" || !ready.LastTransitionTime.Equal(testEpoch) {
+		t.Fatalf("Ready = %+v", ready)
+	}
+}
+
+func TestStoreReconcileIgnoresDeletedStores(t *testing.T) {
+	f := newStoreFixture(t, map[string]interface{}{"aws": map[string]string{"region": "us-east-1"}})
+	if err := f.reconciler.Reconcile(f.ctx, "default", "missing"); err != nil {
+		t.Fatalf("Reconcile(missing) = %v, want nil", err)
+	}
+}
@@ -0,0 +1,210 @@
+// Pattern 38: Adapting Legacy Providers to the One Provider Interface
+//
+// Problem: The guide grew two provider abstractions — a context-free one
+// with a map config (the original Pattern 02 shape) and the context-aware
+// SecretsProvider with StoreConfig and Close (Pattern 05). Code built on
+// both ended up writing ad-hoc adapters, and the registry could only hold
+// one of them.
+//
+// Solution: SecretsProvider is the only interface the registry knows.
+// Implementations still written against the old shape are wrapped once,
+// at registration:
+//
+//   func init() {
+//       RegisterProvider("consul", AdaptLegacyProvider(&consul.Provider{}))
+//   }
+//
+// The adapter fills in what the old shape can't express:
+//
+//   StoreConfig          → map config (region, server, path + authConfig)
+//   ctx                  → checked before every call; a call already in
+//                          flight can't be cancelled — the old API has no ctx
+//   Close                → the legacy client's Close() if it has one, else no-op
+//   Capabilities         → declared if implemented, else GetSecret|GetSecretMap
+//   ValidateStore        → delegated if implemented, else accept
+//
+// REAL CODE REFERENCE:
+//   apis/externalsecrets/v1/provider.go (Provider, SecretsClient)
+//   the v1beta1 → v1 provider migration kept old providers compiling the same way
+
+package guide
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// =============================================================================
+// The Legacy Shape
+// =============================================================================
+
+// LegacySecretProvider is the pre-context provider interface.
+type LegacySecretProvider interface {
+	NewClient(config map[string]string) (LegacySecretClient, error)
+}
+
+type LegacySecretClient interface {
+	GetSecret(key string) ([]byte, error)
+	GetSecretMap(key string) (map[string][]byte, error)
+}
+
+// =============================================================================
+// The Adapter
+// =============================================================================
+
+// AdaptLegacyProvider wraps a legacy provider as a SecretsProvider.
+func AdaptLegacyProvider(legacy LegacySecretProvider) SecretsProvider {
+	return &legacyProviderAdapter{legacy: legacy}
+}
+
+type legacyProviderAdapter struct {
+	legacy LegacySecretProvider
+}
+
+func (a *legacyProviderAdapter) NewClient(ctx context.Context, store StoreConfig) (SecretsClient, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	client, err := a.legacy.NewClient(legacyConfig(store))
+	if err != nil {
+		return nil, err
+	}
+	return &legacyClientAdapter{legacy: client}, nil
+}
+
+func (a *legacyProviderAdapter) Capabilities() Capabilities {
+	if cp, ok := a.legacy.(CapabilityProvider); ok {
+		return cp.Capabilities()
+	}
+	return CapGetSecret | CapGetSecretMap // all the legacy client interface offers
+}
+
+func (a *legacyProviderAdapter) ValidateStore(store StoreConfig) error {
+	if v, ok := a.legacy.(interface{ ValidateStore(StoreConfig) error }); ok {
+		return v.ValidateStore(store)
+	}
+	return nil
+}
+
+// legacyConfig flattens a StoreConfig into the old map. Typed fields win
+// over an authConfig entry of the same name.
+func legacyConfig(store StoreConfig) map[string]string {
+	config := make(map[string]string, len(store.AuthConfig)+3)
+	for k, v := range store.AuthConfig {
+		config[k] = v
+	}
+	for k, v := range map[string]string{"region": store.Region, "server": store.Server, "path": store.Path} {
+		if v != "" {
+			config[k] = v
+		}
+	}
+	return config
+}
+
+type legacyClientAdapter struct {
+	legacy LegacySecretClient
+	closed atomic.Bool // a deferred Close can race a worker's early one
+}
+
+var ErrClientClosed = errors.New("provider client is closed")
+
+func (c *legacyClientAdapter) GetSecret(ctx context.Context, key string) ([]byte, error) {
+	if err := c.check(ctx); err != nil {
+		return nil, err
+	}
+	return c.legacy.GetSecret(key)
+}
+
+func (c *legacyClientAdapter) GetSecretMap(ctx context.Context, key string) (map[string][]byte, error) {
+	if err := c.check(ctx); err != nil {
+		return nil, err
+	}
+	return c.legacy.GetSecretMap(key)
+}
+
+// Close is idempotent: reconcilers defer it and may also close early. Of
+// any number of concurrent calls, exactly one closes the legacy client.
+func (c *legacyClientAdapter) Close(ctx context.Context) error {
+	if c.closed.Swap(true) {
+		return nil
+	}
+	if closer, ok := c.legacy.(interface{ Close() error }); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (c *legacyClientAdapter) check(ctx context.Context) error {
+	if c.closed.Load() {
+		return ErrClientClosed
+	}
+	return ctx.Err()
+}
+
+// =============================================================================
+// Example: A Legacy Provider Behind the Canonical Interface
+// =============================================================================
+
+// staticLegacyProvider is written against the old shape: no ctx, map config.
+type staticLegacyProvider struct{}
+
+func (p *staticLegacyProvider) NewClient(config map[string]string) (LegacySecretClient, error) {
+	if config["server"] == "" {
+		return nil, fmt.Errorf("server is required")
+	}
+	return &staticLegacyClient{server: config["server"]}, nil
+}
+
+type staticLegacyClient struct{ server string }
+
+func (c *staticLegacyClient) GetSecret(key string) ([]byte, error) {
+	return []byte(c.server + "/" + key), nil
+}
+func (c *staticLegacyClient) GetSecretMap(key string) (map[string][]byte, error) {
+	return map[string][]byte{key: []byte(c.server)}, nil
+}
+func (c *staticLegacyClient) Close() error {
+	fmt.Println("legacy client closed")
+	return nil
+}
+
+func ExampleLegacyProviderAdapter() {
+	ctx := context.Background()
+
+	// Same interface the registry holds: the reconciler can't tell them apart.
+	var provider SecretsProvider = AdaptLegacyProvider(&staticLegacyProvider{})
+	fmt.Println(provider.Capabilities()) // GetSecret|GetSecretMap
+
+	client, err := provider.NewClient(ctx, StoreConfig{Server: "https://kv.internal"})
+	if err != nil {
+		panic(err)
+	}
+	value, _ := client.GetSecret(ctx, "db/password")
+	fmt.Println(string(value)) // https://kv.internal/db/password
+
+	// A cancelled reconcile stops before calling into the legacy code.
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = client.GetSecret(cancelled, "db/password")
+	fmt.Println(err) // context canceled
+
+	client.Close(ctx) // legacy client closed
+	client.Close(ctx) // (idempotent: prints nothing)
+	_, err = client.GetSecret(ctx, "db/password")
+	fmt.Println(errors.Is(err, ErrClientClosed)) // true
+
+	// The registry path: resolve, validate, create — then always Close.
+	awsClient, err := GetProviderFromSpec(ctx, map[string]interface{}{"aws": map[string]string{"region": "eu-west-1"}}) // AWS: creating client for region eu-west-1
+	if err != nil {
+		panic(err)
+	}
+	defer awsClient.Close(ctx)
+}
+
+// KEY INSIGHT:
+// Two interfaces for one concept means every consumer handles both. Picking
+// the richer one as canonical and adapting the other at a single boundary —
+// registration — keeps the rest of the code written against exactly one
+// shape, and makes the missing pieces (ctx, Close) explicit in one place.
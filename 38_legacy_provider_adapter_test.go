@@ -0,0 +1,84 @@
+package guide
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type countingLegacyClient struct {
+	staticLegacyClient
+	closes atomic.Int32
+}
+
+func (c *countingLegacyClient) Close() error {
+	c.closes.Add(1)
+	return nil
+}
+
+func TestLegacyClientConcurrentCloseClosesOnce(t *testing.T) {
+	ctx := context.Background()
+	legacy := &countingLegacyClient{}
+	client := &legacyClientAdapter{legacy: legacy}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() { defer wg.Done(); client.Close(ctx) }()
+		go func() { defer wg.Done(); client.GetSecret(ctx, "db/password") }()
+	}
+	wg.Wait()
+
+	if got := legacy.closes.Load(); got != 1 {
+		t.Fatalf("legacy Close called %d times, want 1", got)
+	}
+	if _, err := client.GetSecret(ctx, "db/password"); !errors.Is(err, ErrClientClosed) {
+		t.Fatalf("GetSecret after Close = %v, want ErrClientClosed", err)
+	}
+}
+
+func TestLegacyAdapterConfigAndContext(t *testing.T) {
+	ctx := context.Background()
+	provider := AdaptLegacyProvider(&staticLegacyProvider{})
+	if got := provider.Capabilities(); got != CapGetSecret|CapGetSecretMap {
+		t.Fatalf("Capabilities() = %v", got)
+	}
+	if _, err := provider.NewClient(ctx, StoreConfig{}); err == nil {
+		t.Fatal("NewClient without a server succeeded")
+	}
+
+	// authConfig reaches the legacy map, but a typed field wins.
+	config := legacyConfig(StoreConfig{Server: "https://kv", AuthConfig: map[string]string{"server": "ignored", "token": "t"}})
+	if config["server"] != "https://kv" || config["token"] != "t" {
+		t.Fatalf("legacyConfig() = %v", config)
+	}
+
+	client, err := provider.NewClient(ctx, StoreConfig{Server: "https://kv"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := client.GetSecretMap(cancelled, "k"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("GetSecretMap on a cancelled ctx = %v", err)
+	}
+	if _, err := provider.NewClient(cancelled, StoreConfig{Server: "https://kv"}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("NewClient on a cancelled ctx = %v", err)
+	}
+}
+
+// The registry holds one implementation per provider name: Pattern 05's.
+func TestRegistryHoldsThePattern05Providers(t *testing.T) {
+	for name, want := range map[string]SecretsProvider{"aws": &awsSecretsProvider{}, "vault": &vaultProvider{}, "gcp": &gcpProvider{}} {
+		got, ok := GetProviderByName(name)
+		if !ok {
+			t.Fatalf("%q is not registered", name)
+		}
+		if gotType, wantType := fmt.Sprintf("%T", got), fmt.Sprintf("%T", want); gotType != wantType {
+			t.Errorf("%q is a %s, want %s", name, gotType, wantType)
+		}
+	}
+}
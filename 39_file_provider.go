@@ -0,0 +1,693 @@
+// Pattern 39: A File-Backed Provider for Local Development
+//
+// Problem: Every registered provider (Patterns 02 and 05) returns hardcoded
+// strings. That's fine for showing the shape of the interface, but useless
+// for exercising a real sync loop offline: nothing changes, nothing is
+// missing, and nothing is nested.
+//
+// Solution: A real provider, registered like any other, that reads from disk:
+//
+//   {"file": {"path": "/etc/dev-secrets"}}        a directory tree (one file per value)
+//   {"file": {"path": "/etc/dev-secrets.json"}}   a JSON document
+//   {"file": {"path": "/etc/dev-secrets.yaml"}}   a YAML document (subset, see below)
+//
+// Both layouts become the same tree, so one key syntax works for all three:
+//
+//   GetSecret("db.password")   leaf value; an object comes back as JSON
+//   GetSecret("db/password")   '/' also separates, and never joins (directories)
+//   GetSecret("tls.crt")       keys containing dots win over nesting (longest match)
+//   GetSecretMap("db")         the object's direct children; nested ones as JSON
+//
+// Files are re-read on every call, so an edit is visible on the next sync.
+// To make that next sync happen NOW rather than at the refresh interval,
+// a FileWatcher polls the tree and calls back when its content changes —
+// hook it to Controller.Enqueue (Pattern 23).
+//
+// YAML: the guide has no dependencies beyond the standard library, which has
+// no YAML parser. Rather than guess, the provider accepts the subset secret
+// files actually use — nested block mappings of scalars, quoted or not, with
+// comments — and rejects everything else (lists, flow style, block scalars,
+// anchors, multiple documents) with ErrUnsupportedYAML and the line number.
+// Scalars are kept as written: no type coercion is needed when every value
+// ends up as bytes anyway. The one exception is null (null, Null, NULL, ~,
+// or nothing after the colon), which is an empty value — as JSON's null is.
+// A plain scalar containing ": " is refused rather than read as text: real
+// YAML rejects it too, so the file wouldn't load anywhere else.
+//
+// Polling, not inotify: the standard library has no portable file-event API.
+// For a dev provider, a content hash every few seconds is simple and also
+// catches what inotify handles badly — Kubernetes' atomic ..data symlink swap.
+//
+// REAL CODE REFERENCE:
+//   providers/v1/fake/fake.go (the in-tree test provider)
+//   k8s.io/kubernetes/pkg/volume/util/atomic_writer.go (the ..data symlink layout)
+
+package guide
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// =============================================================================
+// The Provider
+// =============================================================================
+
+var (
+	ErrNoSecret        = errors.New("secret does not exist") // the provider-side "not found" (advanced Pattern 17's NoSecretErr)
+	ErrUnsupportedYAML = errors.New("unsupported YAML")
+)
+
+type FileProvider struct{}
+
+func (p *FileProvider) NewClient(ctx context.Context, store StoreConfig) (SecretsClient, error) {
+	if _, err := os.Stat(store.Path); err != nil {
+		return nil, fmt.Errorf("file provider: %w", err)
+	}
+	return &fileClient{path: store.Path}, nil
+}
+
+// Capabilities: reads only. Listing (find) isn't in the client interface.
+func (p *FileProvider) Capabilities() Capabilities { return CapGetSecret | CapGetSecretMap }
+
+func (p *FileProvider) ValidateStore(store StoreConfig) error {
+	// Whether path is a directory or a document is only known on disk, and
+	// the webhook may not run where the files are: NewClient checks that.
+	if store.Path == "" {
+		return fmt.Errorf("%w: path is required", ErrInvalidStore)
+	}
+	return nil
+}
+
+func init() {
+	RegisterProvider("file", &FileProvider{})
+}
+
+type fileClient struct{ path string }
+
+func (c *fileClient) GetSecret(ctx context.Context, key string) ([]byte, error) {
+	node, err := c.lookup(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if node.children == nil {
+		return node.value, nil
+	}
+	return json.Marshal(node.toJSON())
+}
+
+func (c *fileClient) GetSecretMap(ctx context.Context, key string) (map[string][]byte, error) {
+	node, err := c.lookup(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if node.children == nil {
+		return nil, fmt.Errorf("file provider: key %q is a value, not an object", key)
+	}
+	out := make(map[string][]byte, len(node.children))
+	for k, child := range node.children {
+		if child.children == nil {
+			out[k] = child.value
+			continue
+		}
+		out[k], _ = json.Marshal(child.toJSON()) // strings and maps always marshal
+	}
+	return out, nil
+}
+
+func (c *fileClient) Close(ctx context.Context) error { return nil } // nothing held open between calls
+
+func (c *fileClient) lookup(ctx context.Context, key string) (*fileNode, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	root, err := loadFileTree(c.path)
+	if err != nil {
+		return nil, fmt.Errorf("file provider: %w", err)
+	}
+	node := root.lookup(key)
+	if node == nil {
+		return nil, fmt.Errorf("file provider: key %q: %w", key, ErrNoSecret)
+	}
+	return node, nil
+}
+
+// =============================================================================
+// The Tree
+// =============================================================================
+
+// fileNode is a directory/object (children != nil) or a file/scalar (value).
+type fileNode struct {
+	value    []byte
+	children map[string]*fileNode
+}
+
+// lookup resolves a key path. '.' and '/' both separate segments; at each
+// level the longest run of '.'-joined segments that names a child wins, so
+// "tls.crt" finds a key literally named "tls.crt" before trying tls → crt.
+func (n *fileNode) lookup(key string) *fileNode {
+	if key == "" {
+		return n
+	}
+	var segs [][2]int // [start, end) of each segment in key
+	start := 0
+	for i := 0; i <= len(key); i++ {
+		if i == len(key) || key[i] == '.' || key[i] == '/' {
+			segs = append(segs, [2]int{start, i})
+			start = i + 1
+		}
+	}
+	return n.lookupSegs(key, segs)
+}
+
+func (n *fileNode) lookupSegs(key string, segs [][2]int) *fileNode {
+	if len(segs) == 0 {
+		return n
+	}
+	if n.children == nil {
+		return nil
+	}
+	for i := len(segs); i >= 1; i-- {
+		name := key[segs[0][0]:segs[i-1][1]]
+		if strings.Contains(name, "/") {
+			continue // '/' never joins
+		}
+		if child, ok := n.children[name]; ok {
+			if found := child.lookupSegs(key, segs[i:]); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// toJSON renders a subtree as nested maps of strings.
+func (n *fileNode) toJSON() interface{} {
+	if n.children == nil {
+		return string(n.value)
+	}
+	out := make(map[string]interface{}, len(n.children))
+	for k, child := range n.children {
+		out[k] = child.toJSON()
+	}
+	return out
+}
+
+// fingerprint hashes every path and value in the tree, in sorted order.
+func (n *fileNode) fingerprint() [sha256.Size]byte {
+	h := sha256.New()
+	var walk func(prefix string, n *fileNode)
+	walk = func(prefix string, n *fileNode) {
+		if n.children == nil {
+			fmt.Fprintf(h, "%q=%d:", prefix, len(n.value))
+			h.Write(n.value)
+			return
+		}
+		for _, k := range sortedKeys(n.children) {
+			walk(prefix+"/"+k, n.children[k])
+		}
+	}
+	walk("", n)
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
+
+// =============================================================================
+// Loading: Directories, JSON, YAML
+// =============================================================================
+
+func documentFormat(path string) (string, bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return "json", true
+	case ".yaml", ".yml":
+		return "yaml", true
+	}
+	return "", false
+}
+
+func loadFileTree(path string) (*fileNode, error) {
+	info, err := os.Stat(path) // follows symlinks, as a mounted Secret needs
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return loadDir(path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch format, _ := documentFormat(path); format {
+	case "json":
+		return parseJSONDocument(data)
+	case "yaml":
+		return parseYAMLDocument(data)
+	}
+	return nil, fmt.Errorf("%s: not a directory, .json or .yaml/.yml file", path)
+}
+
+// loadDir maps a directory to an object and each regular file to a value.
+// Dot-entries are skipped: that hides editor swap files and, in a mounted
+// Secret volume, the ..data and ..<timestamp> plumbing behind each key.
+func loadDir(dir string) (*fileNode, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	node := &fileNode{children: make(map[string]*fileNode, len(entries))}
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		full := filepath.Join(dir, e.Name())
+		info, err := os.Stat(full)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			child, err := loadDir(full)
+			if err != nil {
+				return nil, err
+			}
+			node.children[e.Name()] = child
+			continue
+		}
+		value, err := os.ReadFile(full)
+		if err != nil {
+			return nil, err
+		}
+		node.children[e.Name()] = &fileNode{value: value}
+	}
+	return node, nil
+}
+
+func parseJSONDocument(data []byte) (*fileNode, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber() // numbers exactly as written; float64 would round long IDs
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("json: %w", err)
+	}
+	// dec.More() alone misses a stray '}' or ']'; only EOF means "nothing else".
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("json: unexpected content after the top-level object (offset %d)", dec.InputOffset())
+	}
+	if _, ok := doc.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("json: top level must be an object")
+	}
+	return jsonNode(doc), nil
+}
+
+func jsonNode(v interface{}) *fileNode {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		node := &fileNode{children: make(map[string]*fileNode, len(v))}
+		for k, child := range v {
+			node.children[k] = jsonNode(child)
+		}
+		return node
+	case string:
+		return &fileNode{value: []byte(v)}
+	case nil:
+		return &fileNode{value: []byte{}} // same as a YAML null
+	default: // json.Number, bool, and arrays: their JSON text
+		raw, _ := json.Marshal(v)
+		return &fileNode{value: raw}
+	}
+}
+
+// parseYAMLDocument parses the block-mapping subset described at the top.
+func parseYAMLDocument(data []byte) (*fileNode, error) {
+	type frame struct {
+		indent int
+		node   *fileNode
+	}
+	root := &fileNode{children: map[string]*fileNode{}}
+	stack := []frame{{indent: 0, node: root}}
+
+	// A "key:" line with no value opens a mapping; it only becomes one if
+	// the next line is indented deeper. Otherwise the key is an empty value.
+	var open *fileNode
+	openIndent := 0
+	sawContent := false
+
+	unsupported := func(line int, what string) error {
+		return fmt.Errorf("yaml line %d: %w: %s", line, ErrUnsupportedYAML, what)
+	}
+
+	for i, raw := range strings.Split(string(data), "\n") {
+		lineNo := i + 1
+		line := strings.TrimRight(raw, " \r")
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if trimmed == "---" && !sawContent {
+			continue
+		}
+		if trimmed == "---" || trimmed == "..." {
+			return nil, unsupported(lineNo, "multiple documents")
+		}
+		sawContent = true
+		indent := len(line) - len(trimmed)
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, unsupported(lineNo, "tab indentation")
+		}
+		if trimmed == "-" || strings.HasPrefix(trimmed, "- ") {
+			return nil, unsupported(lineNo, "sequences")
+		}
+
+		if open != nil {
+			if indent > openIndent {
+				stack = append(stack, frame{indent: indent, node: open})
+			} else {
+				open.children = nil // "key:" with nothing under it
+				open.value = []byte{}
+			}
+			open = nil
+		}
+		for len(stack) > 1 && indent < stack[len(stack)-1].indent {
+			stack = stack[:len(stack)-1]
+		}
+		if indent != stack[len(stack)-1].indent {
+			return nil, fmt.Errorf("yaml line %d: inconsistent indentation", lineNo)
+		}
+
+		key, rest, err := splitYAMLKey(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("yaml line %d: %w", lineNo, err)
+		}
+		parent := stack[len(stack)-1].node
+		if _, dup := parent.children[key]; dup {
+			return nil, fmt.Errorf("yaml line %d: duplicate key %q", lineNo, key)
+		}
+
+		if rest == "" {
+			open = &fileNode{children: map[string]*fileNode{}}
+			openIndent = indent
+			parent.children[key] = open
+			continue
+		}
+		value, err := parseYAMLScalar(rest)
+		if err != nil {
+			return nil, fmt.Errorf("yaml line %d: %w", lineNo, err)
+		}
+		parent.children[key] = &fileNode{value: []byte(value)}
+	}
+	if open != nil {
+		open.children = nil
+		open.value = []byte{}
+	}
+	return root, nil
+}
+
+// splitYAMLKey splits `key: rest` (key optionally quoted). rest is "" for
+// `key:` and for `key: # comment`.
+func splitYAMLKey(line string) (key, rest string, err error) {
+	if line[0] == '"' || line[0] == '\'' {
+		end := closingQuote(line)
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated quoted key")
+		}
+		if key, err = parseYAMLScalar(line[:end+1]); err != nil {
+			return "", "", err
+		}
+		line = line[end+1:]
+		if !strings.HasPrefix(line, ":") {
+			return "", "", fmt.Errorf("expected ':' after key %q", key)
+		}
+		rest = line[1:]
+	} else {
+		idx := strings.Index(line+" ", ": ")
+		if idx < 0 && strings.HasSuffix(line, ":") {
+			idx = len(line) - 1
+		}
+		if idx < 0 {
+			return "", "", fmt.Errorf("expected 'key: value', got %q", line)
+		}
+		key, rest = line[:idx], line[idx+1:]
+	}
+	rest = strings.TrimSpace(rest)
+	if strings.HasPrefix(rest, "#") {
+		rest = ""
+	}
+	return key, rest, nil
+}
+
+// parseYAMLScalar unquotes "double" (YAML backslash escapes) and 'single' (a
+// doubled quote escapes one) scalars, and strips a trailing comment from
+// plain ones. A plain null is the empty string.
+func parseYAMLScalar(s string) (string, error) {
+	switch s[0] {
+	case '"':
+		end := closingQuote(s)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated double-quoted string")
+		}
+		if tail := strings.TrimSpace(s[end+1:]); tail != "" && !strings.HasPrefix(tail, "#") {
+			return "", fmt.Errorf("unexpected %q after quoted string", tail)
+		}
+		return unquoteYAMLDouble(s[1:end])
+	case '\'':
+		end := closingQuote(s)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated single-quoted string")
+		}
+		if tail := strings.TrimSpace(s[end+1:]); tail != "" && !strings.HasPrefix(tail, "#") {
+			return "", fmt.Errorf("unexpected %q after quoted string", tail)
+		}
+		return strings.ReplaceAll(s[1:end], "''", "'"), nil
+	case '[', '{':
+		return "", fmt.Errorf("%w: flow collections", ErrUnsupportedYAML)
+	case '|', '>':
+		return "", fmt.Errorf("%w: block scalars (use a quoted string with \\n)", ErrUnsupportedYAML)
+	case '&', '*', '!':
+		return "", fmt.Errorf("%w: anchors, aliases and tags", ErrUnsupportedYAML)
+	case '@', '`':
+		return "", fmt.Errorf("plain scalars cannot start with %q (quote the value)", s[0])
+	}
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, ": ") || strings.HasSuffix(s, ":") {
+		return "", fmt.Errorf("plain scalar %q contains ': ' (quote the value)", s)
+	}
+	switch s {
+	case "null", "Null", "NULL", "~":
+		return "", nil
+	}
+	return s, nil
+}
+
+// yamlEscapes are the single-character escapes of YAML 1.2 §5.7. Go's
+// strconv.Unquote differs: it rejects \/, \e, \N, \_, \L, \P, \<space> and
+// \<tab>, and reads \xXX as a byte where YAML means the code point U+00XX.
+var yamlEscapes = map[byte]string{
+	'0': "\x00", 'a': "\a", 'b': "\b", 't': "\t", '\t': "\t", 'n': "\n", 'v': "\v",
+	'f': "\f", 'r': "\r", 'e': "\x1b", ' ': " ", '"': "\"", '/': "/", '\\': "\\",
+	'N': "\u0085", '_': "\u00a0", 'L': "\u2028", 'P': "\u2029",
+}
+
+// unquoteYAMLDouble decodes the body of a double-quoted scalar.
+func unquoteYAMLDouble(body string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(body); i++ {
+		if body[i] != '\\' {
+			b.WriteByte(body[i])
+			continue
+		}
+		if i+1 == len(body) {
+			return "", fmt.Errorf("trailing backslash in double-quoted string")
+		}
+		i++
+		if esc, ok := yamlEscapes[body[i]]; ok {
+			b.WriteString(esc)
+			continue
+		}
+		digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[body[i]]
+		if digits == 0 {
+			return "", fmt.Errorf("unknown escape \\%c in double-quoted string", body[i])
+		}
+		if i+digits >= len(body) {
+			return "", fmt.Errorf("short \\%c escape in double-quoted string", body[i])
+		}
+		code, err := strconv.ParseUint(body[i+1:i+1+digits], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return "", fmt.Errorf("invalid \\%s escape in double-quoted string", body[i:i+1+digits])
+		}
+		b.WriteRune(rune(code))
+		i += digits
+	}
+	return b.String(), nil
+}
+
+// closingQuote returns the index of the quote closing s[0], or -1.
+func closingQuote(s string) int {
+	q := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case q == '"' && s[i] == '\\':
+			i++ // skip the escaped character
+		case q == '\'' && s[i] == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++ // '' is an escaped quote
+		case s[i] == q:
+			return i
+		}
+	}
+	return -1
+}
+
+// =============================================================================
+// Watching for Changes
+// =============================================================================
+
+// FileWatcher re-reads the store's path every interval and calls onChange
+// when the content differs from the last read. A read that fails — a file
+// caught mid-replace — is skipped, not reported as a change.
+type FileWatcher struct {
+	path     string
+	interval time.Duration
+	clock    Clock
+	onChange func()
+
+	mu      sync.Mutex
+	last    [sha256.Size]byte
+	timer   Timer
+	stopped bool
+}
+
+// WatchFileStore starts polling. The initial content is the baseline: only
+// later edits call onChange.
+func WatchFileStore(store StoreConfig, interval time.Duration, clock Clock, onChange func()) (*FileWatcher, error) {
+	root, err := loadFileTree(store.Path)
+	if err != nil {
+		return nil, fmt.Errorf("file provider: %w", err)
+	}
+	w := &FileWatcher{path: store.Path, interval: interval, clock: orRealClock(clock), onChange: onChange, last: root.fingerprint()}
+	w.mu.Lock()
+	w.timer = w.clock.AfterFunc(interval, w.poll)
+	w.mu.Unlock()
+	return w, nil
+}
+
+func (w *FileWatcher) poll() {
+	changed := false
+	if root, err := loadFileTree(w.path); err == nil {
+		sum := root.fingerprint()
+		w.mu.Lock()
+		changed, w.last = sum != w.last, sum
+		w.mu.Unlock()
+	}
+	if changed {
+		w.onChange()
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.stopped {
+		w.timer = w.clock.AfterFunc(w.interval, w.poll)
+	}
+}
+
+// Stop ends polling. It's safe to call more than once.
+func (w *FileWatcher) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stopped = true
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+}
+
+// =============================================================================
+// Example: Three Layouts, One Key Syntax, and a Refresh on Edit
+// =============================================================================
+
+func ExampleFileProvider() {
+	ctx := context.Background()
+	dir, _ := os.MkdirTemp("", "file-provider")
+	defer os.RemoveAll(dir)
+
+	// Layout 1: a directory tree, as a mounted Secret or `mkdir -p` gives you.
+	os.MkdirAll(filepath.Join(dir, "tree", "db"), 0o700)
+	os.WriteFile(filepath.Join(dir, "tree", "db", "password"), []byte("s3cret"), 0o600)
+	os.WriteFile(filepath.Join(dir, "tree", "db", "username"), []byte("app"), 0o600)
+	os.WriteFile(filepath.Join(dir, "tree", "tls.crt"), []byte("-----BEGIN CERTIFICATE-----"), 0o600)
+
+	// Layout 2 and 3: the same data as documents.
+	os.WriteFile(filepath.Join(dir, "dev.json"), []byte(`{"db": {"password": "s3cret", "port": 5432}}`), 0o600)
+	os.WriteFile(filepath.Join(dir, "dev.yaml"), []byte(`# local development only
+db:
+  password: "s3cret"   # quoted
+  port: 5432
+  replicas:
+    reader: db-ro.local
+`), 0o600)
+
+	for _, name := range []string{"tree", "dev.json", "dev.yaml"} {
+		client, err := GetProviderFromSpec(ctx, map[string]interface{}{"file": map[string]string{"path": filepath.Join(dir, name)}})
+		if err != nil {
+			panic(err)
+		}
+		password, _ := client.GetSecret(ctx, "db.password")
+		db, _ := client.GetSecretMap(ctx, "db")
+		fmt.Printf("%-8s db.password=%s db keys=%v\n", name, password, sortedKeys(db))
+		client.Close(ctx)
+	}
+	// tree     db.password=s3cret db keys=[password username]
+	// dev.json db.password=s3cret db keys=[password port]
+	// dev.yaml db.password=s3cret db keys=[password port replicas]
+
+	tree, _ := GetProviderFromSpec(ctx, map[string]interface{}{"file": map[string]string{"path": filepath.Join(dir, "tree")}})
+	defer tree.Close(ctx)
+	crt, _ := tree.GetSecret(ctx, "tls.crt") // a key with a dot, not tls → crt
+	fmt.Println(string(crt))                 // -----BEGIN CERTIFICATE-----
+	_, err := tree.GetSecret(ctx, "db.host")
+	fmt.Println(errors.Is(err, ErrNoSecret)) // true
+
+	yamlClient, _ := GetProviderFromSpec(ctx, map[string]interface{}{"file": map[string]string{"path": filepath.Join(dir, "dev.yaml")}})
+	replicas, _ := yamlClient.GetSecret(ctx, "db.replicas")
+	fmt.Println(string(replicas)) // {"reader":"db-ro.local"}
+
+	// YAML outside the subset is refused, not misread.
+	os.WriteFile(filepath.Join(dir, "dev.yaml"), []byte("hosts:\n  - a\n  - b\n"), 0o600)
+	_, err = yamlClient.GetSecret(ctx, "hosts")
+	fmt.Println(err) // file provider: yaml line 2: unsupported YAML: sequences
+	yamlClient.Close(ctx)
+
+	// Edits trigger a refresh now, not at the next refreshInterval.
+	clock := NewFakeClock(time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC))
+	queue := NewWorkqueue[Request](nil)
+	defer queue.ShutDown()
+	watcher, _ := WatchFileStore(StoreConfig{Path: filepath.Join(dir, "tree")}, 5*time.Second, clock, func() {
+		queue.Add(Request{Namespace: "default", Name: "db-creds"}) // every ExternalSecret using the store
+	})
+	defer watcher.Stop()
+
+	clock.Step(5 * time.Second)
+	fmt.Println(queue.Len()) // 0 — nothing changed
+	os.WriteFile(filepath.Join(dir, "tree", "db", "password"), []byte("rotated"), 0o600)
+	clock.Step(5 * time.Second)
+	fmt.Println(queue.Len()) // 1
+}
+
+// KEY INSIGHT:
+// An offline provider is only useful if it behaves like a real one: values
+// change, keys go missing, data is nested. Mapping directories and documents
+// onto one tree gives every layout the same key syntax, and being strict
+// about the YAML it can't parse keeps "works locally" from meaning "misread".
@@ -0,0 +1,343 @@
+package guide
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newFileClient(t *testing.T, path string) SecretsClient {
+	t.Helper()
+	client, err := GetProviderFromSpec(context.Background(), map[string]interface{}{"file": map[string]string{"path": path}})
+	if err != nil {
+		t.Fatalf("GetProviderFromSpec(%s) = %v", path, err)
+	}
+	t.Cleanup(func() { client.Close(context.Background()) })
+	return client
+}
+
+func mustParseYAML(t *testing.T, doc string) *fileNode {
+	t.Helper()
+	root, err := parseYAMLDocument([]byte(doc))
+	if err != nil {
+		t.Fatalf("parseYAMLDocument(%q) = %v", doc, err)
+	}
+	return root
+}
+
+func valueAt(t *testing.T, root *fileNode, key string) string {
+	t.Helper()
+	node := root.lookup(key)
+	if node == nil {
+		t.Fatalf("key %q not found", key)
+	}
+	if node.children != nil {
+		t.Fatalf("key %q is an object", key)
+	}
+	return string(node.value)
+}
+
+// =============================================================================
+// Tree and Lookup
+// =============================================================================
+
+func TestLoadDirSkipsDotEntriesAndFollowsSecretVolumeSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	// The layout kubelet's atomic writer produces for a mounted Secret.
+	writeFile(t, filepath.Join(dir, "..2024_01_15", "password"), "s3cret")
+	if err := os.Symlink("..2024_01_15", filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join("..data", "password"), filepath.Join(dir, "password")); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, ".password.swp"), "editor junk")
+	writeFile(t, filepath.Join(dir, "db", "username"), "app")
+
+	root, err := loadFileTree(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sortedKeys(root.children); strings.Join(got, ",") != "db,password" {
+		t.Fatalf("top-level keys = %v, want [db password]", got)
+	}
+	if got := valueAt(t, root, "password"); got != "s3cret" {
+		t.Fatalf("password = %q", got)
+	}
+	if got := valueAt(t, root, "db/username"); got != "app" {
+		t.Fatalf("db/username = %q", got)
+	}
+}
+
+func TestLookupPrefersLongestDottedKeyAndNeverJoinsSlashes(t *testing.T) {
+	root := mustParseYAML(t, `
+tls.crt: whole
+tls:
+  crt: nested
+  key: only-nested
+a.b:
+  c: via-dotted-parent
+"x/y": slash-key
+`)
+	for key, want := range map[string]string{
+		"tls.crt":   "whole",
+		"tls/crt":   "nested", // '/' always separates
+		"tls.key":   "only-nested",
+		"a.b.c":     "via-dotted-parent",
+		"a.b/c":     "via-dotted-parent",
+		"x/y":       "", // not found: '/' never joins into "x/y"
+		"tls.crt.x": "",
+	} {
+		node := root.lookup(key)
+		switch {
+		case want == "" && node != nil:
+			t.Errorf("lookup(%q) found %q, want nothing", key, node.value)
+		case want != "" && (node == nil || string(node.value) != want):
+			t.Errorf("lookup(%q) = %v, want %q", key, node, want)
+		}
+	}
+}
+
+func TestFileClientReturnsObjectsAsJSONAndReportsMissingKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dev.json")
+	writeFile(t, path, `{"db": {"password": "s3cret", "port": 5432, "replica": {"host": "ro"}}}`)
+	client := newFileClient(t, path)
+	ctx := context.Background()
+
+	db, err := client.GetSecret(ctx, "db.replica")
+	if err != nil || string(db) != `{"host":"ro"}` {
+		t.Fatalf("GetSecret(db.replica) = %s, %v", db, err)
+	}
+	m, err := client.GetSecretMap(ctx, "db")
+	if err != nil || string(m["port"]) != "5432" || string(m["replica"]) != `{"host":"ro"}` {
+		t.Fatalf("GetSecretMap(db) = %q, %v", m, err)
+	}
+	if _, err := client.GetSecretMap(ctx, "db.password"); err == nil {
+		t.Fatal("GetSecretMap on a value succeeded")
+	}
+	if _, err := client.GetSecret(ctx, "db.host"); !errors.Is(err, ErrNoSecret) {
+		t.Fatalf("GetSecret(db.host) = %v, want ErrNoSecret", err)
+	}
+}
+
+// =============================================================================
+// JSON
+// =============================================================================
+
+func TestParseJSONDocument(t *testing.T) {
+	root, err := parseJSONDocument([]byte(`{"id": 12345678901234567890, "empty": null, "on": true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{"id": "12345678901234567890", "empty": "", "on": "true"} {
+		if got := valueAt(t, root, key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+
+	for _, doc := range []string{
+		`{"a": "b"} {"c": "d"}`, // a second document
+		`{"a": "b"}}`,           // stray close, which dec.More() would miss
+		`{"a": "b"} x`,
+		`["a"]`,
+		`"a"`,
+	} {
+		if _, err := parseJSONDocument([]byte(doc)); err == nil {
+			t.Errorf("parseJSONDocument(%q) succeeded", doc)
+		}
+	}
+	if _, err := parseJSONDocument([]byte("{\"a\": \"b\"}\n\n")); err != nil {
+		t.Errorf("trailing whitespace rejected: %v", err)
+	}
+}
+
+// =============================================================================
+// YAML
+// =============================================================================
+
+func TestParseYAMLScalarsAndNesting(t *testing.T) {
+	root := mustParseYAML(t, `---
+# comment
+db:
+  password: "s3cret"   # quoted, comment after
+  user: app # plain, comment after
+  url: postgres://db:5432/app
+  time: 10:30
+  single: 'it''s'
+  hash: "a # not a comment"
+  empty:
+  nested:
+    deeper: x
+top: last
+`)
+	for key, want := range map[string]string{
+		"db.password":      "s3cret",
+		"db.user":          "app",
+		"db.url":           "postgres://db:5432/app",
+		"db.time":          "10:30",
+		"db.single":        "it's",
+		"db.hash":          "a # not a comment",
+		"db.empty":         "",
+		"db.nested.deeper": "x",
+		"top":              "last",
+	} {
+		if got := valueAt(t, root, key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestParseYAMLNullMatchesJSONNull(t *testing.T) {
+	root := mustParseYAML(t, "a: null\nb: ~\nc: NULL\nd:\ne: \"null\"\nf: 'null'\n")
+	fromJSON, _ := parseJSONDocument([]byte(`{"a": null}`))
+	want := valueAt(t, fromJSON, "a")
+	for _, key := range []string{"a", "b", "c", "d"} {
+		if got := valueAt(t, root, key); got != want {
+			t.Errorf("%s = %q, want %q (JSON null)", key, got, want)
+		}
+	}
+	for _, key := range []string{"e", "f"} {
+		if got := valueAt(t, root, key); got != "null" {
+			t.Errorf("quoted %s = %q, want the string \"null\"", key, got)
+		}
+	}
+}
+
+func TestParseYAMLDoubleQuotedEscapes(t *testing.T) {
+	for in, want := range map[string]string{
+		`"x\/y"`:             "x/y",
+		`"tab\there"`:        "tab\there",
+		`"a\ b"`:             "a b",
+		`"q\"q"`:             `q"q`,
+		`"back\\slash"`:      `back\slash`,
+		`"\e[0m"`:            "\x1b[0m",
+		`"\xe9"`:             "é", // a code point, not the byte 0xe9
+		`"\u00e9\U0001F511"`: "é\U0001F511",
+		`"nbsp\_ls\L"`:       "nbsp\u00a0ls\u2028",
+		`"line\nbreak"`:      "line\nbreak",
+	} {
+		got, err := parseYAMLScalar(in)
+		if err != nil || got != want {
+			t.Errorf("parseYAMLScalar(%s) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{`"\q"`, `"\x4"`, `"\uD800"`, `"\u12G4"`, `"abc`} {
+		if got, err := parseYAMLScalar(in); err == nil {
+			t.Errorf("parseYAMLScalar(%s) = %q, want an error", in, got)
+		}
+	}
+}
+
+func TestParseYAMLRejectsWhatItCannotReadFaithfully(t *testing.T) {
+	for doc, wantErr := range map[string]string{
+		"key: value: more\n":     "contains ': '",
+		"key: value:\n":          "contains ': '",
+		"\"k\": a: b\n":          "contains ': '",
+		"hosts:\n  - a\n":        "sequences",
+		"k: [a, b]\n":            "flow collections",
+		"k: |\n  text\n":         "block scalars",
+		"k: &anchor v\n":         "anchors",
+		"k: @v\n":                "quote the value",
+		"a: 1\n---\nb: 2\n":      "multiple documents",
+		"a:\n\tb: 1\n":           "tab indentation",
+		"a: 1\na: 2\n":           "duplicate key",
+		"a:\n    b: 1\n  c: 2\n": "inconsistent indentation",
+		"just text\n":            "expected 'key: value'",
+		"k: \"unterminated\n":    "unterminated",
+	} {
+		_, err := parseYAMLDocument([]byte(doc))
+		if err == nil {
+			t.Errorf("parseYAMLDocument(%q) succeeded", doc)
+			continue
+		}
+		if !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("parseYAMLDocument(%q) = %v, want it to mention %q", doc, err, wantErr)
+		}
+		if !strings.HasPrefix(err.Error(), "yaml line ") {
+			t.Errorf("parseYAMLDocument(%q) = %v, want a line number", doc, err)
+		}
+	}
+}
+
+// =============================================================================
+// Watcher
+// =============================================================================
+
+func TestFileWatcherCallsBackOnContentChangeOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dev.json")
+	writeFile(t, path, `{"password": "v1"}`)
+	clock := NewFakeClock(testEpoch)
+	changes := 0
+	watcher, err := WatchFileStore(StoreConfig{Path: path}, 5*time.Second, clock, func() { changes++ })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Stop()
+
+	step := func(want int, what string) {
+		t.Helper()
+		clock.Step(5 * time.Second)
+		if changes != want {
+			t.Fatalf("%s: changes = %d, want %d", what, changes, want)
+		}
+	}
+	step(0, "untouched")
+	writeFile(t, path, "{\n  \"password\": \"v1\"\n}\n")
+	step(0, "reformatted, same content")
+	writeFile(t, path, `{"password": "v2"`)
+	step(0, "caught mid-write (unparseable)")
+	writeFile(t, path, `{"password": "v2"}`)
+	step(1, "rotated")
+	step(1, "no further edits")
+
+	watcher.Stop()
+	watcher.Stop() // idempotent
+	writeFile(t, path, `{"password": "v3"}`)
+	step(1, "after Stop")
+}
+
+func TestFileWatcherSeesSecretVolumeSymlinkSwap(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "..v1", "password"), "v1")
+	writeFile(t, filepath.Join(dir, "..v2", "password"), "v2")
+	os.Symlink("..v1", filepath.Join(dir, "..data"))
+	os.Symlink(filepath.Join("..data", "password"), filepath.Join(dir, "password"))
+
+	clock := NewFakeClock(testEpoch)
+	changes := 0
+	watcher, err := WatchFileStore(StoreConfig{Path: dir}, time.Second, clock, func() { changes++ })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Stop()
+
+	// kubelet's swap: a new symlink renamed over ..data.
+	os.Symlink("..v2", filepath.Join(dir, "..data_tmp"))
+	if err := os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+	clock.Step(time.Second)
+	if changes != 1 {
+		t.Fatalf("changes after the ..data swap = %d, want 1", changes)
+	}
+}
+
+func TestWatchFileStoreRejectsUnreadableBaseline(t *testing.T) {
+	if _, err := WatchFileStore(StoreConfig{Path: filepath.Join(t.TempDir(), "missing.json")}, time.Second, NewFakeClock(testEpoch), func() {}); err == nil {
+		t.Fatal("WatchFileStore on a missing path succeeded")
+	}
+}
@@ -27,6 +27,8 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+
+	guide "design-patterns-guide"
 )
 
 // =============================================================================
@@ -81,7 +83,7 @@ func validateGood(spec ExternalSecretSpec) error {
 	// Validate each dataFrom entry — accumulating errors across ALL entries
 	// Real code: externalsecret_validator.go:58-73
 	for i, ref := range spec.DataFrom {
-		if ref.Extract == nil && ref.Find == nil && ref.Generator == nil {
+		if ref.Extract == nil && ref.Find == nil && len(ref.FindTags) == 0 && ref.Generator == nil {
 			errs = errors.Join(errs, fmt.Errorf("dataFrom[%d]: at least one of extract, find, or generator must be specified", i))
 		}
 	}
@@ -89,6 +91,13 @@ func validateGood(spec ExternalSecretSpec) error {
 	// Validate duplicate keys
 	errs = validateDuplicateKeys(spec, errs)
 
+	// Validate the refresh schedule. guide.ParseCron already joins one error
+	// per bad cron field, and joining that into errs flattens nicely: the user
+	// sees every bad field alongside every other problem.
+	if err := validateRefreshSchedule(spec); err != nil {
+		errs = errors.Join(errs, err)
+	}
+
 	// errors.Join returns nil if all inputs are nil — no special check needed
 	return errs
 }
@@ -111,6 +120,37 @@ func validatePolicies(spec ExternalSecretSpec) error {
 	return errs
 }
 
+func validateRefreshSchedule(spec ExternalSecretSpec) error {
+	if spec.RefreshPolicy != "Schedule" {
+		if spec.RefreshSchedule != "" {
+			return fmt.Errorf("refreshSchedule is only used with refreshPolicy=Schedule")
+		}
+		return nil
+	}
+	if spec.RefreshSchedule == "" {
+		return fmt.Errorf("refreshPolicy=Schedule requires refreshSchedule")
+	}
+	if _, err := guide.ParseCron(spec.RefreshSchedule); err != nil {
+		return prefixJoined("refreshSchedule", err)
+	}
+	return nil
+}
+
+// prefixJoined puts the field path on every error in a join, not just the
+// first line: fmt.Errorf("%s: %w") around a joined error only prefixes the
+// text before its first newline.
+func prefixJoined(field string, err error) error {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return fmt.Errorf("%s: %w", field, err)
+	}
+	var errs error
+	for _, e := range joined.Unwrap() {
+		errs = errors.Join(errs, prefixJoined(field, e))
+	}
+	return errs
+}
+
 func validateDuplicateKeys(spec ExternalSecretSpec, errs error) error {
 	seen := make(map[string]bool)
 	for _, d := range spec.Data {
@@ -148,6 +188,73 @@ func demonstrateErrorsIs() {
 	fmt.Println(combined)
 }
 
+// demonstrateScheduleValidation shows a bad cron expression reported next to
+// an unrelated error, in one response.
+func demonstrateScheduleValidation() {
+	err := validateGood(ExternalSecretSpec{
+		SecretStoreRef:  SecretStoreRef{Name: "vault"},
+		RefreshPolicy:   "Schedule",
+		RefreshSchedule: "0 26 * * FUNDAY",
+	})
+	fmt.Println(err)
+	// either data or dataFrom should be specified
+	// refreshSchedule: invalid cron expression "0 26 * * FUNDAY": hour field: value 26: out of range [0, 23]
+	// refreshSchedule: invalid cron expression "0 26 * * FUNDAY": day-of-week field: value "FUNDAY": not a number
+
+	_ = errors.Is(err, guide.ErrInvalidCron) // true — Is() reaches through both Join levels
+}
+
+// =============================================================================
+// Provider Capabilities: Reject What the Store Can't Do
+// =============================================================================
+//
+// Structural checks above don't know which provider the store uses. Once it's
+// resolved, each field maps to a capability (guide Pattern 36), and every
+// unsupported one is joined into the same kind of response — still before
+// any client is created or any network call is made.
+
+func capabilityRequirements(spec ExternalSecretSpec) []guide.CapabilityRequirement {
+	var reqs []guide.CapabilityRequirement
+	for i := range spec.Data {
+		reqs = append(reqs, guide.CapabilityRequirement{Field: fmt.Sprintf("spec.data[%d]", i), Needs: guide.CapGetSecret})
+	}
+	for i, ref := range spec.DataFrom {
+		if ref.Extract != nil {
+			reqs = append(reqs, guide.CapabilityRequirement{Field: fmt.Sprintf("spec.dataFrom[%d].extract", i), Needs: guide.CapGetSecretMap})
+		}
+		if ref.Find != nil {
+			reqs = append(reqs, guide.CapabilityRequirement{Field: fmt.Sprintf("spec.dataFrom[%d].find.name", i), Needs: guide.CapFindByName})
+		}
+		if len(ref.FindTags) > 0 {
+			reqs = append(reqs, guide.CapabilityRequirement{Field: fmt.Sprintf("spec.dataFrom[%d].find.tags", i), Needs: guide.CapFindByTag})
+		}
+	}
+	return reqs
+}
+
+// validateForProvider is validateGood plus the capability check.
+func validateForProvider(spec ExternalSecretSpec, providerName string) error {
+	return errors.Join(validateGood(spec), guide.ValidateCapabilitiesByName(providerName, capabilityRequirements(spec)))
+}
+
+func demonstrateCapabilityValidation() {
+	name := "^app-"
+	err := validateForProvider(ExternalSecretSpec{
+		SecretStoreRef: SecretStoreRef{Name: "vault"},
+		Data:           []DataEntry{{SecretKey: "password", RemoteRef: RemoteRef{Key: "db/password"}}},
+		DataFrom: []DataFromEntry{
+			{Find: &name},
+			{FindTags: map[string]string{"team": "payments"}},
+		},
+		Target: TargetSpec{DeletionPolicy: "Delete", CreationPolicy: "Merge"},
+	}, "vault")
+	fmt.Println(err)
+	// deletionPolicy=Delete must not be used when the controller doesn't own the secret. Please set creationPolicy=Owner
+	// spec.dataFrom[1].find.tags: operation not supported by provider: "vault" needs FindByTag (supports GetSecret|GetSecretMap|FindByName)
+
+	_ = errors.Is(err, guide.ErrUnsupportedOperation) // true
+}
+
 // --- Helper types ---
 
 type ExternalSecretSpec struct {
@@ -155,6 +262,9 @@ type ExternalSecretSpec struct {
 	Data           []DataEntry
 	DataFrom       []DataFromEntry
 	Target         TargetSpec
+
+	RefreshPolicy   string // "Periodic", "OnChange", "CreatedOnce", "Schedule"
+	RefreshSchedule string // cron expression for "Schedule"
 }
 
 type SecretStoreRef struct{ Name string }
@@ -165,7 +275,8 @@ type DataEntry struct {
 type RemoteRef struct{ Key string }
 type DataFromEntry struct {
 	Extract   *string
-	Find      *string
+	Find      *string           // find.name: regexp over secret names
+	FindTags  map[string]string // find.tags: match by provider tags/labels
 	Generator *string
 }
 type TargetSpec struct {
@@ -186,5 +297,7 @@ func init() {
 	_ = validateBad
 	_ = validateGood
 	_ = demonstrateErrorsIs
+	_ = demonstrateScheduleValidation
+	_ = demonstrateCapabilityValidation
 	_ = strings.Contains // suppress import
 }
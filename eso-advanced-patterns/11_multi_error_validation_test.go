@@ -0,0 +1,95 @@
+package eso_advanced_patterns
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	guide "design-patterns-guide"
+)
+
+func TestValidateRefreshSchedulePrefixesEveryCronError(t *testing.T) {
+	err := validateRefreshSchedule(ExternalSecretSpec{
+		RefreshPolicy:   "Schedule",
+		RefreshSchedule: "61 26 * * FUNDAY",
+	})
+	if !errors.Is(err, guide.ErrInvalidCron) {
+		t.Fatalf("err = %v, want ErrInvalidCron", err)
+	}
+	lines := strings.Split(err.Error(), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("want one line per bad field, got %d:\n%v", len(lines), err)
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "refreshSchedule: ") {
+			t.Errorf("line missing the field prefix: %q", line)
+		}
+	}
+}
+
+func TestCapabilityRequirementsMapEachFieldToItsCapability(t *testing.T) {
+	name, key := "^app-", "db"
+	reqs := capabilityRequirements(ExternalSecretSpec{
+		Data: []DataEntry{{SecretKey: "password", RemoteRef: RemoteRef{Key: "db/password"}}},
+		DataFrom: []DataFromEntry{
+			{Extract: &key},
+			{Find: &name, FindTags: map[string]string{"team": "payments"}}, // both halves of one find
+		},
+	})
+	want := []guide.CapabilityRequirement{
+		{Field: "spec.data[0]", Needs: guide.CapGetSecret},
+		{Field: "spec.dataFrom[0].extract", Needs: guide.CapGetSecretMap},
+		{Field: "spec.dataFrom[1].find.name", Needs: guide.CapFindByName},
+		{Field: "spec.dataFrom[1].find.tags", Needs: guide.CapFindByTag},
+	}
+	if len(reqs) != len(want) {
+		t.Fatalf("requirements = %+v, want %+v", reqs, want)
+	}
+	for i := range want {
+		if reqs[i] != want[i] {
+			t.Errorf("requirement %d = %+v, want %+v", i, reqs[i], want[i])
+		}
+	}
+}
+
+func TestValidateCapabilitiesRejectsFindByTagOnVault(t *testing.T) {
+	err := guide.ValidateCapabilitiesByName("vault", capabilityRequirements(ExternalSecretSpec{
+		Data:     []DataEntry{{SecretKey: "password", RemoteRef: RemoteRef{Key: "db/password"}}},
+		DataFrom: []DataFromEntry{{FindTags: map[string]string{"team": "payments"}}},
+	}))
+	if !errors.Is(err, guide.ErrUnsupportedOperation) {
+		t.Fatalf("err = %v, want ErrUnsupportedOperation", err)
+	}
+	if want := `spec.dataFrom[0].find.tags: operation not supported by provider: "vault" needs FindByTag`; !strings.HasPrefix(err.Error(), want) {
+		t.Fatalf("err = %q, want it to start with %q", err, want)
+	}
+	if strings.Contains(err.Error(), "spec.data[0]") {
+		t.Fatalf("a supported field was reported: %v", err)
+	}
+}
+
+func TestValidateForProviderReportsEveryUnsupportedField(t *testing.T) {
+	name := "^app-"
+	spec := ExternalSecretSpec{
+		SecretStoreRef: SecretStoreRef{Name: "files"},
+		Data:           []DataEntry{{SecretKey: "password", RemoteRef: RemoteRef{Key: "db/password"}}},
+		DataFrom: []DataFromEntry{
+			{Find: &name},
+			{FindTags: map[string]string{"team": "payments"}},
+			{FindTags: map[string]string{"env": "prod"}},
+		},
+	}
+	// The file provider (guide Pattern 39) reads by key only.
+	err := validateForProvider(spec, "file")
+	if !errors.Is(err, guide.ErrUnsupportedOperation) {
+		t.Fatalf("err = %v, want ErrUnsupportedOperation", err)
+	}
+	for _, field := range []string{"spec.dataFrom[0].find.name", "spec.dataFrom[1].find.tags", "spec.dataFrom[2].find.tags"} {
+		if !strings.Contains(err.Error(), field+": ") {
+			t.Errorf("%s not reported:\n%v", field, err)
+		}
+	}
+	if n := strings.Count(err.Error(), guide.ErrUnsupportedOperation.Error()); n != 3 {
+		t.Fatalf("%d unsupported fields reported, want 3:\n%v", n, err)
+	}
+}
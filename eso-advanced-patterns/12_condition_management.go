@@ -19,6 +19,8 @@ package eso_advanced_patterns
 import (
 	"fmt"
 	"time"
+
+	guide "design-patterns-guide"
 )
 
 // =============================================================================
@@ -57,8 +59,11 @@ func setConditionBad(status *ResourceStatus, newCond Condition) {
 //   - Set the NEW condition gauge to 1
 //
 // This gives you instant dashboards showing "how many ExternalSecrets are Ready?"
+//
+// The transition time comes from the injected clock (guide Pattern 25), so a
+// FakeClock can prove the timestamp survives hours of repeated failures.
 
-func SetCondition(status *ResourceStatus, newCond Condition, resourceName string) {
+func SetCondition(clock guide.Clock, status *ResourceStatus, newCond Condition, resourceName string) {
 	currentCond := getCondition(status, newCond.Type)
 
 	// Optimization: if nothing changed at all, just refresh the metric and return.
@@ -79,7 +84,7 @@ func SetCondition(status *ResourceStatus, newCond Condition, resourceName string
 		newCond.LastTransitionTime = currentCond.LastTransitionTime
 	} else {
 		// Status actually changed (or new condition) → record transition time
-		newCond.LastTransitionTime = time.Now()
+		newCond.LastTransitionTime = clock.Now()
 	}
 
 	// Replace old condition with new one
@@ -26,6 +26,8 @@ import (
 	"fmt"
 	"sync"
 	"time"
+
+	guide "design-patterns-guide"
 )
 
 // =============================================================================
@@ -151,7 +153,10 @@ func ReconcileWithTryLock(providerName, secretName string) error {
 // ReconcileWithRetry wraps ReconcileWithTryLock with explicit retry logic.
 // In a real controller, you DON'T need to write this — the workqueue does it
 // for you. This is here to illustrate what happens when TryLock fails.
-func ReconcileWithRetry(providerName, secretName string, maxRetries int) error {
+//
+// Sleeps go through the clock: with a guide.FakeClock the whole backoff
+// sequence runs instantly.
+func ReconcileWithRetry(clock guide.Clock, providerName, secretName string, maxRetries int) error {
 	backoff := NewRetryBackoff(100*time.Millisecond, 10*time.Second, 2.0)
 
 	var lastErr error
@@ -168,15 +173,15 @@ func ReconcileWithRetry(providerName, secretName string, maxRetries int) error {
 		}
 
 		// Wait with exponential backoff before retrying.
-		// In a real controller, you DON'T call time.Sleep — the workqueue's
+		// In a real controller, you DON'T sleep at all — the workqueue's
 		// rate limiter handles this. The goroutine goes back to processing
 		// other items, and the item is re-enqueued after the delay.
 		//
-		// We use time.Sleep here only for illustration purposes.
+		// We sleep here only for illustration purposes.
 		delay := backoff.NextDelay()
 		fmt.Printf("attempt %d: lock conflict for %s/%s, retrying in %v\n",
 			attempt+1, providerName, secretName, delay)
-		time.Sleep(delay)
+		clock.Sleep(delay)
 		lastErr = err
 	}
 	return fmt.Errorf("failed after %d retries: %w", maxRetries, lastErr)
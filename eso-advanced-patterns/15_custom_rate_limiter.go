@@ -17,9 +17,13 @@
 package eso_advanced_patterns
 
 import (
+	"errors"
 	"fmt"
 	"math"
+	"sync"
 	"time"
+
+	guide "design-patterns-guide"
 )
 
 // =============================================================================
@@ -61,27 +65,81 @@ import (
 //   - If backoff says "wait 0s" and bucket says "wait 2s" → wait 2s
 //   - This ensures both per-item and global limits are always respected
 
-// ExponentialBackoff simulates per-item exponential backoff.
-type ExponentialBackoff struct {
-	BaseDelay time.Duration
-	MaxDelay  time.Duration
+// Every limiter below implements guide.RateLimiter (Pattern 22), so any of
+// them can be handed to guide.NewWorkqueue or guide.ControllerOptions
+// directly. The per-item half is guide.ItemExponentialFailureRateLimiter
+// itself: BaseDelay * 2^failures, capped, reset by Forget.
+//
+// Real code: client-go/util/workqueue/default_rate_limiters.go:27-35
+
+var ErrInvalidRateLimit = errors.New("invalid rate limit")
+
+// TokenBucket is the global limiter: Rate tokens are added per second, up to
+// Burst. Every When() takes one token. If the bucket is empty, the token is
+// borrowed from the future and the caller waits until it would have arrived —
+// so 400 items over the burst are spread evenly at Rate per second.
+//
+// Items aren't tracked individually: Forget is a no-op and NumRequeues is 0.
+// Every field is unexported: a bucket without a rate can't compute a delay,
+// so the constructors are the only way to build one.
+//
+// Real code: client-go/util/workqueue/default_rate_limiters.go:44-64
+// (BucketRateLimiter wraps golang.org/x/time/rate.Limiter.Reserve)
+type TokenBucket[T comparable] struct {
+	rate  int         // tokens per second
+	burst int         // max burst size
+	clock guide.Clock // a guide.FakeClock simulates a minute of traffic without sleeping
+
+	mu     sync.Mutex
+	tokens float64   // may go negative: tokens reserved ahead of time
+	last   time.Time // when tokens was last brought up to date
+}
+
+// NewTokenBucket rejects rate <= 0 and burst <= 0: with no refill, the debt
+// in When would never be repaid, and the delay it computes goes negative —
+// which the workqueue reads as "retry now", i.e. no limit at all.
+func NewTokenBucket[T comparable](rate, burst int) (*TokenBucket[T], error) {
+	return NewTokenBucketWithClock[T](rate, burst, guide.RealClock{})
 }
 
-func (e *ExponentialBackoff) DelayForFailure(failures int) time.Duration {
-	delay := time.Duration(float64(e.BaseDelay) * math.Pow(2, float64(failures)))
-	if delay > e.MaxDelay {
-		return e.MaxDelay
+// NewTokenBucketWithClock is NewTokenBucket on an injected clock (Pattern 25).
+func NewTokenBucketWithClock[T comparable](rate, burst int, clock guide.Clock) (*TokenBucket[T], error) {
+	if rate <= 0 || burst <= 0 {
+		return nil, fmt.Errorf("%w: token bucket needs rate > 0 and burst > 0, got rate=%d burst=%d", ErrInvalidRateLimit, rate, burst)
 	}
-	return delay
+	if clock == nil {
+		clock = guide.RealClock{}
+	}
+	return &TokenBucket[T]{rate: rate, burst: burst, clock: clock}, nil
 }
 
-// TokenBucket simulates global rate limiting.
-type TokenBucket struct {
-	Rate  int // tokens per second
-	Burst int // max burst size
+func (b *TokenBucket[T]) When(item T) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.clock.Now()
+	if b.last.IsZero() {
+		// First use: the bucket starts full.
+		b.tokens = float64(b.burst)
+	} else {
+		// Refill for the time that passed, never beyond Burst.
+		elapsed := now.Sub(b.last).Seconds()
+		b.tokens = math.Min(float64(b.burst), b.tokens+elapsed*float64(b.rate))
+	}
+	b.last = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	// In debt: wait until enough tokens have accrued to cover it.
+	return time.Duration(-b.tokens / float64(b.rate) * float64(time.Second))
 }
 
-// MaxOfLimiter returns the worst-case (longest) delay from multiple limiters.
+func (b *TokenBucket[T]) Forget(item T)          {}
+func (b *TokenBucket[T]) NumRequeues(item T) int { return 0 }
+
+// MaxOfDelay returns the worst-case (longest) delay from multiple limiters.
 // This is the key insight: you don't pick one limiter, you combine them.
 func MaxOfDelay(delays ...time.Duration) time.Duration {
 	max := time.Duration(0)
@@ -93,6 +151,43 @@ func MaxOfDelay(delays ...time.Duration) time.Duration {
 	return max
 }
 
+// MaxOfRateLimiter asks EVERY limiter and waits for the slowest. Every
+// limiter must see every When() call — skipping one would let the token
+// bucket miss traffic or the backoff miss a failure.
+//
+// Real code: client-go/util/workqueue/default_rate_limiters.go:197-237
+type MaxOfRateLimiter[T comparable] struct {
+	limiters []guide.RateLimiter[T]
+}
+
+func NewMaxOfRateLimiter[T comparable](limiters ...guide.RateLimiter[T]) *MaxOfRateLimiter[T] {
+	return &MaxOfRateLimiter[T]{limiters: limiters}
+}
+
+func (m *MaxOfRateLimiter[T]) When(item T) time.Duration {
+	delays := make([]time.Duration, len(m.limiters))
+	for i, l := range m.limiters {
+		delays[i] = l.When(item)
+	}
+	return MaxOfDelay(delays...)
+}
+
+func (m *MaxOfRateLimiter[T]) Forget(item T) {
+	for _, l := range m.limiters {
+		l.Forget(item)
+	}
+}
+
+func (m *MaxOfRateLimiter[T]) NumRequeues(item T) int {
+	max := 0
+	for _, l := range m.limiters {
+		if n := l.NumRequeues(item); n > max {
+			max = n
+		}
+	}
+	return max
+}
+
 // BuildRateLimiter creates a custom rate limiter for ESO controllers.
 //
 // Real code:
@@ -107,15 +202,18 @@ func MaxOfDelay(delays ...time.Duration) time.Duration {
 //       return workqueue.NewTypedMaxOfRateLimiter[reconcile.Request](
 //           failureRateLimiter, totalRateLimiter)
 //   }
+func BuildRateLimiter[T comparable]() guide.RateLimiter[T] {
+	failureRateLimiter := guide.NewItemExponentialFailureRateLimiter[T](1*time.Second, 7*time.Minute)
+	totalRateLimiter, _ := NewTokenBucket[T](10, 100) // constant, valid arguments
+	return NewMaxOfRateLimiter[T](failureRateLimiter, totalRateLimiter)
+}
+
 func demonstrateRateLimiter() {
-	backoff := &ExponentialBackoff{
-		BaseDelay: 1 * time.Second,
-		MaxDelay:  7 * time.Minute,
-	}
+	backoff := guide.NewItemExponentialFailureRateLimiter[string](1*time.Second, 7*time.Minute)
 
 	// Simulate successive failures for one item
 	for i := 0; i < 10; i++ {
-		delay := backoff.DelayForFailure(i)
+		delay := backoff.When("default/my-es")
 		fmt.Printf("Failure %d: retry after %v\n", i+1, delay)
 	}
 	// Output:
@@ -129,6 +227,35 @@ func demonstrateRateLimiter() {
 	// Failure 8: retry after 2m8s
 	// Failure 9: retry after 4m16s
 	// Failure 10: retry after 7m (capped at MaxDelay)
+
+	// Success resets the item's backoff.
+	backoff.Forget("default/my-es")
+	fmt.Println("After Forget:", backoff.When("default/my-es")) // 1s
+}
+
+// demonstrateThunderingHerd replays a provider outage: 500 ExternalSecrets
+// fail at the same instant. A frozen fake clock makes the result exact.
+func demonstrateThunderingHerd() {
+	bucket, _ := NewTokenBucketWithClock[string](10, 100, guide.NewFakeClock(time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)))
+	limiter := NewMaxOfRateLimiter[string](
+		guide.NewItemExponentialFailureRateLimiter[string](1*time.Second, 7*time.Minute),
+		bucket,
+	)
+
+	_, err := NewTokenBucket[string](0, 100)
+	fmt.Println(err) // invalid rate limit: token bucket needs rate > 0 and burst > 0, got rate=0 burst=100
+
+	var last time.Duration
+	immediate := 0
+	for i := 0; i < 500; i++ {
+		delay := limiter.When(fmt.Sprintf("default/es-%d", i))
+		if delay <= 1*time.Second {
+			immediate++ // only the per-item 1s backoff applies
+		}
+		last = delay
+	}
+	fmt.Printf("retry within 1s: %d\n", immediate)                // 110 (burst of 100 + 10 more tokens in the first second)
+	fmt.Printf("last retry after: %v\n", last.Round(time.Second)) // 40s — the remaining 400 spread at 10/sec
 }
 
 // =============================================================================
@@ -165,4 +292,5 @@ func demonstrateRateLimiter() {
 
 func init() {
 	_ = demonstrateRateLimiter
+	_ = demonstrateThunderingHerd
 }
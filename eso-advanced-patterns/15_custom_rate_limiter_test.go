@@ -0,0 +1,147 @@
+package eso_advanced_patterns
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	guide "design-patterns-guide"
+)
+
+var testEpoch = time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+func newTestBucket(t *testing.T, rate, burst int) (*TokenBucket[string], *guide.FakeClock) {
+	t.Helper()
+	clock := guide.NewFakeClock(testEpoch)
+	bucket, err := NewTokenBucketWithClock[string](rate, burst, clock)
+	if err != nil {
+		t.Fatalf("NewTokenBucketWithClock(%d, %d) = %v", rate, burst, err)
+	}
+	return bucket, clock
+}
+
+func TestNewTokenBucketRejectsNonPositiveArguments(t *testing.T) {
+	for _, tc := range []struct{ rate, burst int }{{0, 100}, {-1, 100}, {10, 0}, {10, -5}} {
+		if _, err := NewTokenBucket[string](tc.rate, tc.burst); !errors.Is(err, ErrInvalidRateLimit) {
+			t.Errorf("NewTokenBucket(%d, %d) = %v, want ErrInvalidRateLimit", tc.rate, tc.burst, err)
+		}
+		if _, err := NewTokenBucketWithClock[string](tc.rate, tc.burst, guide.NewFakeClock(testEpoch)); !errors.Is(err, ErrInvalidRateLimit) {
+			t.Errorf("NewTokenBucketWithClock(%d, %d) = %v, want ErrInvalidRateLimit", tc.rate, tc.burst, err)
+		}
+	}
+}
+
+// A nil clock falls back to real time rather than panicking in When.
+func TestNewTokenBucketWithNilClockUsesRealTime(t *testing.T) {
+	bucket, err := NewTokenBucketWithClock[string](10, 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := bucket.When("k"); d != 0 {
+		t.Fatalf("first call: delay %v, want 0", d)
+	}
+}
+
+func TestTokenBucketBurstThenEvenSpacing(t *testing.T) {
+	bucket, _ := newTestBucket(t, 10, 100)
+
+	for i := 0; i < 100; i++ {
+		if d := bucket.When("k"); d != 0 {
+			t.Fatalf("call %d within burst: delay %v, want 0", i+1, d)
+		}
+	}
+	// Beyond the burst, each call waits one more 1/rate slot. The clock is
+	// frozen, so no tokens come back in between.
+	for i := 1; i <= 50; i++ {
+		want := time.Duration(i) * 100 * time.Millisecond
+		if d := bucket.When("k"); d < want-time.Microsecond || d > want+time.Microsecond {
+			t.Fatalf("call %d over burst: delay %v, want %v", i, d, want)
+		}
+	}
+}
+
+func TestTokenBucketRefillsWithTimeButNotPastBurst(t *testing.T) {
+	bucket, clock := newTestBucket(t, 10, 5)
+	for i := 0; i < 5; i++ {
+		bucket.When("k")
+	}
+	if d := bucket.When("k"); d <= 0 {
+		t.Fatalf("empty bucket: delay %v, want > 0", d)
+	}
+
+	clock.Step(time.Hour) // far more than needed to refill
+	for i := 0; i < 5; i++ {
+		if d := bucket.When("k"); d != 0 {
+			t.Fatalf("call %d after refill: delay %v, want 0", i+1, d)
+		}
+	}
+	if d := bucket.When("k"); d <= 0 {
+		t.Fatalf("bucket refilled past its burst of 5: delay %v", d)
+	}
+}
+
+func TestTokenBucketDelayIsNeverNegative(t *testing.T) {
+	bucket, clock := newTestBucket(t, 1, 1)
+	for i := 0; i < 1000; i++ {
+		if d := bucket.When("k"); d < 0 {
+			t.Fatalf("call %d: negative delay %v", i+1, d)
+		}
+		if i%7 == 0 {
+			clock.Step(300 * time.Millisecond)
+		}
+	}
+}
+
+func TestMaxOfRateLimiterTakesTheLongerDelay(t *testing.T) {
+	bucket, clock := newTestBucket(t, 10, 2)
+	backoff := guide.NewItemExponentialFailureRateLimiter[string](time.Second, 7*time.Minute)
+	limiter := NewMaxOfRateLimiter[string](backoff, bucket)
+
+	if d := limiter.When("a"); d != time.Second {
+		t.Fatalf("first failure: %v, want the 1s backoff (bucket has tokens)", d)
+	}
+	if d := limiter.When("a"); d != 2*time.Second {
+		t.Fatalf("second failure: %v, want the 2s backoff", d)
+	}
+
+	// Bucket empty: a fresh item's 1s backoff loses to the bucket's wait.
+	for i := 0; i < 20; i++ {
+		limiter.When(fmt.Sprintf("other-%d", i))
+	}
+	if d := limiter.When("b"); d <= time.Second {
+		t.Fatalf("drained bucket: %v, want more than the 1s backoff", d)
+	}
+
+	limiter.Forget("a")
+	if got := limiter.NumRequeues("a"); got != 0 {
+		t.Fatalf("NumRequeues after Forget = %d, want 0", got)
+	}
+	clock.Step(time.Minute)
+	if d := limiter.When("a"); d != time.Second {
+		t.Fatalf("after Forget and refill: %v, want the base 1s", d)
+	}
+}
+
+// The limiters plug into the guide's workqueue: a thundering herd of 500
+// failures becomes 100 immediate retries and 400 spread at 10/sec.
+func TestTokenBucketDrivesWorkqueueWithFakeClock(t *testing.T) {
+	bucket, clock := newTestBucket(t, 10, 100)
+	q := guide.NewWorkqueueWithClock[string](bucket, clock)
+	defer q.ShutDown()
+
+	for i := 0; i < 500; i++ {
+		q.AddRateLimited(fmt.Sprintf("default/es-%d", i))
+	}
+	if got := q.Len(); got != 100 {
+		t.Fatalf("ready immediately = %d, want the burst of 100", got)
+	}
+	clock.Step(10 * time.Second)
+	if got := q.Len(); got != 200 {
+		t.Fatalf("ready after 10s = %d, want 200", got)
+	}
+	clock.Step(30 * time.Second)
+	if got := q.Len(); got != 500 {
+		t.Fatalf("ready after 40s = %d, want all 500", got)
+	}
+}
@@ -19,8 +19,11 @@
 package eso_advanced_patterns
 
 import (
+	"context"
 	"fmt"
 	"sync"
+
+	guide "design-patterns-guide"
 )
 
 // =============================================================================
@@ -346,10 +349,12 @@ func demonstrateBuildManagedSecretClient() {
 			labels["reconcile.external-secrets.io/managed"] = "true"
 		}
 		apiServer.Create(CachedObject{
-			Type:      "Secret",
-			Name:      fmt.Sprintf("secret-%d", i),
-			Namespace: "default",
-			Labels:    labels,
+			ObjectMeta: guide.ObjectMeta{
+				Name:      fmt.Sprintf("secret-%d", i),
+				Namespace: "default",
+				Labels:    labels,
+			},
+			Type: "Secret",
 		})
 	}
 
@@ -398,42 +403,53 @@ func demonstrateBuildManagedSecretClient() {
 // --- Helper types ---
 
 type CachedObject struct {
-	Type      string
-	Name      string
-	Namespace string
-	Labels    map[string]string
-	Data      map[string]string
+	guide.ObjectMeta
+	Type string
+	Data map[string]string
 }
 
+// GetKind lets the fake API server key objects by Type ("Secret",
+// "Deployment", ...) rather than by Go type name.
+func (o *CachedObject) GetKind() string { return o.Type }
+
 func (o CachedObject) Key() string {
 	return o.Namespace + "/" + o.Name
 }
 
+// MockAPIServer is a thin CachedObject view over the shared in-memory API
+// server (Pattern 24): writes get resourceVersions and conflict checks, and
+// Watch streams the events an informer would receive.
 type MockAPIServer struct {
-	objects []CachedObject
+	server *guide.FakeAPIServer
 }
 
 func NewMockAPIServer() *MockAPIServer {
-	return &MockAPIServer{}
+	return &MockAPIServer{server: guide.NewFakeAPIServer()}
 }
 
 func (s *MockAPIServer) Create(obj CachedObject) error {
-	s.objects = append(s.objects, obj)
-	return nil
+	return s.server.Create(context.Background(), &obj)
 }
 
 func (s *MockAPIServer) Update(obj CachedObject) error {
-	for i, existing := range s.objects {
-		if existing.Key() == obj.Key() {
-			s.objects[i] = obj
-			return nil
+	return s.server.Update(context.Background(), &obj)
+}
+
+func (s *MockAPIServer) ListAll() []CachedObject {
+	objs, _ := s.server.List(context.Background(), guide.ListOptions{})
+	result := make([]CachedObject, 0, len(objs))
+	for _, obj := range objs {
+		if cached, ok := obj.(*CachedObject); ok {
+			result = append(result, *cached)
 		}
 	}
-	return fmt.Errorf("object %q not found", obj.Key())
+	return result
 }
 
-func (s *MockAPIServer) ListAll() []CachedObject {
-	return s.objects
+// Watch streams Added/Modified/Deleted events until ctx is cancelled.
+// Feeding these into LabelFilteredCache.OnEvent is what an informer does.
+func (s *MockAPIServer) Watch(ctx context.Context) <-chan guide.WatchEvent {
+	return s.server.Watch(ctx, guide.ListOptions{})
 }
 
 func init() {
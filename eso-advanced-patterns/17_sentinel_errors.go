@@ -23,8 +23,11 @@
 package eso_advanced_patterns
 
 import (
+	"context"
 	"errors"
 	"fmt"
+
+	guide "design-patterns-guide"
 )
 
 // =============================================================================
@@ -96,7 +99,7 @@ func getSecretFromProvider(key string) ([]byte, error) {
 }
 
 // Controller — checks sentinel to determine action
-func reconcileSecret(key string) error {
+func reconcileSecret(ctx context.Context, deleter *guide.DeletionPolicyExecutor, es *guide.ExternalSecret, target, deletionPolicy, key string) error {
 	data, err := getSecretFromProvider(key)
 	if err != nil {
 		// errors.Is traverses the wrap chain:
@@ -104,8 +107,9 @@ func reconcileSecret(key string) error {
 		// → still matches NoSecretErr!
 		if errors.Is(err, NoSecretErr) {
 			// Not an error — the source secret was intentionally deleted.
-			// Apply deletion policy: delete the K8s secret, or keep it, etc.
-			return applyDeletionPolicy(key)
+			// Apply deletion policy: delete the K8s secret, keep it, or remove
+			// only our keys — the same engine the finalizer uses.
+			return applyDeletionPolicy(ctx, deleter, es, target, deletionPolicy)
 		}
 		// Actual error (auth failure, network timeout, etc.)
 		// This SHOULD be retried with backoff.
@@ -137,8 +141,14 @@ func reconcileSecret(key string) error {
 
 // --- Helper functions ---
 
-func applyDeletionPolicy(key string) error {
-	fmt.Printf("applying deletion policy for %s\n", key)
+// applyDeletionPolicy hands the NoSecretErr case to guide Pattern 35, so
+// "Retain" and "Merge" mean exactly what they mean on finalization.
+func applyDeletionPolicy(ctx context.Context, deleter *guide.DeletionPolicyExecutor, es *guide.ExternalSecret, target, deletionPolicy string) error {
+	out, err := deleter.Apply(ctx, guide.TriggerSourceDeleted, es, target, deletionPolicy)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("applied deletion policy for %s: %s\n", target, out)
 	return nil
 }
 
@@ -147,7 +157,30 @@ func createOrUpdateSecret(key string, data []byte) error {
 	return nil
 }
 
+func demonstrateNoSecretDeletionPolicy() {
+	ctx := context.Background()
+	client := guide.NewAPIServerSecretClient(guide.NewFakeAPIServer())
+	deleter := guide.NewDeletionPolicyExecutor(client)
+
+	// A Secret this ExternalSecret created on an earlier sync.
+	es := &guide.ExternalSecret{Name: "db", Namespace: "default", UID: "uid-db"}
+	client.CreateSecret(ctx, &guide.Secret{
+		ObjectMeta: guide.ObjectMeta{Name: "db-creds", Namespace: "default", Labels: map[string]string{
+			guide.LabelOwner:   guide.OwnerLabelValue("default", "db"),
+			guide.LabelManaged: "true",
+		}},
+		Data: map[string][]byte{"password": []byte("s3cret")},
+	})
+
+	// The provider says the source is gone: Merge empties our keys.
+	err := reconcileSecret(ctx, deleter, es, "db-creds", "Merge", "db-password")
+	fmt.Println(err)
+	// applied deletion policy for db-creds: SourceDeleted/Merge: removed keys [password], ownership removed=true
+	// <nil>
+}
+
 func init() {
 	_ = handleErrorBad
 	_ = reconcileSecret
+	_ = demonstrateNoSecretDeletionPolicy
 }
@@ -12,14 +12,29 @@
 // you human-readable names for the common case (short names) and collision-
 // free names for the edge case (long names).
 //
+// The names are only useful if something consumes them. The last section is
+// that consumer: a managed-fields tracker that gives the in-memory Secret
+// (guide Pattern 07) server-side-apply ownership, stored in its
+// ObjectMeta.ManagedFields, so two ExternalSecrets writing the same Secret
+// see each other as distinct field managers.
+//
 // REAL CODE REFERENCE:
 //   pkg/controllers/externalsecret/util.go:88-96
+//   k8s.io/apimachinery/pkg/util/managedfields (conflict detection, force)
 
 package eso_advanced_patterns
 
 import (
+	"context"
 	"crypto/sha256"
+	"errors"
 	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	guide "design-patterns-guide"
 )
 
 // =============================================================================
@@ -113,6 +128,163 @@ func demonstrateFQDN() {
 	fmt.Printf("Collision: %v\n", long1 == long2) // false
 }
 
+// =============================================================================
+// Reversible Names: Readable Prefix + Hash Suffix, and a Registry
+// =============================================================================
+//
+// The hash fallback is collision-free but one-way: "externalsecrets.6be38b94..."
+// tells on-call nothing. Two fixes together:
+//
+//   1. Keep as much of the name as fits, then a short hash of the FULL name:
+//        externalsecrets.my-very-long-application-secret-na-6be38b9453ed
+//      Still unique (the hash covers what was cut), still under 63, and
+//      still one DNS-1123 label: '_', '.' and uppercase are folded to '-'.
+//   2. Remember every name handed out, so any field owner — readable, legacy
+//      hashed, or short — can be mapped back to its ExternalSecret.
+//
+// The legacy fqdnFor names stay what they were (see the compatibility note
+// below); the registry records them too so old managedFields still resolve.
+
+const readableHashLen = 12 // hex chars: 48 bits of the full name's SHA-256
+
+var (
+	ErrInvalidFieldOwner   = errors.New("invalid field owner name")
+	ErrFieldOwnerCollision = errors.New("field owner name collision")
+)
+
+var (
+	dns1123Label      = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	notDNS1123Chars   = regexp.MustCompile(`[^-a-z0-9]+`)
+	readableHashShape = regexp.MustCompile(fmt.Sprintf(`-[0-9a-f]{%d}$`, readableHashLen))
+	fieldOwnerPrefix  = fmt.Sprintf(fieldOwnerTemplate, "")
+	maxReadablePrefix = maxDNSLabelLength - len(fieldOwnerPrefix) - 1 - readableHashLen
+)
+
+// fqdnForReadable is fqdnFor with a readable fallback. What follows the
+// "externalsecrets." prefix is always a single DNS-1123 label: a name that
+// already is one (and fits) is kept; anything else — uppercase, '_', '.',
+// or too long — is folded to [-a-z0-9], trimmed to fit, and suffixed with a
+// hash of the ORIGINAL name, so "my_.es" and "my-.es" stay distinct. A
+// valid name that already ends in a hash-shaped suffix is hashed too, so it
+// can't pass through unchanged onto another name's folded output.
+func fqdnForReadable(name string) string {
+	if dns1123Label.MatchString(name) && !readableHashShape.MatchString(name) &&
+		len(fieldOwnerPrefix)+len(name) <= maxDNSLabelLength {
+		return fieldOwnerPrefix + name
+	}
+	hash := sha256.Sum256([]byte(name))
+	prefix := notDNS1123Chars.ReplaceAllString(strings.ToLower(name), "-")
+	if len(prefix) > maxReadablePrefix {
+		prefix = prefix[:maxReadablePrefix]
+	}
+	prefix = strings.Trim(prefix, "-")
+	if prefix == "" {
+		return fmt.Sprintf(fieldOwnerTemplateSha, hash[:readableHashLen/2])
+	}
+	return fieldOwnerPrefix + prefix + "-" + fmt.Sprintf("%x", hash[:readableHashLen/2])
+}
+
+// validateFieldOwner checks a generated name: the "externalsecrets." prefix
+// followed by one DNS-1123 label (lowercase alphanumerics and '-', starting
+// and ending alphanumeric, no dots), 63 characters in all. Every violation
+// is reported.
+//
+// Real code: k8s.io/apimachinery/pkg/util/validation/validation.go (IsDNS1123Label)
+func validateFieldOwner(name string) error {
+	var errs error
+	if len(name) > maxDNSLabelLength {
+		errs = errors.Join(errs, fmt.Errorf("%w %q: %d characters, must be no more than %d", ErrInvalidFieldOwner, name, len(name), maxDNSLabelLength))
+	}
+	label, ok := strings.CutPrefix(name, fieldOwnerPrefix)
+	if !ok {
+		errs = errors.Join(errs, fmt.Errorf("%w %q: must start with %q", ErrInvalidFieldOwner, name, fieldOwnerPrefix))
+	}
+	if !dns1123Label.MatchString(label) {
+		errs = errors.Join(errs, fmt.Errorf("%w %q: %q is not a DNS-1123 label", ErrInvalidFieldOwner, name, label))
+	}
+	return errs
+}
+
+// FieldOwnerRegistry maps field owner names back to ExternalSecret names.
+// Safe for concurrent use.
+type FieldOwnerRegistry struct {
+	mu      sync.RWMutex
+	byOwner map[string]string // field owner → ExternalSecret name
+}
+
+func NewFieldOwnerRegistry() *FieldOwnerRegistry {
+	return &FieldOwnerRegistry{byOwner: make(map[string]string)}
+}
+
+// Register returns the readable field owner for esName, and records both it
+// and the legacy fqdnFor name for Lookup. Registering the same name twice is
+// a no-op; two names producing one owner is an error, never a silent overwrite.
+func (r *FieldOwnerRegistry) Register(esName string) (string, error) {
+	owner := fqdnForReadable(esName)
+	if err := validateFieldOwner(owner); err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, name := range []string{owner, fqdnFor(esName)} {
+		if prev, ok := r.byOwner[name]; ok && prev != esName {
+			return "", fmt.Errorf("%w: %q and %q both map to %q", ErrFieldOwnerCollision, prev, esName, name)
+		}
+	}
+	r.byOwner[owner] = esName
+	r.byOwner[fqdnFor(esName)] = esName
+	return owner, nil
+}
+
+// Lookup answers "which ExternalSecret is this field manager?"
+func (r *FieldOwnerRegistry) Lookup(owner string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	name, ok := r.byOwner[owner]
+	return name, ok
+}
+
+func demonstrateReversibleNames() {
+	registry := NewFieldOwnerRegistry()
+	long := "my-very-long-application-secret-name-production-us-east-1-database"
+
+	short, _ := registry.Register("db-creds")
+	readable, _ := registry.Register(long)
+	fmt.Println(short)                               // externalsecrets.db-creds
+	fmt.Println(readable, len(readable))             // externalsecrets.my-very-long-application-secret-na-6be38b9453ed 63
+	fmt.Println(validateFieldOwner(readable) == nil) // true
+
+	// A Secret written before readable names existed: its password field is
+	// recorded under the legacy hashed owner. On-call can still resolve it.
+	tracker := NewManagedFieldsTracker()
+	secret := &guide.Secret{ObjectMeta: guide.ObjectMeta{Name: "shared", Namespace: "default"}}
+	tracker.Apply(secret, fqdnFor(long), ApplyConfiguration{Data: map[string][]byte{"password": []byte("x")}}, false)
+	for _, m := range tracker.Managers(secret, FieldPath{Section: "data", Key: "password"}) {
+		name, ok := registry.Lookup(m)
+		fmt.Println(m, "→", name, ok) // externalsecrets.6be38b94... → my-very-long-...-database true
+	}
+
+	// The next write uses the readable owner, and takes over the legacy
+	// owner's fields instead of conflicting with its former self.
+	err := tracker.ApplyForExternalSecret(secret, long, ApplyConfiguration{Data: map[string][]byte{"password": []byte("y")}}, false)
+	fmt.Println(err, tracker.Managers(secret, FieldPath{Section: "data", Key: "password"}))
+	// <nil> [externalsecrets.my-very-long-application-secret-na-6be38b9453ed]
+
+	// Names that aren't a single label are folded, never passed through:
+	// dots, '_' and uppercase all become '-', and the hash keeps them apart.
+	for _, name := range []string{"Payments_DB.prod", "my_.es", "a..b"} {
+		owner, _ := registry.Register(name)
+		fmt.Println(owner, validateFieldOwner(owner) == nil)
+	}
+	// externalsecrets.payments-db-prod-<12 hex> true
+	// externalsecrets.my-es-<12 hex> true
+	// externalsecrets.a-b-<12 hex> true
+
+	fmt.Println(validateFieldOwner("externalsecrets.a..b"))
+	// invalid field owner name "externalsecrets.a..b": "a..b" is not a DNS-1123 label
+}
+
 // =============================================================================
 // Where This Pattern Applies
 // =============================================================================
@@ -128,6 +300,9 @@ func demonstrateFQDN() {
 // The key insight is: MOST names are short (under 30 chars). Making the common
 // case readable and only falling back to hashes for the rare long-name case
 // gives you the best of both worlds: debuggability AND correctness.
+//
+// Package k8sname (design-patterns-guide/k8sname) generalizes this to any
+// label, subdomain or label value, with a collision check over realistic inputs.
 
 // =============================================================================
 // Backwards Compatibility Note
@@ -140,8 +315,325 @@ func demonstrateFQDN() {
 // when fixing naming constraints: don't change existing names, only fix
 // the generation logic for new ones.
 
+// =============================================================================
+// Consumer: Managed Fields (Server-Side Apply Ownership)
+// =============================================================================
+//
+// Real code: the reconciler writes with client.FieldOwner(fqdnFor(es.Name)),
+// and the API server records that name in metadata.managedFields for every
+// field it set. A second manager applying a DIFFERENT value to an owned
+// field gets a conflict instead of silently winning — unless it forces.
+//
+//   field               managers
+//   data[password]      externalsecrets.db-creds
+//   data[tls.crt]       externalsecrets.certs
+//   labels[app]         externalsecrets.db-creds, externalsecrets.certs   ← same value, shared
+
+// ErrFieldConflict is wrapped by every *FieldConflictError.
+var ErrFieldConflict = errors.New("field manager conflict")
+
+// FieldPath names one data key, label or annotation of a Secret.
+type FieldPath = guide.FieldPath
+
+// ApplyConfiguration is everything one manager wants set. Fields it applied
+// before and leaves out now are released (and removed if nobody else owns them).
+type ApplyConfiguration struct {
+	Data        map[string][]byte
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+func (c ApplyConfiguration) fields() map[FieldPath]string {
+	out := make(map[FieldPath]string)
+	for k, v := range c.Data {
+		out[FieldPath{Section: "data", Key: k}] = string(v)
+	}
+	for k, v := range c.Labels {
+		out[FieldPath{Section: "labels", Key: k}] = v
+	}
+	for k, v := range c.Annotations {
+		out[FieldPath{Section: "annotations", Key: k}] = v
+	}
+	return out
+}
+
+// FieldConflict is one field another manager owns with a different value.
+type FieldConflict struct {
+	Field    FieldPath
+	Managers []string
+}
+
+// FieldConflictError lists every conflict of one Apply, like the API
+// server's "Apply failed with N conflicts" response.
+type FieldConflictError struct {
+	Manager   string
+	Conflicts []FieldConflict
+}
+
+func (e *FieldConflictError) Error() string {
+	parts := make([]string, len(e.Conflicts))
+	for i, c := range e.Conflicts {
+		parts[i] = fmt.Sprintf("conflict with %q: %s", strings.Join(c.Managers, ","), c.Field)
+	}
+	return fmt.Sprintf("%s: apply failed with %d conflicts: %s", e.Manager, len(e.Conflicts), strings.Join(parts, "; "))
+}
+
+func (e *FieldConflictError) Unwrap() error { return ErrFieldConflict }
+
+// ManagedFieldsTracker applies configurations with field-manager ownership.
+// It keeps no state of its own: who owns what is read from and written back
+// to secret.ManagedFields, so it persists through FakeAPIServer updates and
+// every client sees it — exactly like metadata.managedFields. Two writers
+// racing on one Secret are serialized by resourceVersion (ErrConflict on
+// Update), not by the tracker.
+type ManagedFieldsTracker struct{}
+
+func NewManagedFieldsTracker() *ManagedFieldsTracker {
+	return &ManagedFieldsTracker{}
+}
+
+// ApplyForExternalSecret applies as the field manager fqdnForReadable(esName).
+// Fields still recorded under the legacy fqdnFor name — written before the
+// readable names existed — are the same ExternalSecret's, so they're handed
+// over first instead of conflicting with it.
+func (t *ManagedFieldsTracker) ApplyForExternalSecret(secret *guide.Secret, esName string, cfg ApplyConfiguration, force bool) error {
+	manager := fqdnForReadable(esName)
+	if legacy := fqdnFor(esName); legacy != manager {
+		renameManager(&secret.ObjectMeta, legacy, manager)
+	}
+	return t.Apply(secret, manager, cfg, force)
+}
+
+// renameManager moves every field owned by from to to.
+//
+// Real code: k8s.io/client-go/util/csaupgrade (UpgradeManagedFields)
+func renameManager(meta *guide.ObjectMeta, from, to string) {
+	owned := readManagedFields(meta)
+	for _, managers := range owned {
+		if managers[from] {
+			delete(managers, from)
+			managers[to] = true
+		}
+	}
+	writeManagedFields(meta, owned)
+}
+
+// Apply writes cfg into secret as manager. Nothing is written if any field
+// conflicts and force is false; with force, the conflicting managers lose
+// ownership of those fields. Fields present on the Secret but owned by
+// nobody (written without a field manager) are taken over without conflict.
+func (t *ManagedFieldsTracker) Apply(secret *guide.Secret, manager string, cfg ApplyConfiguration, force bool) error {
+	owned := readManagedFields(&secret.ObjectMeta)
+	desired := cfg.fields()
+
+	// Pass 1: find conflicts, change nothing.
+	var conflicts []FieldConflict
+	for field, value := range desired {
+		live, exists := getField(secret, field)
+		if !exists || live == value {
+			continue // same value: shared ownership, never a conflict
+		}
+		var others []string
+		for m := range owned[field] {
+			if m != manager {
+				others = append(others, m)
+			}
+		}
+		if len(others) > 0 {
+			sort.Strings(others)
+			conflicts = append(conflicts, FieldConflict{Field: field, Managers: others})
+		}
+	}
+	if len(conflicts) > 0 && !force {
+		sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Field.String() < conflicts[j].Field.String() })
+		return &FieldConflictError{Manager: manager, Conflicts: conflicts}
+	}
+	for _, c := range conflicts {
+		owned[c.Field] = nil // force: the others lose these fields
+	}
+
+	// Pass 2: release fields this manager no longer applies.
+	for field, managers := range owned {
+		if _, still := desired[field]; still || !managers[manager] {
+			continue
+		}
+		delete(managers, manager)
+		if len(managers) == 0 {
+			delete(owned, field)
+			deleteField(secret, field)
+		}
+	}
+
+	// Pass 3: write and record.
+	for field, value := range desired {
+		setField(secret, field, value)
+		if owned[field] == nil {
+			owned[field] = make(map[string]bool)
+		}
+		owned[field][manager] = true
+	}
+	writeManagedFields(&secret.ObjectMeta, owned)
+	return nil
+}
+
+// Managers returns who owns a field, sorted.
+func (t *ManagedFieldsTracker) Managers(secret *guide.Secret, field FieldPath) []string {
+	var out []string
+	for m := range readManagedFields(&secret.ObjectMeta)[field] {
+		out = append(out, m)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// readManagedFields indexes metadata.managedFields by field.
+func readManagedFields(meta *guide.ObjectMeta) map[FieldPath]map[string]bool {
+	owned := make(map[FieldPath]map[string]bool)
+	for _, entry := range meta.ManagedFields {
+		for _, field := range entry.Fields {
+			if owned[field] == nil {
+				owned[field] = make(map[string]bool)
+			}
+			owned[field][entry.Manager] = true
+		}
+	}
+	return owned
+}
+
+// writeManagedFields stores the index back as one entry per manager, sorted,
+// so the same ownership always serializes the same way.
+func writeManagedFields(meta *guide.ObjectMeta, owned map[FieldPath]map[string]bool) {
+	byManager := make(map[string][]FieldPath)
+	for field, managers := range owned {
+		for m := range managers {
+			byManager[m] = append(byManager[m], field)
+		}
+	}
+	meta.ManagedFields = nil
+	for _, m := range sortedManagers(byManager) {
+		fields := byManager[m]
+		sort.Slice(fields, func(i, j int) bool { return fields[i].String() < fields[j].String() })
+		meta.ManagedFields = append(meta.ManagedFields, guide.ManagedFieldsEntry{Manager: m, Operation: "Apply", Fields: fields})
+	}
+}
+
+func sortedManagers(m map[string][]FieldPath) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func getField(s *guide.Secret, f FieldPath) (string, bool) {
+	switch f.Section {
+	case "data":
+		v, ok := s.Data[f.Key]
+		return string(v), ok
+	case "labels":
+		v, ok := s.Labels[f.Key]
+		return v, ok
+	default:
+		v, ok := s.Annotations[f.Key]
+		return v, ok
+	}
+}
+
+func setField(s *guide.Secret, f FieldPath, value string) {
+	switch f.Section {
+	case "data":
+		if s.Data == nil {
+			s.Data = make(map[string][]byte)
+		}
+		s.Data[f.Key] = []byte(value)
+	case "labels":
+		if s.Labels == nil {
+			s.Labels = make(map[string]string)
+		}
+		s.Labels[f.Key] = value
+	default:
+		if s.Annotations == nil {
+			s.Annotations = make(map[string]string)
+		}
+		s.Annotations[f.Key] = value
+	}
+}
+
+func deleteField(s *guide.Secret, f FieldPath) {
+	switch f.Section {
+	case "data":
+		delete(s.Data, f.Key)
+	case "labels":
+		delete(s.Labels, f.Key)
+	default:
+		delete(s.Annotations, f.Key)
+	}
+}
+
+func demonstrateManagedFields() {
+	ctx := context.Background()
+	api := guide.NewFakeAPIServer()
+	api.Create(ctx, &guide.Secret{ObjectMeta: guide.ObjectMeta{Name: "shared", Namespace: "default"}})
+	tracker := NewManagedFieldsTracker()
+	longName := "my-very-long-application-secret-name-production-us-east-1-database"
+
+	// Each reconcile is Get → Apply → Update on its own copy. Ownership is
+	// in the object, so the second writer sees the first one's fields.
+	apply := func(esName string, cfg ApplyConfiguration, force bool) error {
+		secret := &guide.Secret{}
+		api.Get(ctx, "default", "shared", secret)
+		if err := tracker.ApplyForExternalSecret(secret, esName, cfg, force); err != nil {
+			return err
+		}
+		return api.Update(ctx, secret)
+	}
+	read := func() *guide.Secret {
+		secret := &guide.Secret{}
+		api.Get(ctx, "default", "shared", secret)
+		return secret
+	}
+
+	// Two ExternalSecrets write disjoint keys and agree on one label.
+	apply("db-creds", ApplyConfiguration{
+		Data:   map[string][]byte{"password": []byte("s3cret")},
+		Labels: map[string]string{"app": "billing"},
+	}, false)
+	apply(longName, ApplyConfiguration{
+		Data:   map[string][]byte{"tls.crt": []byte("...")},
+		Labels: map[string]string{"app": "billing"},
+	}, false)
+	fmt.Println(tracker.Managers(read(), FieldPath{Section: "labels", Key: "app"}))
+	// [externalsecrets.db-creds externalsecrets.my-very-long-application-secret-na-6be38b9453ed]
+
+	// The second one now also claims password, with a different value.
+	err := apply(longName, ApplyConfiguration{
+		Data: map[string][]byte{"tls.crt": []byte("..."), "password": []byte("other")},
+	}, false)
+	fmt.Println(err)
+	// externalsecrets.my-very-long-…-6be38b9453ed: apply failed with 1 conflicts: conflict with "externalsecrets.db-creds": data[password]
+	fmt.Println(errors.Is(err, ErrFieldConflict), string(read().Data["password"])) // true s3cret — nothing written
+
+	// Forcing takes the field over. Its label was left out, so it releases
+	// that too; db-creds still owns it, so the label stays.
+	apply(longName, ApplyConfiguration{
+		Data: map[string][]byte{"tls.crt": []byte("..."), "password": []byte("other")},
+	}, true)
+	fmt.Println(tracker.Managers(read(), FieldPath{Section: "data", Key: "password"}), read().Labels["app"])
+	// [externalsecrets.my-very-long-…-6be38b9453ed] billing
+
+	// db-creds's next apply conflicts in turn: a human has to pick a winner.
+	err = apply("db-creds", ApplyConfiguration{
+		Data:   map[string][]byte{"password": []byte("s3cret")},
+		Labels: map[string]string{"app": "billing"},
+	}, false)
+	fmt.Println(errors.Is(err, ErrFieldConflict)) // true
+}
+
 func init() {
 	_ = fqdnTruncateBad
 	_ = fqdnHashBad
 	_ = demonstrateFQDN
+	_ = demonstrateManagedFields
+	_ = demonstrateReversibleNames
 }
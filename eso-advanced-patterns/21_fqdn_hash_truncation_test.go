@@ -0,0 +1,179 @@
+package eso_advanced_patterns
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	guide "design-patterns-guide"
+)
+
+// managedSecret is a Secret in a fake API server, read and written the way a
+// reconciler does: a fresh copy per Get, one Update per apply.
+type managedSecret struct {
+	t       *testing.T
+	api     *guide.FakeAPIServer
+	tracker *ManagedFieldsTracker
+}
+
+func newManagedSecret(t *testing.T) *managedSecret {
+	api := guide.NewFakeAPIServer()
+	if err := api.Create(context.Background(), &guide.Secret{ObjectMeta: guide.ObjectMeta{Name: "shared", Namespace: "default"}}); err != nil {
+		t.Fatal(err)
+	}
+	return &managedSecret{t: t, api: api, tracker: NewManagedFieldsTracker()}
+}
+
+func (m *managedSecret) get() *guide.Secret {
+	m.t.Helper()
+	secret := &guide.Secret{}
+	if err := m.api.Get(context.Background(), "default", "shared", secret); err != nil {
+		m.t.Fatal(err)
+	}
+	return secret
+}
+
+func (m *managedSecret) apply(manager string, cfg ApplyConfiguration, force bool) error {
+	m.t.Helper()
+	secret := m.get()
+	if err := m.tracker.Apply(secret, manager, cfg, force); err != nil {
+		return err
+	}
+	if err := m.api.Update(context.Background(), secret); err != nil {
+		m.t.Fatal(err)
+	}
+	return nil
+}
+
+func TestManagedFieldsPersistThroughAPIServer(t *testing.T) {
+	m := newManagedSecret(t)
+	if err := m.apply("a", ApplyConfiguration{Data: map[string][]byte{"password": []byte("1")}}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	// A different tracker, a fresh copy: ownership comes from the object.
+	other := NewManagedFieldsTracker()
+	secret := m.get()
+	if got := other.Managers(secret, FieldPath{Section: "data", Key: "password"}); !slices.Equal(got, []string{"a"}) {
+		t.Fatalf("managers after a round trip = %v, want [a]", got)
+	}
+	err := other.Apply(secret, "b", ApplyConfiguration{Data: map[string][]byte{"password": []byte("2")}}, false)
+	var conflict *FieldConflictError
+	if !errors.As(err, &conflict) || len(conflict.Conflicts) != 1 || conflict.Conflicts[0].Managers[0] != "a" {
+		t.Fatalf("err = %v, want a conflict with a", err)
+	}
+	if string(secret.Data["password"]) != "1" {
+		t.Fatal("a conflicting apply wrote data")
+	}
+}
+
+func TestManagedFieldsSharedValueForceAndRelease(t *testing.T) {
+	m := newManagedSecret(t)
+	label := FieldPath{Section: "labels", Key: "app"}
+	password := FieldPath{Section: "data", Key: "password"}
+
+	m.apply("a", ApplyConfiguration{Data: map[string][]byte{"password": []byte("1")}, Labels: map[string]string{"app": "x"}}, false)
+	if err := m.apply("b", ApplyConfiguration{Labels: map[string]string{"app": "x"}}, false); err != nil {
+		t.Fatalf("same value must be shared, got %v", err)
+	}
+	if got := m.tracker.Managers(m.get(), label); !slices.Equal(got, []string{"a", "b"}) {
+		t.Fatalf("label managers = %v", got)
+	}
+
+	if err := m.apply("b", ApplyConfiguration{Data: map[string][]byte{"password": []byte("2")}}, true); err != nil {
+		t.Fatal(err)
+	}
+	secret := m.get()
+	if got := m.tracker.Managers(secret, password); !slices.Equal(got, []string{"b"}) {
+		t.Fatalf("password managers after force = %v, want [b]", got)
+	}
+	// b dropped the label from its apply; a still owns it, so it stays.
+	if got := m.tracker.Managers(secret, label); !slices.Equal(got, []string{"a"}) || secret.Labels["app"] != "x" {
+		t.Fatalf("label managers = %v value %q", got, secret.Labels["app"])
+	}
+
+	// a stops applying the label too: nobody owns it, so it's removed.
+	m.apply("a", ApplyConfiguration{}, false)
+	if _, ok := m.get().Labels["app"]; ok {
+		t.Fatal("label with no managers left was kept")
+	}
+}
+
+func TestManagedFieldsSerializeDeterministically(t *testing.T) {
+	m := newManagedSecret(t)
+	m.apply("b", ApplyConfiguration{Data: map[string][]byte{"z": nil, "a": nil}}, false)
+	m.apply("a", ApplyConfiguration{Annotations: map[string]string{"k": "v"}}, false)
+
+	got := m.get().ManagedFields
+	if len(got) != 2 || got[0].Manager != "a" || got[1].Manager != "b" {
+		t.Fatalf("entries = %+v, want a then b", got)
+	}
+	if f := got[1].Fields; len(f) != 2 || f[0].Key != "a" || f[1].Key != "z" {
+		t.Fatalf("b's fields = %v, want sorted", f)
+	}
+}
+
+func TestFqdnForReadableAlwaysProducesPrefixedLabel(t *testing.T) {
+	inputs := []string{
+		"db-creds", "my_.es", "my-.es", "a..b", "a.b", ".a", "a.", "Payments_DB.prod", "UPPER",
+		"-leading", "trailing-", "___", "", "ünïcode-name",
+		"my-very-long-application-secret-name-production-us-east-1-database",
+		"my-very-long-application-secret-name-production-us-west-2-database",
+		"my-es-257d19a92115", // valid, but shaped like "my_.es"'s output
+	}
+	seen := make(map[string]string)
+	for _, in := range inputs {
+		owner := fqdnForReadable(in)
+		if err := validateFieldOwner(owner); err != nil {
+			t.Errorf("fqdnForReadable(%q) = %q: %v", in, owner, err)
+		}
+		if prev, ok := seen[owner]; ok {
+			t.Errorf("%q and %q both map to %q", prev, in, owner)
+		}
+		seen[owner] = in
+	}
+	if got := fqdnForReadable("db-creds"); got != "externalsecrets.db-creds" {
+		t.Errorf("a valid short name was rewritten: %q", got)
+	}
+}
+
+func TestValidateFieldOwnerRequiresOneLabelAfterPrefix(t *testing.T) {
+	for _, name := range []string{
+		"externalsecrets.a.b",  // dotted: a subdomain, not a label
+		"externalsecrets.-bad", // bad edge
+		"externalsecrets.",     // empty
+		"other.db-creds",       // wrong prefix
+		"externalsecrets." + string(make([]byte, 50)),
+	} {
+		if err := validateFieldOwner(name); !errors.Is(err, ErrInvalidFieldOwner) {
+			t.Errorf("validateFieldOwner(%q) = %v, want ErrInvalidFieldOwner", name, err)
+		}
+	}
+}
+
+func TestApplyForExternalSecretWritesReadableOwnerAndAdoptsLegacyFields(t *testing.T) {
+	long := "my-very-long-application-secret-name-production-us-east-1-database"
+	tracker := NewManagedFieldsTracker()
+	secret := &guide.Secret{}
+	password := FieldPath{Section: "data", Key: "password"}
+
+	// Written by an older controller under the hashed name.
+	tracker.Apply(secret, fqdnFor(long), ApplyConfiguration{Data: map[string][]byte{"password": []byte("old")}}, false)
+
+	if err := tracker.ApplyForExternalSecret(secret, long, ApplyConfiguration{Data: map[string][]byte{"password": []byte("new")}}, false); err != nil {
+		t.Fatalf("the same ExternalSecret conflicted with its legacy name: %v", err)
+	}
+	if got := tracker.Managers(secret, password); !slices.Equal(got, []string{fqdnForReadable(long)}) {
+		t.Fatalf("managers = %v, want only the readable owner", got)
+	}
+
+	// The registry still resolves both names.
+	registry := NewFieldOwnerRegistry()
+	registry.Register(long)
+	for _, owner := range []string{fqdnFor(long), fqdnForReadable(long)} {
+		if name, ok := registry.Lookup(owner); !ok || name != long {
+			t.Errorf("Lookup(%q) = %q, %v", owner, name, ok)
+		}
+	}
+}
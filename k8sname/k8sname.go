@@ -0,0 +1,259 @@
+// Package k8sname: Generating Valid Kubernetes Names
+//
+// Problem: Secret names, label values, lease names and field owners are all
+// derived from user input, and each has its own rules:
+//
+//   DNS-1123 label      [a-z0-9-], alphanumeric at both ends, ≤ 63   (field owners, namespaces)
+//   DNS-1123 subdomain  labels joined by '.', ≤ 253                  (Secret, Lease names)
+//   label value         [A-Za-z0-9._-], alphanumeric at both ends, ≤ 63, or empty
+//
+// fqdnFor (advanced Pattern 21) solves this for one template. Every other
+// call site re-solves it, usually by lowercasing and truncating — which maps
+// "Team_A" and "team-a" to the same name, and two long names with a common
+// prefix to the same name.
+//
+// Solution: One generator per mode with a single rule: an input that is
+// already valid is returned unchanged; anything else is sanitized, truncated,
+// and suffixed with a hash of the ORIGINAL input. The hash is what keeps
+// lossy sanitizing and truncation from merging distinct inputs.
+//
+//   Label("db-creds")                        → db-creds
+//   Label("Team_A/DB creds")                 → team-a-db-creds-ee1a900bf01ddcdc
+//   Subdomain("payments.prod")               → payments.prod
+//   LabelValue("my very long value …")       → my-very-long-value-…-<16 hex>
+//
+// Hashed outputs always end in "-<hex>" (or are only "<hex>"), so that shape
+// is reserved: a valid input that already ends in it is hashed like any
+// other, never passed through. Otherwise "team-a-db-creds-ee1a900bf01ddcdc"
+// would come back unchanged — the same name "Team_A/DB creds" maps to.
+//
+// Output depends only on (mode, prefix, input): no randomness, no state.
+//
+// REAL CODE REFERENCE:
+//   pkg/controllers/externalsecret/util.go:88-96 (fqdnFor)
+//   k8s.io/apimachinery/pkg/util/validation/validation.go (IsDNS1123Label, IsDNS1123Subdomain, IsValidLabelValue)
+
+package k8sname
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// =============================================================================
+// Modes and Their Rules
+// =============================================================================
+
+type Mode int
+
+const (
+	ModeLabel      Mode = iota // DNS-1123 label
+	ModeSubdomain              // DNS-1123 subdomain
+	ModeLabelValue             // label value
+)
+
+const (
+	MaxLabelLength      = 63
+	MaxSubdomainLength  = 253
+	MaxLabelValueLength = 63
+
+	// DefaultHashLen is 16 hex characters (64 bits). At a million names in
+	// one namespace the birthday bound puts a collision at about 1 in 37 million.
+	DefaultHashLen = 16
+)
+
+var ErrInvalidName = errors.New("invalid name")
+
+var (
+	labelRe      = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	subdomainRe  = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+	labelValueRe = regexp.MustCompile(`^(([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9])?$`)
+
+	notLabelChars      = regexp.MustCompile(`[^a-z0-9-]+`)
+	notLabelValueChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ModeLabel:
+		return "DNS-1123 label"
+	case ModeSubdomain:
+		return "DNS-1123 subdomain"
+	case ModeLabelValue:
+		return "label value"
+	}
+	return fmt.Sprintf("Mode(%d)", int(m))
+}
+
+func (m Mode) maxLength() int {
+	if m == ModeSubdomain {
+		return MaxSubdomainLength
+	}
+	return MaxLabelLength // == MaxLabelValueLength
+}
+
+// Validate reports every rule name breaks for mode, joined.
+func Validate(mode Mode, name string) error {
+	var errs error
+	if len(name) > mode.maxLength() {
+		errs = errors.Join(errs, fmt.Errorf("%w %q: %d characters, must be no more than %d", ErrInvalidName, name, len(name), mode.maxLength()))
+	}
+	var ok bool
+	switch mode {
+	case ModeLabel:
+		ok = labelRe.MatchString(name)
+	case ModeSubdomain:
+		ok = subdomainRe.MatchString(name)
+	case ModeLabelValue:
+		ok = labelValueRe.MatchString(name)
+	default:
+		return fmt.Errorf("%w: unknown mode %d", ErrInvalidName, int(mode))
+	}
+	if !ok {
+		errs = errors.Join(errs, fmt.Errorf("%w %q: not a valid %s", ErrInvalidName, name, mode))
+	}
+	return errs
+}
+
+// =============================================================================
+// The Generator
+// =============================================================================
+
+// Generator produces names of one mode, optionally behind a fixed prefix
+// ("externalsecrets." for field owners, "eso-lease-" for leases).
+type Generator struct {
+	mode    Mode
+	prefix  string
+	hashLen int
+}
+
+// New returns a generator. The prefix is kept verbatim, so it must be able
+// to start a valid name; hashLen 0 means DefaultHashLen.
+func New(mode Mode, prefix string, hashLen int) (*Generator, error) {
+	if hashLen == 0 {
+		hashLen = DefaultHashLen
+	}
+	if hashLen < 8 || hashLen > 2*sha256.Size {
+		return nil, fmt.Errorf("%w: hash length %d out of range [8, %d]", ErrInvalidName, hashLen, 2*sha256.Size)
+	}
+	if err := Validate(mode, prefix+strings.Repeat("0", hashLen)); err != nil {
+		return nil, fmt.Errorf("prefix %q cannot start a %s: %w", prefix, mode, err)
+	}
+	return &Generator{mode: mode, prefix: prefix, hashLen: hashLen}, nil
+}
+
+// Name maps input to a valid name. Valid inputs pass through unchanged
+// unless they end in the reserved hash shape; everything else becomes
+// <prefix><sanitized, truncated input>-<hash>.
+func (g *Generator) Name(input string) string {
+	if candidate := g.prefix + input; Validate(g.mode, candidate) == nil && !g.hashShaped(input) {
+		return candidate
+	}
+
+	sum := sha256.Sum256([]byte(input))
+	hash := hex.EncodeToString(sum[:])[:g.hashLen]
+
+	body := g.sanitize(input)
+	if room := g.mode.maxLength() - len(g.prefix) - 1 - g.hashLen; len(body) > room {
+		body = g.trimEnds(body[:max(room, 0)])
+	}
+	if body == "" {
+		return g.prefix + hash
+	}
+	return g.prefix + body + "-" + hash
+}
+
+// hashShaped reports whether input ends the way a hashed output does:
+// hashLen lowercase hex characters, alone or after a '-'.
+func (g *Generator) hashShaped(input string) bool {
+	if len(input) < g.hashLen {
+		return false
+	}
+	head, tail := input[:len(input)-g.hashLen], input[len(input)-g.hashLen:]
+	if head != "" && !strings.HasSuffix(head, "-") {
+		return false
+	}
+	for _, c := range tail {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// sanitize replaces each run of disallowed characters with '-' and trims
+// what can't start or end a name. Subdomains are sanitized label by label so
+// "a..b" and ".a" don't produce empty labels.
+func (g *Generator) sanitize(input string) string {
+	switch g.mode {
+	case ModeSubdomain:
+		var labels []string
+		for _, part := range strings.Split(strings.ToLower(input), ".") {
+			if l := strings.Trim(notLabelChars.ReplaceAllString(part, "-"), "-"); l != "" {
+				labels = append(labels, l)
+			}
+		}
+		return strings.Join(labels, ".")
+	case ModeLabelValue:
+		return g.trimEnds(notLabelValueChars.ReplaceAllString(input, "-"))
+	default:
+		return g.trimEnds(notLabelChars.ReplaceAllString(strings.ToLower(input), "-"))
+	}
+}
+
+// trimEnds drops leading and trailing characters a name can't end with
+// (after truncation, "abc-" or "abc." would otherwise be left behind).
+func (g *Generator) trimEnds(s string) string {
+	return strings.Trim(s, "-._")
+}
+
+// =============================================================================
+// Shorthands
+// =============================================================================
+
+var (
+	labelGen      = &Generator{mode: ModeLabel, hashLen: DefaultHashLen}
+	subdomainGen  = &Generator{mode: ModeSubdomain, hashLen: DefaultHashLen}
+	labelValueGen = &Generator{mode: ModeLabelValue, hashLen: DefaultHashLen}
+)
+
+// Label returns a DNS-1123 label for input.
+func Label(input string) string { return labelGen.Name(input) }
+
+// Subdomain returns a DNS-1123 subdomain for input.
+func Subdomain(input string) string { return subdomainGen.Name(input) }
+
+// LabelValue returns a label value for input.
+func LabelValue(input string) string { return labelValueGen.Name(input) }
+
+// =============================================================================
+// Example
+// =============================================================================
+
+func ExampleNames() {
+	fmt.Println(Label("db-creds"))        // db-creds — already valid, unchanged
+	fmt.Println(Label("Team_A/DB creds")) // team-a-db-creds-ee1a900bf01ddcdc
+	fmt.Println(Label("team-a-db-creds")) // team-a-db-creds — no hash, so no clash with the line above
+	fmt.Println(Label("team-a-db-creds-ee1a900bf01ddcdc"))
+	// team-a-db-creds-ee1a900bf01ddcdc-<16 hex> — valid, but hash-shaped, so hashed
+	fmt.Println(Subdomain("Payments..Prod"))
+	// payments.prod-<16 hex> — empty label dropped, hash keeps it apart from "payments.prod"
+	fmt.Println(LabelValue("Release 2024/Q1")) // Release-2024-Q1-<16 hex> — case kept, values allow it
+
+	fieldOwner, _ := New(ModeSubdomain, "externalsecrets.", 0)
+	fmt.Println(fieldOwner.Name("my-secret")) // externalsecrets.my-secret
+
+	_, err := New(ModeLabel, "ESO-", 0)
+	fmt.Println(err) // prefix "ESO-" cannot start a DNS-1123 label: invalid name "ESO-0000000000000000": not a valid DNS-1123 label
+}
+
+// KEY INSIGHT:
+// Sanitizing and truncating both throw information away, so on their own
+// they can't be injective. Hashing the ORIGINAL input whenever anything was
+// thrown away puts that information back — and passing valid inputs through
+// untouched keeps the common case readable and backwards compatible, as long
+// as the one shape hashing produces is never passed through as well.
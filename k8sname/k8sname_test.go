@@ -0,0 +1,199 @@
+package k8sname
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// Three properties a Generator must hold for ANY set of inputs:
+//
+//	valid          every output passes Validate
+//	deterministic  the same input always gives the same output
+//	injective      distinct inputs never give the same output
+//
+// checkProperties returns every violation found in inputs, joined.
+func checkProperties(g *Generator, inputs []string) error {
+	var errs error
+	seen := make(map[string]string, len(inputs)) // output → input
+	for _, in := range inputs {
+		out := g.Name(in)
+		if err := Validate(g.mode, out); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("input %q: %w", in, err))
+		}
+		if again := g.Name(in); again != out {
+			errs = errors.Join(errs, fmt.Errorf("input %q: not deterministic: %q then %q", in, out, again))
+		}
+		if prev, ok := seen[out]; ok && prev != in {
+			errs = errors.Join(errs, fmt.Errorf("collision: %q and %q both map to %q", prev, in, out))
+		}
+		seen[out] = in
+	}
+	return errs
+}
+
+// realisticInputs returns n distinct names shaped like what users actually
+// type: team/app/env combinations in varying case and separators, long
+// names sharing 60+ character prefixes, and a little non-ASCII. The same
+// seed always yields the same set.
+func realisticInputs(n int, seed int64) []string {
+	r := rand.New(rand.NewSource(seed))
+	words := []string{"payments", "billing", "db", "api", "Auth", "cache", "kafka", "redis", "TLS", "creds", "token", "prod", "staging", "us-east-1", "eu-west-2", "café", "ü"}
+	seps := []string{"-", "_", ".", " ", "/", "--", ""}
+	longPrefix := "my-very-long-application-secret-name-production-us-east-1-database-"
+
+	seen := make(map[string]bool, n)
+	out := make([]string, 0, n)
+	for len(out) < n {
+		var b strings.Builder
+		if r.Intn(4) == 0 {
+			b.WriteString(longPrefix) // many inputs differ only past the truncation point
+		}
+		for i, k := 0, 1+r.Intn(5); i < k; i++ {
+			if i > 0 {
+				b.WriteString(seps[r.Intn(len(seps))])
+			}
+			w := words[r.Intn(len(words))]
+			if r.Intn(3) == 0 {
+				w = strings.ToUpper(w)
+			}
+			b.WriteString(w)
+		}
+		fmt.Fprintf(&b, "%s%d", seps[r.Intn(len(seps))], r.Intn(1000))
+		if s := b.String(); !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func mustNew(t *testing.T, mode Mode, prefix string) *Generator {
+	t.Helper()
+	g, err := New(mode, prefix, 0)
+	if err != nil {
+		t.Fatalf("New(%v, %q) = %v", mode, prefix, err)
+	}
+	return g
+}
+
+// 100,000 realistic inputs — far more names than one namespace holds.
+func TestPropertiesHoldForRealisticInputs(t *testing.T) {
+	inputs := realisticInputs(100_000, 1)
+	for _, mode := range []Mode{ModeLabel, ModeSubdomain, ModeLabelValue} {
+		if err := checkProperties(mustNew(t, mode, ""), inputs); err != nil {
+			t.Errorf("%v: %v", mode, err)
+		}
+	}
+}
+
+// Feeding every output back in as an input is the adversarial case: each
+// hashed output is a valid name, and must not come back unchanged.
+func TestPropertiesHoldWhenOutputsAreFedBackAsInputs(t *testing.T) {
+	base := realisticInputs(20_000, 2)
+	for _, tc := range []struct {
+		mode   Mode
+		prefix string
+	}{
+		{ModeLabel, ""},
+		{ModeLabel, "eso-lease-"},
+		{ModeSubdomain, ""},
+		{ModeSubdomain, "externalsecrets."},
+		{ModeLabelValue, ""},
+	} {
+		g := mustNew(t, tc.mode, tc.prefix)
+		inputs := append([]string(nil), base...)
+		for _, in := range base {
+			inputs = append(inputs, strings.TrimPrefix(g.Name(in), tc.prefix))
+		}
+		if err := checkProperties(g, inputs); err != nil {
+			t.Errorf("%v prefix %q: %v", tc.mode, tc.prefix, err)
+		}
+	}
+}
+
+func TestValidInputsPassThroughUnchanged(t *testing.T) {
+	for _, tc := range []struct {
+		mode  Mode
+		input string
+	}{
+		{ModeLabel, "db-creds"},
+		{ModeLabel, "a"},
+		{ModeLabel, "team-a-db-creds-ee1a900bf01ddcd"}, // 15 hex: not the reserved shape
+		{ModeLabel, "release-0123456789abcdefg"},       // 17 chars, last isn't hex
+		{ModeSubdomain, "payments.prod"},
+		{ModeSubdomain, "a.ee1a900bf01ddcdc"}, // hashes never follow a '.'
+		{ModeLabelValue, "Release_2024.Q1"},
+		{ModeLabelValue, "x-EE1A900BF01DDCDC"}, // hashes are lowercase
+	} {
+		if got := mustNew(t, tc.mode, "").Name(tc.input); got != tc.input {
+			t.Errorf("%v Name(%q) = %q, want it unchanged", tc.mode, tc.input, got)
+		}
+	}
+}
+
+func TestHashShapedInputsAreHashed(t *testing.T) {
+	hashed := Label("Team_A/DB creds")
+	if hashed != "team-a-db-creds-ee1a900bf01ddcdc" {
+		t.Fatalf(`Label("Team_A/DB creds") = %q`, hashed)
+	}
+	if got := Label(hashed); got == hashed || Validate(ModeLabel, got) != nil {
+		t.Fatalf("Label(%q) = %q, want a different valid name", hashed, got)
+	}
+
+	// Alone, the bare hash is reserved too: it's what an input with nothing
+	// left after sanitizing maps to.
+	bare := Label("___")
+	if got := Label(bare); got == bare {
+		t.Fatalf("Label(%q) passed through, colliding with Label(%q)", bare, "___")
+	}
+
+	owners := mustNew(t, ModeSubdomain, "externalsecrets.")
+	folded := owners.Name("My_Secret")
+	if got := owners.Name(strings.TrimPrefix(folded, "externalsecrets.")); got == folded {
+		t.Fatalf("prefixed generator passed %q through", folded)
+	}
+}
+
+func TestSanitizedNamesAreValidAndKeepTheirReadablePart(t *testing.T) {
+	long := strings.Repeat("a", 300)
+	for _, tc := range []struct {
+		mode       Mode
+		input      string
+		wantPrefix string
+	}{
+		{ModeLabel, "Team_A/DB creds", "team-a-db-creds-"},
+		{ModeLabel, long, strings.Repeat("a", MaxLabelLength-1-DefaultHashLen) + "-"},
+		{ModeSubdomain, "Payments..Prod", "payments.prod-"},
+		{ModeSubdomain, ".a.", "a-"},
+		{ModeLabelValue, "Release 2024/Q1", "Release-2024-Q1-"},
+	} {
+		got := mustNew(t, tc.mode, "").Name(tc.input)
+		if err := Validate(tc.mode, got); err != nil {
+			t.Errorf("%v Name(%q) = %q: %v", tc.mode, tc.input, got, err)
+		}
+		if !strings.HasPrefix(got, tc.wantPrefix) || len(got) != len(tc.wantPrefix)+DefaultHashLen {
+			t.Errorf("%v Name(%q) = %q, want %q + %d hex", tc.mode, tc.input, got, tc.wantPrefix, DefaultHashLen)
+		}
+	}
+}
+
+func TestNewRejectsUnusablePrefixAndHashLength(t *testing.T) {
+	for _, tc := range []struct {
+		mode    Mode
+		prefix  string
+		hashLen int
+	}{
+		{ModeLabel, "ESO-", 0},
+		{ModeLabel, "externalsecrets.", 0},
+		{ModeLabel, "", 4},
+		{ModeLabel, "", 65},
+		{ModeLabelValue, strings.Repeat("x", 60), 0},
+	} {
+		if _, err := New(tc.mode, tc.prefix, tc.hashLen); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("New(%v, %q, %d) = %v, want ErrInvalidName", tc.mode, tc.prefix, tc.hashLen, err)
+		}
+	}
+}